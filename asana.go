@@ -14,6 +14,7 @@ import (
 	"net/textproto"
 	"net/url"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/rs/xid"
@@ -49,6 +50,20 @@ type Client struct {
 	Debug          bool
 	Verbose        []bool
 	DefaultOptions Options
+
+	// RetryPolicy controls how requests which are rate limited or fail with
+	// a server error are retried. Requests are not retried if this is nil.
+	RetryPolicy *RetryPolicy
+
+	// RateLimiter, if set, holds requests back to stay within Asana's
+	// per-token quotas. It may be shared by several clients which use the
+	// same access token.
+	RateLimiter *RateLimiter
+
+	// DownloadClient fetches attachment content from the file host. The
+	// download URLs are pre-signed, so it should not add Asana credentials
+	// to requests. Defaults to http.DefaultClient.
+	DownloadClient *http.Client
 }
 
 // NewClient instantiates a new Asana client with the given HTTP client and
@@ -78,6 +93,10 @@ type Response struct {
 	Data     json.RawMessage `json:"data"`
 	NextPage *NextPage       `json:"next_page"`
 	Errors   []*Error        `json:"errors"`
+
+	// Only present in responses from the events API
+	Sync    string `json:"sync,omitempty"`
+	HasMore bool   `json:"has_more,omitempty"`
 }
 
 func (c *Client) getURL(path string) string {
@@ -105,6 +124,15 @@ func mergeQuery(q url.Values, request interface{}) error {
 }
 
 func (c *Client) Get(ctx context.Context, path string, data, result interface{}, opts ...*Options) (*NextPage, error) {
+	resultData, err := c.get(ctx, path, data, result, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return resultData.NextPage, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, data, result interface{}, opts ...*Options) (*Response, error) {
 	requestID := xid.New()
 
 	// Prepare options
@@ -157,23 +185,14 @@ func (c *Client) Get(ctx context.Context, path string, data, result interface{},
 	if c.Debug {
 		log.Printf("%s GET %s", requestID, path)
 	}
-	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.getURL(path), nil)
-	if err != nil {
-		return nil, errors.Wrapf(err, "%s Request error", requestID)
-	}
-	c.addHeaders(request, options)
-	resp, err := c.HTTPClient.Do(request)
-	if err != nil {
-		return nil, errors.Wrapf(err, "%s GET error", requestID)
-	}
-
-	// Parse the result
-	resultData, err := c.parseResponse(resp, result, requestID)
-	if err != nil {
-		return nil, err
-	}
-
-	return resultData.NextPage, nil
+	return c.execute(ctx, requestID, replayAlways, func() (*http.Request, error) {
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.getURL(path), nil)
+		if err != nil {
+			return nil, err
+		}
+		c.addHeaders(request, options)
+		return request, nil
+	}, result)
 }
 
 func (c *Client) addHeaders(request *http.Request, options *Options) {
@@ -217,6 +236,11 @@ func (c *Client) delete(ctx context.Context, path string, opts ...*Options) erro
 }
 
 func (c *Client) do(ctx context.Context, method, path string, data, result interface{}, opts ...*Options) error {
+	return c.send(ctx, method, path, c.replayMode(method), data, result, opts...)
+}
+
+// send makes a request with a JSON body, retrying failures allowed by mode
+func (c *Client) send(ctx context.Context, method, path string, mode replayMode, data, result interface{}, opts ...*Options) error {
 
 	requestID := xid.New()
 
@@ -250,19 +274,15 @@ func (c *Client) do(ctx context.Context, method, path string, data, result inter
 		body, _ := json.MarshalIndent(req, "", "  ")
 		log.Printf("%s %s %s\n%s", requestID, method, path, body)
 	}
-	request, err := http.NewRequestWithContext(ctx, method, c.getURL(path), bytes.NewReader(body))
-	if err != nil {
-		return errors.Wrap(err, "Request error")
-	}
-
-	request.Header.Add("Content-Type", "application/json")
-	c.addHeaders(request, options)
-	resp, err := c.HTTPClient.Do(request)
-	if err != nil {
-		return errors.Wrapf(err, "%s error", method)
-	}
-
-	_, err = c.parseResponse(resp, result, requestID)
+	_, err = c.execute(ctx, requestID, mode, func() (*http.Request, error) {
+		request, err := http.NewRequestWithContext(ctx, method, c.getURL(path), bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Add("Content-Type", "application/json")
+		c.addHeaders(request, options)
+		return request, nil
+	}, result)
 	return err
 }
 
@@ -287,7 +307,10 @@ func escapeQuotes(s string) string {
 
 // --------
 
-func (c *Client) postMultipart(ctx context.Context, path string, result interface{}, field string, r io.ReadCloser, filename string, contentType string, opts ...*Options) error {
+// postMultipart sends a multipart form made up of text fields and, if file
+// is not nil, a file. The file is streamed rather than buffered, and the
+// upload is only retried if its reader can be rewound.
+func (c *Client) postMultipart(ctx context.Context, path string, fields map[string]string, file *NewAttachment, result interface{}, opts ...*Options) error {
 	// Make request
 	requestID := xid.New()
 	options, err := c.mergeOptions(opts...)
@@ -296,22 +319,44 @@ func (c *Client) postMultipart(ctx context.Context, path string, result interfac
 	}
 
 	if c.Debug {
-		log.Printf("%s POST multipart %s\n%s=%s;ContentType=%s", requestID, path, field, filename, contentType)
+		log.Printf("%s POST multipart %s\n%v", requestID, path, fields)
+	}
+
+	// Write the fields in a stable order
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
 	}
-	defer r.Close()
+	sort.Strings(keys)
 
-	// Write header
 	buffer := &bytes.Buffer{}
 	partWriter := multipart.NewWriter(buffer)
-	h := make(textproto.MIMEHeader)
-	h.Set("Content-Disposition",
-		fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
-			escapeQuotes(field), escapeQuotes(filename)))
-	h.Set("Content-Type", contentType)
+	for _, key := range keys {
+		if err := partWriter.WriteField(key, fields[key]); err != nil {
+			return errors.Wrapf(err, "%s write form field %s", requestID, key)
+		}
+	}
 
-	_, err = partWriter.CreatePart(h)
-	if err != nil {
-		return errors.Wrapf(err, "%s create multipart header", requestID)
+	// Write the file header, which is sent before the file content
+	var upload *upload
+	if file != nil {
+		if c.Debug {
+			log.Printf("%s file=%s;ContentType=%s;Size=%d", requestID, file.FileName, file.ContentType, file.Size)
+		}
+		if closer, ok := file.Reader.(io.Closer); ok {
+			defer closer.Close()
+		}
+		if upload, err = newUpload(file); err != nil {
+			return err
+		}
+
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition",
+			fmt.Sprintf(`form-data; name="file"; filename="%s"`, escapeQuotes(file.FileName)))
+		h.Set("Content-Type", file.ContentType)
+		if _, err = partWriter.CreatePart(h); err != nil {
+			return errors.Wrapf(err, "%s create multipart header", requestID)
+		}
 	}
 	headerSize := buffer.Len()
 
@@ -319,24 +364,42 @@ func (c *Client) postMultipart(ctx context.Context, path string, result interfac
 	if err = partWriter.Close(); err != nil {
 		return errors.Wrapf(err, "%s create multipart footer", requestID)
 	}
+	header, footer := buffer.Bytes()[:headerSize], buffer.Bytes()[headerSize:]
 
 	// Create request
-	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.getURL(path), io.MultiReader(
-		bytes.NewReader(buffer.Bytes()[:headerSize]),
-		r,
-		bytes.NewReader(buffer.Bytes()[headerSize:])))
-	if err != nil {
-		return errors.Wrapf(err, "%s Request error", requestID)
-	}
+	mode := c.replayMode(http.MethodPost)
+	if upload != nil && !upload.seekable {
+		mode = replayNever
+	}
+	_, err = c.execute(ctx, requestID, mode, func() (*http.Request, error) {
+		var body io.Reader = bytes.NewReader(buffer.Bytes())
+		contentLength := int64(buffer.Len())
+		if upload != nil {
+			if err := upload.rewind(); err != nil {
+				return nil, err
+			}
+			body = io.MultiReader(bytes.NewReader(header), upload, bytes.NewReader(footer))
+			contentLength = -1
+			if upload.size >= 0 {
+				contentLength = int64(len(header)) + upload.size + int64(len(footer))
+			}
+		}
 
-	request.Header.Add("Content-Type", partWriter.FormDataContentType())
-	c.addHeaders(request, options)
-	resp, err := c.HTTPClient.Do(request)
-	if err != nil {
-		return errors.Wrapf(err, "%s POST error", requestID)
-	}
+		request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.getURL(path), body)
+		if err != nil {
+			return nil, err
+		}
+		request.ContentLength = contentLength
+		request.Header.Add("Content-Type", partWriter.FormDataContentType())
+		c.addHeaders(request, options)
+		return request, nil
+	}, result)
 
-	_, err = c.parseResponse(resp, result, requestID)
+	// The HTTP client hides errors from the body, such as the file turning
+	// out to be too large
+	if upload != nil && upload.err != nil {
+		return upload.err
+	}
 	return err
 }
 
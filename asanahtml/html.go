@@ -0,0 +1,285 @@
+// Package asanahtml builds and parses Asana rich text, the restricted form
+// of HTML used by the html_text and html_notes fields of stories, tasks and
+// projects.
+//
+// Rich text is built as a tree of nodes and rendered with String, which
+// escapes text so that user content cannot break the markup:
+//
+//	body := asanahtml.Body(
+//		asanahtml.Strong(asanahtml.Text("Incident resolved")),
+//		asanahtml.Text(" by "), asanahtml.Mention(userID), asanahtml.Text("\n"),
+//		asanahtml.UL(
+//			asanahtml.LI(asanahtml.Text(summary)),
+//		),
+//	)
+//	story, err := task.CreateComment(ctx, client, &asana.StoryBase{HTMLText: body.String()})
+//
+// Parse turns html_text returned by the API back into the same tree.
+//
+// Asana rich text has no paragraphs or line break elements; lines are
+// separated by newlines in the text itself.
+package asanahtml // import "github.com/incident-io/asana-go/asanahtml"
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// NodeType is the kind of a node in a rich text tree
+type NodeType int
+
+// Node types
+const (
+	ElementNode NodeType = iota
+	TextNode
+)
+
+// Attr is an attribute of an element
+type Attr struct {
+	Name  string
+	Value string
+}
+
+// Node is an element or a run of text in rich text. For elements Data is
+// the tag name, such as "strong"; for text it is the unescaped text.
+type Node struct {
+	Type     NodeType
+	Data     string
+	Attrs    []Attr
+	Children []*Node
+}
+
+// Element returns an element node. Most callers should use the functions
+// named after each element instead.
+func Element(tag string, attrs []Attr, children ...*Node) *Node {
+	return &Node{Type: ElementNode, Data: tag, Attrs: attrs, Children: children}
+}
+
+// Text returns a text node. The text is escaped when it is rendered.
+func Text(text string) *Node {
+	return &Node{Type: TextNode, Data: text}
+}
+
+// Body returns the root element which all rich text must be wrapped in
+func Body(children ...*Node) *Node { return Element("body", nil, children...) }
+
+// Strong returns bold text
+func Strong(children ...*Node) *Node { return Element("strong", nil, children...) }
+
+// Em returns italic text
+func Em(children ...*Node) *Node { return Element("em", nil, children...) }
+
+// U returns underlined text
+func U(children ...*Node) *Node { return Element("u", nil, children...) }
+
+// S returns struck through text
+func S(children ...*Node) *Node { return Element("s", nil, children...) }
+
+// Code returns inline code
+func Code(text string) *Node { return Element("code", nil, Text(text)) }
+
+// Pre returns a code block
+func Pre(text string) *Node { return Element("pre", nil, Text(text)) }
+
+// H1 returns a heading
+func H1(children ...*Node) *Node { return Element("h1", nil, children...) }
+
+// H2 returns a subheading
+func H2(children ...*Node) *Node { return Element("h2", nil, children...) }
+
+// Blockquote returns a quotation
+func Blockquote(children ...*Node) *Node { return Element("blockquote", nil, children...) }
+
+// HR returns a horizontal rule
+func HR() *Node { return Element("hr", nil) }
+
+// UL returns a bulleted list of LI items
+func UL(items ...*Node) *Node { return Element("ul", nil, items...) }
+
+// OL returns a numbered list of LI items
+func OL(items ...*Node) *Node { return Element("ol", nil, items...) }
+
+// LI returns a list item, which may contain a nested list
+func LI(children ...*Node) *Node { return Element("li", nil, children...) }
+
+// Link returns a link to a URL
+func Link(href string, children ...*Node) *Node {
+	return Element("a", []Attr{{Name: "href", Value: href}}, children...)
+}
+
+// Mention returns a mention of a user, task, project or other Asana object.
+// Asana fills in the name and link, and notifies mentioned users.
+func Mention(gid string) *Node {
+	return Element("a", []Attr{{Name: "data-asana-gid", Value: gid}})
+}
+
+// Attr returns the value of an attribute, or an empty string
+func (n *Node) Attr(name string) string {
+	for _, attr := range n.Attrs {
+		if attr.Name == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// IsMention reports whether the node is a mention of an Asana object
+func (n *Node) IsMention() bool {
+	return n.Type == ElementNode && n.Data == "a" && n.Attr("data-asana-gid") != ""
+}
+
+// Mentions returns the IDs of the objects mentioned in the tree, in order
+func (n *Node) Mentions() []string {
+	var result []string
+	n.walk(func(node *Node) {
+		if node.IsMention() {
+			result = append(result, node.Attr("data-asana-gid"))
+		}
+	})
+	return result
+}
+
+// PlainText returns the text of the tree without any markup
+func (n *Node) PlainText() string {
+	b := &strings.Builder{}
+	n.walk(func(node *Node) {
+		if node.Type == TextNode {
+			b.WriteString(node.Data)
+		}
+	})
+	return b.String()
+}
+
+func (n *Node) walk(visit func(*Node)) {
+	visit(n)
+	for _, child := range n.Children {
+		child.walk(visit)
+	}
+}
+
+// String renders the tree as rich text
+func (n *Node) String() string {
+	b := &strings.Builder{}
+	n.render(b)
+	return b.String()
+}
+
+var escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+// escape makes text safe to include in rich text. Characters which are not
+// allowed in XML are dropped, since Asana rejects them.
+func escape(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if isXMLChar(r) {
+			return r
+		}
+		return -1
+	}, text)
+	return escaper.Replace(text)
+}
+
+func isXMLChar(r rune) bool {
+	return r == '\t' || r == '\n' || r == '\r' ||
+		(r >= 0x20 && r <= 0xD7FF) ||
+		(r >= 0xE000 && r <= 0xFFFD) ||
+		(r >= 0x10000 && r <= 0x10FFFF)
+}
+
+func (n *Node) render(b *strings.Builder) {
+	if n.Type == TextNode {
+		b.WriteString(escape(n.Data))
+		return
+	}
+
+	b.WriteString("<" + n.Data)
+	for _, attr := range n.Attrs {
+		b.WriteString(" " + attr.Name + `="` + escape(attr.Value) + `"`)
+	}
+	if len(n.Children) == 0 {
+		b.WriteString("/>")
+		return
+	}
+	b.WriteString(">")
+	for _, child := range n.Children {
+		child.render(b)
+	}
+	b.WriteString("</" + n.Data + ">")
+}
+
+// Elements which Asana accepts in rich text, and the elements they may be
+// nested in. An empty list means any element other than body.
+var elements = map[string][]string{
+	"body":       nil,
+	"strong":     nil,
+	"em":         nil,
+	"u":          nil,
+	"s":          nil,
+	"code":       nil,
+	"pre":        nil,
+	"h1":         nil,
+	"h2":         nil,
+	"blockquote": nil,
+	"hr":         nil,
+	"ul":         nil,
+	"ol":         nil,
+	"li":         {"ul", "ol"},
+	"a":          nil,
+	"img":        nil,
+	"table":      nil,
+	"tr":         {"table"},
+	"td":         {"tr"},
+}
+
+// Validate checks that the tree is rich text which Asana will accept: the
+// root is a body element, every element is supported, list items are only
+// found in lists, links are not nested and mentions are empty. Asana fills
+// in the text of mentions in the html_text it returns, so parsed rich text
+// must have that text removed before it is sent back.
+func (n *Node) Validate() error {
+	if n.Type != ElementNode || n.Data != "body" {
+		return errors.New("Rich text must have a body element at the root")
+	}
+	return n.validateChildren(false)
+}
+
+func (n *Node) validateChildren(inLink bool) error {
+	for _, child := range n.Children {
+		if child.Type == TextNode {
+			if (n.Data == "ul" || n.Data == "ol") && strings.TrimSpace(child.Data) != "" {
+				return errors.Errorf("Text is not allowed directly inside <%s>", n.Data)
+			}
+			continue
+		}
+
+		parents, ok := elements[child.Data]
+		if !ok || child.Data == "body" {
+			return errors.Errorf("<%s> is not allowed in rich text", child.Data)
+		}
+		if len(parents) > 0 && !contains(parents, n.Data) {
+			return errors.Errorf("<%s> must be inside <%s>", child.Data, strings.Join(parents, "> or <"))
+		}
+		if (n.Data == "ul" || n.Data == "ol") && child.Data != "li" {
+			return errors.Errorf("<%s> may only contain <li>, not <%s>", n.Data, child.Data)
+		}
+		if child.Data == "a" && inLink {
+			return errors.New("<a> is not allowed inside another <a>")
+		}
+		if child.IsMention() && len(child.Children) > 0 {
+			return errors.Errorf("The mention of %s must be empty", child.Attr("data-asana-gid"))
+		}
+		if err := child.validateChildren(inLink || child.Data == "a"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package asanahtml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	body := Body(
+		Strong(Text("INC-42 <prod> & \"friends\"")),
+		Text(" assigned to "), Mention("1001"), Text("\n"),
+		UL(
+			LI(Text("Restarted "), Code("api-7f9")),
+			LI(Link("https://status.example.com?a=1&b=2", Text("Status page"))),
+		),
+		Pre("if x < 1 {\n\treturn\n}"),
+		Text("bell\x07"),
+	)
+
+	expected := `<body><strong>INC-42 &lt;prod&gt; &amp; &quot;friends&quot;</strong> assigned to <a data-asana-gid="1001"/>` + "\n" +
+		`<ul><li>Restarted <code>api-7f9</code></li><li><a href="https://status.example.com?a=1&amp;b=2">Status page</a></li></ul>` +
+		"<pre>if x &lt; 1 {\n\treturn\n}</pre>bell</body>"
+	if body.String() != expected {
+		t.Errorf("Unexpected rich text\n%s\nexpected\n%s", body, expected)
+	}
+	if err := body.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := map[string]*Node{
+		"root is not body":    Strong(Text("x")),
+		"unknown element":     Body(Element("script", nil, Text("x"))),
+		"nested body":         Body(Body()),
+		"item outside list":   Body(LI(Text("x"))),
+		"text inside list":    Body(UL(Text("x"))),
+		"element inside list": Body(OL(Strong(Text("x")))),
+		"link inside link":    Body(Link("https://a.example.com", Strong(Link("https://b.example.com", Text("x"))))),
+		"mention with text":   Body(Element("a", []Attr{{Name: "data-asana-gid", Value: "1"}}, Text("@Ada"))),
+	}
+	for name, node := range cases {
+		if err := node.Validate(); err == nil {
+			t.Errorf("Expected %s to be invalid", name)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	text := `<body>Paged <a href="https://app.asana.com/0/1001/list" data-asana-gid="1001" data-asana-type="user">@Ada</a> about ` +
+		`<a data-asana-gid="2002"/>&nbsp;&amp; more<ul><li>one</li><li>two</li></ul><hr/></body>`
+
+	root, err := Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Asana returns mentions with their text, which it does not accept back
+	if err := root.Validate(); err == nil {
+		t.Error("Expected a mention with text to be invalid")
+	}
+	if mentions := root.Mentions(); !reflect.DeepEqual(mentions, []string{"1001", "2002"}) {
+		t.Errorf("Unexpected mentions %v", mentions)
+	}
+	if plain := root.PlainText(); plain != "Paged @Ada about  & moreonetwo" {
+		t.Errorf("Unexpected plain text %q", plain)
+	}
+	mention := root.Children[1]
+	if !mention.IsMention() || mention.Attr("data-asana-type") != "user" {
+		t.Errorf("Expected a user mention, saw %+v", mention)
+	}
+
+	// Rendering a parsed tree gives equivalent rich text
+	again, err := Parse(root.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(root, again) {
+		t.Errorf("Expected the tree to survive a round trip, saw %s", again)
+	}
+
+	for _, invalid := range []string{
+		"",
+		"plain text",
+		"<strong>x</strong>",
+		"<body>a & b</body>",
+		"<body><strong>x</body>",
+		"<body/><body/>",
+	} {
+		if _, err := Parse(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		} else if !strings.HasPrefix(err.Error(), "Invalid rich text") {
+			t.Errorf("Unexpected error for %q: %v", invalid, err)
+		}
+	}
+}
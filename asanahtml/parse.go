@@ -0,0 +1,80 @@
+package asanahtml
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Parse reads rich text, such as the html_text of a story, into a tree. The
+// root of the tree is the body element. Rich text must be well-formed XML;
+// the named entities of HTML, such as &nbsp;, are also accepted.
+func Parse(text string) (*Node, error) {
+	decoder := xml.NewDecoder(strings.NewReader(text))
+	decoder.Entity = xml.HTMLEntity
+
+	var root *Node
+	var stack []*Node
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid rich text")
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &Node{Type: ElementNode, Data: t.Name.Local}
+			for _, attr := range t.Attr {
+				node.Attrs = append(node.Attrs, Attr{Name: attrName(attr.Name), Value: attr.Value})
+			}
+			if len(stack) == 0 {
+				if root != nil {
+					return nil, errors.New("Invalid rich text: more than one root element")
+				}
+				root = node
+			} else {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, node)
+			}
+			stack = append(stack, node)
+
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+
+		case xml.CharData:
+			if len(stack) == 0 {
+				if strings.TrimSpace(string(t)) != "" {
+					return nil, errors.New("Invalid rich text: text outside the body")
+				}
+				continue
+			}
+			parent := stack[len(stack)-1]
+			if last := len(parent.Children) - 1; last >= 0 && parent.Children[last].Type == TextNode {
+				parent.Children[last].Data += string(t)
+				continue
+			}
+			parent.Children = append(parent.Children, Text(string(t)))
+		}
+	}
+
+	if root == nil {
+		return nil, errors.New("Invalid rich text: no body element")
+	}
+	if root.Data != "body" {
+		return nil, errors.Errorf("Invalid rich text: the root element is <%s>, not <body>", root.Data)
+	}
+	return root, nil
+}
+
+// attrName restores prefixed attribute names, which the decoder splits
+func attrName(name xml.Name) string {
+	if name.Space != "" {
+		return name.Space + ":" + name.Local
+	}
+	return name.Local
+}
@@ -0,0 +1,751 @@
+package asanaschema
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	asana "github.com/incident-io/asana-go"
+)
+
+// Action is the kind of change a plan makes to an object
+type Action string
+
+// Actions in a plan
+const (
+	Create Action = "create"
+	Update Action = "update"
+	Remove Action = "remove"
+)
+
+var symbols = map[Action]string{
+	Create: "+",
+	Update: "~",
+	Remove: "-",
+}
+
+// Change is a single step of a plan
+type Change struct {
+	Action Action
+
+	// The kind of object: custom_field, enum_option, tag or
+	// custom_field_setting
+	Resource string
+
+	// The name of the object. Enum options are named after their field and
+	// custom field settings after their project, as in "Priority/High".
+	Name string
+
+	// Human readable descriptions of the attributes being changed
+	Details []string
+
+	apply func(ctx context.Context, client *asana.Client) error
+}
+
+func (c *Change) String() string {
+	s := fmt.Sprintf("%s %s %q\n", symbols[c.Action], c.Resource, c.Name)
+	for _, detail := range c.Details {
+		s += "    " + detail + "\n"
+	}
+	return s
+}
+
+// Plan is the set of changes which bring a workspace in line with a schema.
+// Plans only contain differences, so planning again after applying a plan
+// produces an empty plan.
+type Plan struct {
+	Workspace *asana.Workspace
+	Changes   []*Change
+
+	// Differences which cannot be applied, such as the importance of a
+	// custom field on a project or a missing project template, which Asana
+	// does not allow to be changed
+	Warnings []string
+
+	// Live custom fields by folded name, including those created while
+	// applying the plan
+	fields map[string]*asana.CustomField
+}
+
+// Empty returns true if the workspace already matches the schema
+func (p *Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// String formats the plan for review
+func (p *Plan) String() string {
+	name := p.Workspace.Name
+	if name == "" {
+		name = p.Workspace.ID
+	}
+
+	counts := map[Action]int{}
+	for _, change := range p.Changes {
+		counts[change.Action]++
+	}
+
+	var b strings.Builder
+	if p.Empty() {
+		fmt.Fprintf(&b, "No changes. Workspace %q matches the schema.\n", name)
+	} else {
+		fmt.Fprintf(&b, "Plan for workspace %q: %d to create, %d to update, %d to remove.\n\n",
+			name, counts[Create], counts[Update], counts[Remove])
+		for _, change := range p.Changes {
+			b.WriteString(change.String())
+		}
+	}
+
+	if len(p.Warnings) > 0 {
+		b.WriteString("\nWarnings:\n")
+		for _, warning := range p.Warnings {
+			b.WriteString("  " + warning + "\n")
+		}
+	}
+	return b.String()
+}
+
+// Apply makes the changes in the plan in order, stopping at the first error.
+// Changes which were made before the error are not undone; planning again
+// picks up from where the plan stopped.
+func (p *Plan) Apply(ctx context.Context, client *asana.Client) error {
+	for _, change := range p.Changes {
+		if err := change.apply(ctx, client); err != nil {
+			return errors.Wrapf(err, "Unable to %s %s %q", change.Action, change.Resource, change.Name)
+		}
+	}
+	return nil
+}
+
+func (p *Plan) add(change *Change) {
+	p.Changes = append(p.Changes, change)
+}
+
+func detail(attribute string, from, to interface{}) string {
+	return fmt.Sprintf("%s: %q -> %q", attribute, fmt.Sprint(from), fmt.Sprint(to))
+}
+
+var (
+	customFieldOptions = &asana.Options{Fields: []string{
+		"name", "resource_subtype", "description", "enabled", "precision", "format",
+		"currency_code", "custom_label", "custom_label_position",
+		"enum_options.name", "enum_options.color", "enum_options.enabled",
+	}}
+	tagOptions          = &asana.Options{Fields: []string{"name", "color", "notes"}}
+	projectOptions      = &asana.Options{Fields: []string{"name"}}
+	fieldSettingOptions = &asana.Options{Fields: []string{"custom_field.name", "is_important"}}
+	templateOptions     = &asana.Options{Fields: []string{
+		"name", "public", "team.name", "requested_dates.name", "requested_roles.name",
+	}}
+)
+
+// Plan compares the schema with the live workspace and returns the changes
+// needed to bring the workspace in line with it. Nothing is modified.
+func (s *Schema) Plan(ctx context.Context, client *asana.Client, workspace *asana.Workspace) (*Plan, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	if workspace.Name == "" {
+		if err := workspace.Fetch(ctx, client); err != nil {
+			return nil, err
+		}
+	}
+
+	plan := &Plan{
+		Workspace: workspace,
+		fields:    map[string]*asana.CustomField{},
+	}
+
+	fields, err := workspace.AllCustomFields(ctx, client, customFieldOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range fields {
+		plan.fields[fold(field.Name)] = field
+	}
+	for _, field := range s.CustomFields {
+		if err := plan.diffCustomField(field); err != nil {
+			return nil, err
+		}
+	}
+
+	tags, err := workspace.AllTags(ctx, client, tagOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range s.Tags {
+		plan.diffTag(workspace, tags, tag)
+	}
+
+	var projects []*asana.Project
+	for _, project := range s.Projects {
+		live := &asana.Project{ID: project.ID}
+		if live.ID != "" {
+			if err := live.Fetch(ctx, client, projectOptions); err != nil {
+				return nil, errors.Wrapf(err, "Unable to load project %s", project.ID)
+			}
+		} else {
+			// Only list projects if some are identified by name
+			if projects == nil {
+				if projects, err = workspace.AllProjects(ctx, client, projectOptions); err != nil {
+					return nil, err
+				}
+			}
+			if live, err = findProject(projects, project.Name); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := plan.diffProject(ctx, client, s, live, project); err != nil {
+			return nil, err
+		}
+	}
+
+	// Only list templates if the schema has some to check
+	if len(s.ProjectTemplates) > 0 {
+		templates, err := workspace.AllProjectTemplates(ctx, client, templateOptions)
+		if err != nil {
+			return nil, err
+		}
+		for _, template := range s.ProjectTemplates {
+			plan.checkProjectTemplate(templates, template)
+		}
+	}
+
+	return plan, nil
+}
+
+func findProject(projects []*asana.Project, name string) (*asana.Project, error) {
+	var found *asana.Project
+	for _, project := range projects {
+		if project.Name != name {
+			continue
+		}
+		if found != nil {
+			return nil, errors.Errorf("More than one project is named %q; identify it by gid instead", name)
+		}
+		found = project
+	}
+	if found == nil {
+		return nil, errors.Errorf("Project %q does not exist", name)
+	}
+	return found, nil
+}
+
+func (p *Plan) diffCustomField(desired *CustomField) error {
+	key := fold(desired.Name)
+	live := p.fields[key]
+	if live == nil {
+		p.createCustomField(key, desired)
+		return nil
+	}
+	if live.ResourceSubtype != desired.Type {
+		return errors.Errorf("Custom field %q is a %s field and cannot be changed to %s", live.Name, live.ResourceSubtype, desired.Type)
+	}
+
+	update := &asana.UpdateCustomFieldRequest{}
+	var details []string
+	if live.Name != desired.Name {
+		update.Name = desired.Name
+		details = append(details, detail("name", live.Name, desired.Name))
+	}
+	if live.Description != desired.Description {
+		update.Description = &desired.Description
+		details = append(details, detail("description", live.Description, desired.Description))
+	}
+	if live.Enabled != nil && !*live.Enabled {
+		enabled := true
+		update.Enabled = &enabled
+		details = append(details, detail("enabled", false, true))
+	}
+	if desired.Precision != nil && (live.Precision == nil || *live.Precision != *desired.Precision) {
+		update.Precision = desired.Precision
+		var from interface{} = ""
+		if live.Precision != nil {
+			from = *live.Precision
+		}
+		details = append(details, detail("precision", from, *desired.Precision))
+	}
+	if desired.Format != "" && live.Format != desired.Format {
+		update.Format = desired.Format
+		details = append(details, detail("format", live.Format, desired.Format))
+	}
+	if desired.CurrencyCode != "" && live.CurrencyCode != desired.CurrencyCode {
+		update.CurrencyCode = desired.CurrencyCode
+		details = append(details, detail("currency_code", live.CurrencyCode, desired.CurrencyCode))
+	}
+	if desired.CustomLabel != "" && live.CustomLabel != desired.CustomLabel {
+		update.CustomLabel = desired.CustomLabel
+		details = append(details, detail("custom_label", live.CustomLabel, desired.CustomLabel))
+	}
+	if desired.CustomLabelPosition != "" && live.CustomLabelPosition != desired.CustomLabelPosition {
+		update.CustomLabelPosition = desired.CustomLabelPosition
+		details = append(details, detail("custom_label_position", live.CustomLabelPosition, desired.CustomLabelPosition))
+	}
+
+	if len(details) > 0 {
+		p.add(&Change{
+			Action:   Update,
+			Resource: "custom_field",
+			Name:     desired.Name,
+			Details:  details,
+			apply: func(ctx context.Context, client *asana.Client) error {
+				// Keep the loaded enum options, which the update response
+				// may not include
+				options := live.EnumOptions
+				if err := live.Update(ctx, client, update); err != nil {
+					return err
+				}
+				live.EnumOptions = options
+				return nil
+			},
+		})
+	}
+
+	p.diffEnumOptions(live, desired)
+	return nil
+}
+
+func (p *Plan) createCustomField(key string, desired *CustomField) {
+	request := &asana.CreateCustomFieldRequest{
+		Workspace: p.Workspace.ID,
+		CustomFieldBase: asana.CustomFieldBase{
+			Name:                desired.Name,
+			ResourceSubtype:     desired.Type,
+			Description:         desired.Description,
+			Precision:           desired.Precision,
+			Format:              desired.Format,
+			CurrencyCode:        desired.CurrencyCode,
+			CustomLabel:         desired.CustomLabel,
+			CustomLabelPosition: desired.CustomLabelPosition,
+		},
+	}
+
+	details := []string{"type: " + string(desired.Type)}
+	if desired.Description != "" {
+		details = append(details, fmt.Sprintf("description: %q", desired.Description))
+	}
+	if desired.Precision != nil {
+		details = append(details, fmt.Sprintf("precision: %d", *desired.Precision))
+	}
+	if desired.Format != "" {
+		details = append(details, fmt.Sprintf("format: %s", desired.Format))
+	}
+	for _, option := range desired.Options {
+		request.EnumOptions = append(request.EnumOptions, &asana.EnumValueBase{Name: option.Name, Color: optionColor(option)})
+
+		d := fmt.Sprintf("option: %q", option.Name)
+		if option.Color != "" {
+			d += " (" + option.Color + ")"
+		}
+		if !option.enabled() {
+			d += " (disabled)"
+		}
+		details = append(details, d)
+	}
+
+	p.add(&Change{
+		Action:   Create,
+		Resource: "custom_field",
+		Name:     desired.Name,
+		Details:  details,
+		apply: func(ctx context.Context, client *asana.Client) error {
+			field, err := client.CreateCustomField(ctx, request)
+			if err != nil {
+				return err
+			}
+			p.fields[key] = field
+
+			// Options can only be disabled once they exist
+			disabled := false
+			for _, option := range desired.Options {
+				if option.enabled() {
+					continue
+				}
+				live := field.EnumOptionByName(option.Name)
+				if live == nil {
+					return errors.Errorf("Option %q was not created", option.Name)
+				}
+				if err := live.Update(ctx, client, &asana.UpdateEnumOptionRequest{Enabled: &disabled}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
+
+// optionColor defaults new options to no color
+func optionColor(option *EnumOption) string {
+	if option.Color == "" {
+		return "none"
+	}
+	return option.Color
+}
+
+// diffEnumOptions creates, updates and reorders the options of an existing
+// field, and disables options which are not in the schema. The order is
+// simulated as changes are planned so that each option is only moved when it
+// does not already follow the option before it in the schema.
+func (p *Plan) diffEnumOptions(live *asana.CustomField, desired *CustomField) {
+	var order []string
+	existing := map[string]*asana.EnumValue{}
+	for _, option := range live.EnumOptions {
+		order = append(order, option.Name)
+		existing[option.Name] = option
+	}
+	managed := map[string]bool{}
+	for _, option := range desired.Options {
+		managed[option.Name] = true
+	}
+
+	for i, option := range desired.Options {
+		option := option
+		name := desired.Name + "/" + option.Name
+		previous := ""
+		if i > 0 {
+			previous = desired.Options[i-1].Name
+		}
+
+		current := existing[option.Name]
+		if current == nil {
+			details := []string{fmt.Sprintf("position: %s", describePosition(previous))}
+			if option.Color != "" {
+				details = append(details, fmt.Sprintf("color: %q", option.Color))
+			}
+			if !option.enabled() {
+				details = append(details, "enabled: false")
+			}
+
+			p.add(&Change{
+				Action:   Create,
+				Resource: "enum_option",
+				Name:     name,
+				Details:  details,
+				apply: func(ctx context.Context, client *asana.Client) error {
+					request := &asana.CreateEnumOptionRequest{
+						Name:    option.Name,
+						Color:   optionColor(option),
+						Enabled: option.Enabled,
+					}
+					if previous != "" {
+						request.InsertAfter = live.EnumOptionByName(previous).ID
+					} else if len(live.EnumOptions) > 0 {
+						request.InsertBefore = live.EnumOptions[0].ID
+					}
+					_, err := live.CreateEnumOption(ctx, client, request)
+					return err
+				},
+			})
+			order = place(order, option.Name, previous)
+			continue
+		}
+
+		update := &asana.UpdateEnumOptionRequest{}
+		var details []string
+		if option.Color != "" && current.Color != option.Color {
+			update.Color = option.Color
+			details = append(details, detail("color", current.Color, option.Color))
+		}
+		if current.Enabled != option.enabled() {
+			enabled := option.enabled()
+			update.Enabled = &enabled
+			details = append(details, detail("enabled", current.Enabled, enabled))
+		}
+		if len(details) > 0 {
+			p.add(&Change{
+				Action:   Update,
+				Resource: "enum_option",
+				Name:     name,
+				Details:  details,
+				apply: func(ctx context.Context, client *asana.Client) error {
+					return current.Update(ctx, client, update)
+				},
+			})
+		}
+
+		if precedingOption(order, managed, option.Name) != previous {
+			p.add(&Change{
+				Action:   Update,
+				Resource: "enum_option",
+				Name:     name,
+				Details:  []string{fmt.Sprintf("position: %s", describePosition(previous))},
+				apply: func(ctx context.Context, client *asana.Client) error {
+					request := &asana.InsertEnumOptionRequest{EnumOption: current.ID}
+					if previous != "" {
+						request.AfterEnumOption = live.EnumOptionByName(previous).ID
+					} else {
+						request.BeforeEnumOption = live.EnumOptions[0].ID
+					}
+					return live.InsertEnumOption(ctx, client, request)
+				},
+			})
+			order = place(order, option.Name, previous)
+		}
+	}
+
+	// Options cannot be deleted, so those which are no longer wanted are
+	// disabled
+	for _, option := range live.EnumOptions {
+		option := option
+		if managed[option.Name] || !option.Enabled {
+			continue
+		}
+		p.add(&Change{
+			Action:   Update,
+			Resource: "enum_option",
+			Name:     desired.Name + "/" + option.Name,
+			Details:  []string{detail("enabled", true, false)},
+			apply: func(ctx context.Context, client *asana.Client) error {
+				disabled := false
+				return option.Update(ctx, client, &asana.UpdateEnumOptionRequest{Enabled: &disabled})
+			},
+		})
+	}
+}
+
+func describePosition(previous string) string {
+	if previous == "" {
+		return "first"
+	}
+	return fmt.Sprintf("after %q", previous)
+}
+
+// precedingOption returns the closest managed option before name, or an
+// empty string if there is none
+func precedingOption(order []string, managed map[string]bool, name string) string {
+	previous := ""
+	for _, o := range order {
+		if o == name {
+			return previous
+		}
+		if managed[o] {
+			previous = o
+		}
+	}
+	return previous
+}
+
+// place moves name directly after previous, or to the start of the order
+func place(order []string, name, previous string) []string {
+	result := make([]string, 0, len(order)+1)
+	if previous == "" {
+		result = append(result, name)
+	}
+	for _, o := range order {
+		if o == name {
+			continue
+		}
+		result = append(result, o)
+		if o == previous {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+func (p *Plan) diffTag(workspace *asana.Workspace, tags []*asana.Tag, desired *Tag) {
+	var live *asana.Tag
+	for _, tag := range tags {
+		if tag.Name == desired.Name {
+			live = tag
+			break
+		}
+	}
+
+	if live == nil {
+		var details []string
+		if desired.Color != "" {
+			details = append(details, fmt.Sprintf("color: %q", desired.Color))
+		}
+		if desired.Notes != "" {
+			details = append(details, fmt.Sprintf("notes: %q", desired.Notes))
+		}
+
+		p.add(&Change{
+			Action:   Create,
+			Resource: "tag",
+			Name:     desired.Name,
+			Details:  details,
+			apply: func(ctx context.Context, client *asana.Client) error {
+				_, err := workspace.CreateTag(ctx, client, &asana.TagBase{
+					Name:  desired.Name,
+					Color: desired.Color,
+					Notes: desired.Notes,
+				})
+				return err
+			},
+		})
+		return
+	}
+
+	update := &asana.TagBase{}
+	var details []string
+	if desired.Color != "" && live.Color != desired.Color {
+		update.Color = desired.Color
+		details = append(details, detail("color", live.Color, desired.Color))
+	}
+	if desired.Notes != "" && live.Notes != desired.Notes {
+		update.Notes = desired.Notes
+		details = append(details, detail("notes", live.Notes, desired.Notes))
+	}
+	if len(details) > 0 {
+		p.add(&Change{
+			Action:   Update,
+			Resource: "tag",
+			Name:     desired.Name,
+			Details:  details,
+			apply: func(ctx context.Context, client *asana.Client) error {
+				return live.Update(ctx, client, update)
+			},
+		})
+	}
+}
+
+func (p *Plan) diffProject(ctx context.Context, client *asana.Client, schema *Schema, live *asana.Project, desired *Project) error {
+	var settings []*asana.CustomFieldSetting
+	attached := map[string]*asana.CustomFieldSetting{}
+	it := live.IterateCustomFieldSettings(ctx, client, fieldSettingOptions)
+	for it.Next() {
+		setting := it.Value()
+		settings = append(settings, setting)
+		attached[fold(setting.CustomField.Name)] = setting
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	declared := map[string]bool{}
+	for _, field := range schema.CustomFields {
+		declared[fold(field.Name)] = true
+	}
+
+	wanted := map[string]bool{}
+	for _, setting := range desired.CustomFields {
+		setting := setting
+		key := fold(setting.Name)
+		wanted[key] = true
+		if !declared[key] && p.fields[key] == nil {
+			return errors.Errorf("Custom field %q on project %q is not in the schema or the workspace", setting.Name, live.Name)
+		}
+
+		if current := attached[key]; current != nil {
+			if current.Important != setting.Important {
+				p.Warnings = append(p.Warnings, fmt.Sprintf(
+					"Custom field %q on project %q has important %v, but the schema wants %v. This can only be changed in Asana.",
+					setting.Name, live.Name, current.Important, setting.Important))
+			}
+			continue
+		}
+
+		var details []string
+		if setting.Important {
+			details = append(details, "important: true")
+		}
+		p.add(&Change{
+			Action:   Create,
+			Resource: "custom_field_setting",
+			Name:     live.Name + "/" + setting.Name,
+			Details:  details,
+			apply: func(ctx context.Context, client *asana.Client) error {
+				// The field may have been created earlier in the plan
+				field := p.fields[key]
+				if field == nil {
+					return errors.Errorf("Custom field %q does not exist", setting.Name)
+				}
+				_, err := live.AddCustomFieldSetting(ctx, client, &asana.AddCustomFieldSettingRequest{
+					CustomField: field.ID,
+					Important:   setting.Important,
+				})
+				return err
+			},
+		})
+	}
+
+	if !desired.Exclusive {
+		return nil
+	}
+	for _, setting := range settings {
+		setting := setting
+		if wanted[fold(setting.CustomField.Name)] {
+			continue
+		}
+		p.add(&Change{
+			Action:   Remove,
+			Resource: "custom_field_setting",
+			Name:     live.Name + "/" + setting.CustomField.Name,
+			apply: func(ctx context.Context, client *asana.Client) error {
+				return live.RemoveCustomFieldSetting(ctx, client, setting.CustomField.ID)
+			},
+		})
+	}
+	return nil
+}
+
+// checkProjectTemplate warns about differences between a project template in
+// the schema and the workspace, as templates cannot be changed through the API
+func (p *Plan) checkProjectTemplate(templates []*asana.ProjectTemplate, desired *ProjectTemplate) {
+	var live *asana.ProjectTemplate
+	for _, template := range templates {
+		if template.Name == desired.Name {
+			live = template
+			break
+		}
+	}
+	if live == nil {
+		p.Warnings = append(p.Warnings, fmt.Sprintf(
+			"Project template %q does not exist. Templates can only be created in Asana.", desired.Name))
+		return
+	}
+
+	var details []string
+	team := ""
+	if live.Team != nil {
+		team = live.Team.Name
+	}
+	if desired.Team != "" && team != desired.Team {
+		details = append(details, detail("team", team, desired.Team))
+	}
+	if desired.Public != nil && live.Public != *desired.Public {
+		details = append(details, detail("public", live.Public, *desired.Public))
+	}
+
+	var dates, roles []string
+	for _, date := range live.RequestedDates {
+		dates = append(dates, date.Name)
+	}
+	for _, role := range live.RequestedRoles {
+		roles = append(roles, role.Name)
+	}
+	if len(desired.RequestedDates) > 0 && !sameNames(dates, desired.RequestedDates) {
+		details = append(details, detail("requested_dates", strings.Join(dates, ", "), strings.Join(desired.RequestedDates, ", ")))
+	}
+	if len(desired.RequestedRoles) > 0 && !sameNames(roles, desired.RequestedRoles) {
+		details = append(details, detail("requested_roles", strings.Join(roles, ", "), strings.Join(desired.RequestedRoles, ", ")))
+	}
+
+	if len(details) > 0 {
+		p.Warnings = append(p.Warnings, fmt.Sprintf(
+			"Project template %q differs from the schema (%s). Templates can only be changed in Asana.",
+			desired.Name, strings.Join(details, "; ")))
+	}
+}
+
+// sameNames returns true if both lists hold the same names in any order
+func sameNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := map[string]int{}
+	for _, name := range a {
+		counts[name]++
+	}
+	for _, name := range b {
+		if counts[name] == 0 {
+			return false
+		}
+		counts[name]--
+	}
+	return true
+}
@@ -0,0 +1,262 @@
+// Package asanaschema keeps the custom fields, tags, project custom field
+// settings and project templates of an Asana workspace in line with a desired
+// state document.
+//
+// A schema is read from YAML or JSON, compared against the live workspace to
+// produce a Plan, and the plan is applied once it has been reviewed:
+//
+//	schema, err := asanaschema.Parse(file)
+//	plan, err := schema.Plan(ctx, client, workspace)
+//	fmt.Print(plan)
+//	err = plan.Apply(ctx, client)
+//
+// Only the objects named in the schema are managed. Custom fields, tags and
+// enum options which are not listed are left alone, except that enum options
+// missing from a listed field are disabled, and a project marked exclusive
+// has any unlisted custom fields removed.
+//
+// Project templates cannot be created or changed through the API, so they
+// are only checked: a template which is missing or differs from the schema is
+// reported as a warning on the plan rather than as a change.
+package asanaschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	asana "github.com/incident-io/asana-go"
+)
+
+// Schema is the desired state of a workspace
+type Schema struct {
+	CustomFields []*CustomField `json:"custom_fields,omitempty" yaml:"custom_fields,omitempty"`
+	Tags         []*Tag         `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Projects     []*Project     `json:"projects,omitempty" yaml:"projects,omitempty"`
+
+	ProjectTemplates []*ProjectTemplate `json:"project_templates,omitempty" yaml:"project_templates,omitempty"`
+}
+
+// CustomField is a custom field in the workspace, matched by name. The type
+// of an existing field cannot be changed.
+type CustomField struct {
+	Name        string          `json:"name" yaml:"name"`
+	Type        asana.FieldType `json:"type" yaml:"type"`
+	Description string          `json:"description,omitempty" yaml:"description,omitempty"`
+
+	// Formatting is only managed when it is set
+	Precision           *int                `json:"precision,omitempty" yaml:"precision,omitempty"`
+	Format              asana.Format        `json:"format,omitempty" yaml:"format,omitempty"`
+	CurrencyCode        string              `json:"currency_code,omitempty" yaml:"currency_code,omitempty"`
+	CustomLabel         string              `json:"custom_label,omitempty" yaml:"custom_label,omitempty"`
+	CustomLabelPosition asana.LabelPosition `json:"custom_label_position,omitempty" yaml:"custom_label_position,omitempty"`
+
+	// Options of enum and multi_enum fields, in display order
+	Options []*EnumOption `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// EnumOption is an option of an enum custom field, matched by name. Renaming
+// an option in the schema disables the old option and creates a new one.
+type EnumOption struct {
+	Name string `json:"name" yaml:"name"`
+
+	// The color is only managed when it is set
+	Color string `json:"color,omitempty" yaml:"color,omitempty"`
+
+	// Options are enabled unless this is false
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+}
+
+func (o *EnumOption) enabled() bool {
+	return o.Enabled == nil || *o.Enabled
+}
+
+// Tag is a tag in the workspace, matched by name
+type Tag struct {
+	Name string `json:"name" yaml:"name"`
+
+	// The color and notes are only managed when they are set
+	Color string `json:"color,omitempty" yaml:"color,omitempty"`
+	Notes string `json:"notes,omitempty" yaml:"notes,omitempty"`
+}
+
+// Project lists the custom fields which should be attached to an existing
+// project, identified by ID or by name
+type Project struct {
+	ID   string `json:"gid,omitempty" yaml:"gid,omitempty"`
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	CustomFields []*FieldSetting `json:"custom_fields,omitempty" yaml:"custom_fields,omitempty"`
+
+	// Exclusive removes custom fields which are not listed from the project
+	Exclusive bool `json:"exclusive,omitempty" yaml:"exclusive,omitempty"`
+}
+
+func (p *Project) String() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.ID
+}
+
+// FieldSetting attaches a custom field to a project. It can be written as
+// just the name of the field.
+type FieldSetting struct {
+	Name      string `json:"name" yaml:"name"`
+	Important bool   `json:"important,omitempty" yaml:"important,omitempty"`
+}
+
+// UnmarshalJSON accepts a field name or an object
+func (s *FieldSetting) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &s.Name); err == nil {
+		return nil
+	}
+
+	type fieldSetting FieldSetting
+	return json.Unmarshal(data, (*fieldSetting)(s))
+}
+
+// UnmarshalYAML accepts a field name or an object
+func (s *FieldSetting) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal(&s.Name); err == nil {
+		return nil
+	}
+
+	type fieldSetting FieldSetting
+	return unmarshal((*fieldSetting)(s))
+}
+
+// ProjectTemplate is a project template which should exist in the
+// workspace, matched by name. Templates are only checked, never changed.
+type ProjectTemplate struct {
+	Name string `json:"name" yaml:"name"`
+
+	// The team and visibility are only checked when they are set
+	Team   string `json:"team,omitempty" yaml:"team,omitempty"`
+	Public *bool  `json:"public,omitempty" yaml:"public,omitempty"`
+
+	// The names of the dates and roles asked for on instantiation. They are
+	// only checked when listed, and the order does not matter.
+	RequestedDates []string `json:"requested_dates,omitempty" yaml:"requested_dates,omitempty"`
+	RequestedRoles []string `json:"requested_roles,omitempty" yaml:"requested_roles,omitempty"`
+}
+
+// Parse reads a schema from YAML or JSON and validates it. Unknown keys are
+// rejected so that typos do not silently go unmanaged.
+func Parse(r io.Reader) (*Schema, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &Schema{}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(schema)
+	} else {
+		err = yaml.UnmarshalStrict(data, schema)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to parse schema")
+	}
+
+	if err := schema.Validate(); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// fold returns the key used to match custom field names, which Asana
+// requires to be unique regardless of case
+func fold(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// Validate checks that the schema is complete and has no duplicates
+func (s *Schema) Validate() error {
+	fields := map[string]bool{}
+	for _, field := range s.CustomFields {
+		if field.Name == "" {
+			return errors.New("Custom fields must have a name")
+		}
+		if fields[fold(field.Name)] {
+			return errors.Errorf("Custom field %q is listed more than once", field.Name)
+		}
+		fields[fold(field.Name)] = true
+
+		switch field.Type {
+		case asana.Enum, asana.MultiEnum:
+			if len(field.Options) == 0 {
+				return errors.Errorf("Custom field %q needs at least one option", field.Name)
+			}
+		case asana.Text, asana.Number, asana.DateField, asana.People:
+			if len(field.Options) > 0 {
+				return errors.Errorf("Custom field %q has options, but only enum and multi_enum fields can", field.Name)
+			}
+		default:
+			return errors.Errorf("Custom field %q has unknown type %q", field.Name, field.Type)
+		}
+
+		options := map[string]bool{}
+		for _, option := range field.Options {
+			if option.Name == "" {
+				return errors.Errorf("Options of custom field %q must have a name", field.Name)
+			}
+			if options[option.Name] {
+				return errors.Errorf("Option %q of custom field %q is listed more than once", option.Name, field.Name)
+			}
+			options[option.Name] = true
+		}
+	}
+
+	tags := map[string]bool{}
+	for _, tag := range s.Tags {
+		if tag.Name == "" {
+			return errors.New("Tags must have a name")
+		}
+		if tags[tag.Name] {
+			return errors.Errorf("Tag %q is listed more than once", tag.Name)
+		}
+		tags[tag.Name] = true
+	}
+
+	projects := map[string]bool{}
+	for _, project := range s.Projects {
+		if project.ID == "" && project.Name == "" {
+			return errors.New("Projects must have a gid or a name")
+		}
+		if projects[project.String()] {
+			return errors.Errorf("Project %q is listed more than once", project)
+		}
+		projects[project.String()] = true
+
+		settings := map[string]bool{}
+		for _, setting := range project.CustomFields {
+			if setting.Name == "" {
+				return errors.Errorf("Custom fields of project %q must have a name", project)
+			}
+			if settings[fold(setting.Name)] {
+				return errors.Errorf("Custom field %q is listed more than once on project %q", setting.Name, project)
+			}
+			settings[fold(setting.Name)] = true
+		}
+	}
+
+	templates := map[string]bool{}
+	for _, template := range s.ProjectTemplates {
+		if template.Name == "" {
+			return errors.New("Project templates must have a name")
+		}
+		if templates[template.Name] {
+			return errors.Errorf("Project template %q is listed more than once", template.Name)
+		}
+		templates[template.Name] = true
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package asanaschema
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	asana "github.com/incident-io/asana-go"
+	"github.com/incident-io/asana-go/asanatest"
+)
+
+const testSchema = `
+custom_fields:
+  - name: Priority
+    type: enum
+    description: How urgent the work is
+    options:
+      - name: Urgent
+        color: red
+      - name: High
+      - name: Low
+        color: green
+  - name: Severity
+    type: enum
+    options:
+      - name: Major
+      - name: Minor
+  - name: Cost
+    type: number
+    precision: 2
+tags:
+  - name: incident
+    color: dark-red
+projects:
+  - name: Incidents
+    exclusive: true
+    custom_fields:
+      - Priority
+      - name: Severity
+        important: true
+`
+
+func TestParse(t *testing.T) {
+	schema, err := Parse(strings.NewReader(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schema.CustomFields) != 3 || len(schema.CustomFields[0].Options) != 3 {
+		t.Errorf("Unexpected custom fields %+v", schema.CustomFields)
+	}
+	if settings := schema.Projects[0].CustomFields; len(settings) != 2 || settings[0].Name != "Priority" || !settings[1].Important {
+		t.Errorf("Unexpected custom field settings %+v", settings)
+	}
+
+	json := `{"tags": [{"name": "incident"}], "projects": [{"gid": "1", "custom_fields": ["Priority", {"name": "Severity", "important": true}]}]}`
+	schema, err = Parse(strings.NewReader(json))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings := schema.Projects[0].CustomFields; len(settings) != 2 || !settings[1].Important {
+		t.Errorf("Unexpected custom field settings %+v", settings)
+	}
+
+	invalid := map[string]string{
+		"custom_fields: [{name: A, type: text, colour: red}]":                 "field colour not found",
+		"custom_fields: [{name: A, type: enum}]":                              "needs at least one option",
+		"custom_fields: [{name: A, type: text}, {name: a, type: number}]":     "listed more than once",
+		`{"custom_fields": [{"name": "A", "type": "text", "options": [{}]}]}`: "only enum and multi_enum",
+	}
+	for document, expected := range invalid {
+		if _, err := Parse(strings.NewReader(document)); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected %s to fail with %q, saw %v", document, expected, err)
+		}
+	}
+}
+
+func TestPlanAndApply(t *testing.T) {
+	ctx := context.Background()
+	server := asanatest.NewServer()
+	defer server.Close()
+	client := server.NewClient()
+	workspace := server.AddWorkspace("Acme")
+
+	// Live state which has drifted from the schema
+	project, err := client.CreateProject(ctx, &asana.CreateProjectRequest{
+		ProjectBase: asana.ProjectBase{Name: "Incidents"},
+		Workspace:   workspace.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	priority, err := client.CreateCustomField(ctx, &asana.CreateCustomFieldRequest{
+		CustomFieldBase: asana.CustomFieldBase{Name: "Priority", ResourceSubtype: asana.Enum},
+		Workspace:       workspace.ID,
+		EnumOptions:     []*asana.EnumValueBase{{Name: "Low", Color: "blue"}, {Name: "Old"}, {Name: "High"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy, err := client.CreateCustomField(ctx, &asana.CreateCustomFieldRequest{
+		CustomFieldBase: asana.CustomFieldBase{Name: "Legacy", ResourceSubtype: asana.Text},
+		Workspace:       workspace.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := project.AddCustomFieldSetting(ctx, client, &asana.AddCustomFieldSettingRequest{CustomField: legacy.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	schema, err := Parse(strings.NewReader(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := schema.Plan(ctx, client, workspace)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`~ custom_field "Priority"`,
+		`description: "" -> "How urgent the work is"`,
+		`+ enum_option "Priority/Urgent"`,
+		`~ enum_option "Priority/High"`,
+		`position: after "Urgent"`,
+		`color: "blue" -> "green"`,
+		`enabled: "true" -> "false"`,
+		`+ custom_field "Severity"`,
+		`+ custom_field "Cost"`,
+		`+ tag "incident"`,
+		`+ custom_field_setting "Incidents/Severity"`,
+		`- custom_field_setting "Incidents/Legacy"`,
+	}
+	output := plan.String()
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("Expected the plan to contain %q:\n%s", line, output)
+		}
+	}
+
+	if err := plan.Apply(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+
+	// Applying again should have nothing to do
+	plan, err = schema.Plan(ctx, client, workspace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.Empty() {
+		t.Errorf("Expected an empty plan after applying, saw:\n%s", plan)
+	}
+
+	if err := priority.Fetch(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, option := range priority.EnumOptions {
+		if option.Enabled {
+			names = append(names, option.Name)
+		}
+	}
+	if strings.Join(names, ",") != "Urgent,High,Low" {
+		t.Errorf("Unexpected enabled options %v", names)
+	}
+
+	settings, _, err := project.ListCustomFieldSettings(ctx, client, &asana.Options{Fields: []string{"custom_field.name", "is_important"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(settings) != 2 || settings[0].CustomField.Name != "Priority" || !settings[1].Important {
+		t.Errorf("Unexpected custom field settings %+v", settings)
+	}
+}
+
+func TestPlanProjectTemplates(t *testing.T) {
+	ctx := context.Background()
+	server := asanatest.NewServer()
+	defer server.Close()
+	client := server.NewClient()
+	workspace := server.AddWorkspace("Acme")
+	team := server.AddTeam(workspace.ID, "Platform")
+
+	project, err := team.CreateProject(ctx, client, &asana.CreateProjectRequest{ProjectBase: asana.ProjectBase{Name: "Postmortem"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.AddProjectTemplate(project.ID, "Postmortem template", []string{"Incident date"}, []string{"Author"})
+
+	schema, err := Parse(strings.NewReader(`
+project_templates:
+  - name: Postmortem template
+    team: Platform
+    requested_dates: [Incident date]
+    requested_roles: [Author, Reviewer]
+  - name: Launch template
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := schema.Plan(ctx, client, workspace)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Templates are reported, but there is nothing to apply
+	if !plan.Empty() || len(plan.Warnings) != 2 {
+		t.Fatalf("Expected only warnings, saw:\n%s", plan)
+	}
+	expected := []string{
+		`Project template "Postmortem template" differs from the schema (requested_roles: "Author" -> "Author, Reviewer")`,
+		`Project template "Launch template" does not exist`,
+	}
+	for i, warning := range expected {
+		if !strings.HasPrefix(plan.Warnings[i], warning) {
+			t.Errorf("Expected warning %q, saw %q", warning, plan.Warnings[i])
+		}
+	}
+
+	if _, err := Parse(strings.NewReader("project_templates: [{name: A}, {name: A}]")); err == nil {
+		t.Error("Expected duplicate templates to be rejected")
+	}
+}
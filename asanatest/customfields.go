@@ -0,0 +1,486 @@
+package asanatest
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+var (
+	customFieldFields = fieldSet("name", "description", "precision", "format", "currency_code",
+		"custom_label", "custom_label_position", "enabled", "has_notifications_enabled",
+		"is_global_to_workspace")
+	enumOptionFields = fieldSet("name", "color", "enabled")
+)
+
+// Custom field subtypes and the types of value they hold
+var customFieldSubtypes = map[string]bool{
+	"text":       true,
+	"number":     true,
+	"enum":       true,
+	"multi_enum": true,
+	"date":       true,
+	"people":     true,
+}
+
+var datePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+func (s *Server) listCustomFields(r *request) (*response, error) {
+	if _, err := s.get("workspace", r.params[0]); err != nil {
+		return nil, err
+	}
+	return &response{list: s.list("custom_field", func(o *object) bool {
+		return o.refs["workspace"] == r.params[0]
+	})}, nil
+}
+
+func (s *Server) createCustomField(r *request) (*response, error) {
+	workspace, err := required(r, "workspace")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.reference("workspace", workspace); err != nil {
+		return nil, err
+	}
+	name, err := required(r, "name")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkCustomFieldName(workspace, "", name); err != nil {
+		return nil, err
+	}
+
+	subtype := toString(r.data["resource_subtype"])
+	if subtype == "" {
+		subtype = toString(r.data["type"])
+	}
+	if !customFieldSubtypes[subtype] {
+		return nil, errorf(http.StatusBadRequest, "resource_subtype: Not a valid custom field type: %q", subtype)
+	}
+
+	field := s.create("custom_field", map[string]interface{}{
+		"workspace":                 workspace,
+		"created_by":                s.me,
+		"resource_subtype":          subtype,
+		"description":               "",
+		"enabled":                   true,
+		"is_global_to_workspace":    true,
+		"has_notifications_enabled": true,
+	})
+	if subtype == "number" {
+		field.fields["precision"] = 0
+	}
+	if subtype == "enum" || subtype == "multi_enum" {
+		field.lists["enum_options"] = []string{}
+	}
+
+	if err := s.apply(field, r.data, customFieldFields); err != nil {
+		s.delete(field.gid)
+		return nil, err
+	}
+	if err := checkPrecision(field); err != nil {
+		s.delete(field.gid)
+		return nil, err
+	}
+
+	options, _ := r.data["enum_options"].([]interface{})
+	for _, o := range options {
+		data, _ := o.(map[string]interface{})
+		if _, err := s.createOption(field, data); err != nil {
+			s.delete(field.gid)
+			return nil, err
+		}
+	}
+
+	return &response{status: http.StatusCreated, object: field}, nil
+}
+
+// checkCustomFieldName enforces unique custom field names in a workspace
+func (s *Server) checkCustomFieldName(workspace, gid, name string) error {
+	for _, field := range s.list("custom_field", nil) {
+		if field.refs["workspace"] == workspace && field.gid != gid && strings.EqualFold(toString(field.fields["name"]), name) {
+			return errorf(http.StatusBadRequest, "name: A custom field with the name %q already exists in this workspace", name)
+		}
+	}
+	return nil
+}
+
+func checkPrecision(field *object) error {
+	value, ok := field.fields["precision"]
+	if !ok {
+		return nil
+	}
+	precision, isNumber := toNumber(value)
+	if !isNumber || precision < 0 || precision > 6 || precision != math.Trunc(precision) {
+		return errorf(http.StatusBadRequest, "precision: Must be an integer between 0 and 6")
+	}
+	return nil
+}
+
+// updateCustomField is the update handler for custom fields, which also
+// checks names and precision
+func (s *Server) updateCustomField(r *request) (*response, error) {
+	field, err := s.get("custom_field", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	if name, ok := r.data["name"].(string); ok {
+		if err := s.checkCustomFieldName(field.refs["workspace"], field.gid, name); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := r.data["precision"]; ok && field.fields["resource_subtype"] != "number" {
+		return nil, errorf(http.StatusBadRequest, "precision: Only number custom fields have a precision")
+	}
+
+	previous := field.fields["precision"]
+	if err := s.apply(field, r.data, customFieldFields); err != nil {
+		return nil, err
+	}
+	if err := checkPrecision(field); err != nil {
+		field.fields["precision"] = previous
+		return nil, err
+	}
+	return &response{object: field}, nil
+}
+
+// Enum options
+
+func (s *Server) createEnumOption(r *request) (*response, error) {
+	field, err := s.get("custom_field", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	option, err := s.createOption(field, r.data)
+	if err != nil {
+		return nil, err
+	}
+	return &response{status: http.StatusCreated, object: option}, nil
+}
+
+// createOption adds an enum option to a custom field, at the position given
+// by insert_before or insert_after
+func (s *Server) createOption(field *object, data map[string]interface{}) (*object, error) {
+	if _, ok := field.lists["enum_options"]; !ok {
+		return nil, errorf(http.StatusBadRequest, "Only enum custom fields have enum options")
+	}
+	name := toString(data["name"])
+	if name == "" {
+		return nil, errorf(http.StatusBadRequest, "name: Missing input")
+	}
+	if len(field.lists["enum_options"]) >= 500 {
+		return nil, errorf(http.StatusBadRequest, "A custom field may have at most 500 enum options")
+	}
+
+	option := s.create("enum_option", map[string]interface{}{
+		"name":    name,
+		"color":   "none",
+		"enabled": true,
+	})
+	if err := s.apply(option, data, enumOptionFields); err != nil {
+		s.delete(option.gid)
+		return nil, err
+	}
+
+	options, err := insert(field.lists["enum_options"], option.gid, toString(data["insert_before"]), toString(data["insert_after"]))
+	if err != nil {
+		s.delete(option.gid)
+		return nil, err
+	}
+	field.lists["enum_options"] = options
+	return option, nil
+}
+
+func (s *Server) insertEnumOption(r *request) (*response, error) {
+	field, err := s.get("custom_field", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	option, err := required(r, "enum_option")
+	if err != nil {
+		return nil, err
+	}
+	if !contains(field.lists["enum_options"], option) {
+		return nil, errorf(http.StatusBadRequest, "enum_option: Not an option of the custom field: %s", option)
+	}
+
+	before, after := toString(r.data["before_enum_option"]), toString(r.data["after_enum_option"])
+	if (before == "") == (after == "") {
+		return nil, errorf(http.StatusBadRequest, "One of before_enum_option or after_enum_option is required")
+	}
+
+	options, err := insert(field.lists["enum_options"], option, before, after)
+	if err != nil {
+		return nil, err
+	}
+	field.lists["enum_options"] = options
+	return &response{data: map[string]interface{}{}}, nil
+}
+
+// Custom field settings
+
+func (s *Server) listCustomFieldSettings(parentType string) handlerFunc {
+	return func(r *request) (*response, error) {
+		parent, err := s.get(parentType, r.params[0])
+		if err != nil {
+			return nil, err
+		}
+		return &response{list: s.customFieldSettings(parent)}, nil
+	}
+}
+
+func (s *Server) addCustomFieldSetting(parentType string) handlerFunc {
+	return func(r *request) (*response, error) {
+		parent, err := s.get(parentType, r.params[0])
+		if err != nil {
+			return nil, err
+		}
+		fieldID, err := required(r, "custom_field")
+		if err != nil {
+			return nil, err
+		}
+		field, err := s.get("custom_field", fieldID)
+		if err != nil {
+			return nil, errorf(http.StatusBadRequest, "custom_field: Not a recognized ID: %s", fieldID)
+		}
+
+		for _, setting := range s.customFieldSettings(parent) {
+			if setting.refs["custom_field"] == field.gid {
+				return nil, errorf(http.StatusBadRequest, "custom_field: The custom field is already on the %s", parentType)
+			}
+		}
+
+		setting := s.create("custom_field_setting", map[string]interface{}{
+			parentType:     parent.gid,
+			"custom_field": field.gid,
+			"is_important": toBool(r.data["is_important"]),
+		})
+
+		settings := parent.ordered["custom_field_settings"]
+		switch {
+		case hasNull(r.data, "insert_after"):
+			settings = append([]string{setting.gid}, settings...)
+		default:
+			settings, err = insert(settings, setting.gid, toString(r.data["insert_before"]), toString(r.data["insert_after"]))
+			if err != nil {
+				s.delete(setting.gid)
+				return nil, err
+			}
+		}
+		parent.ordered["custom_field_settings"] = settings
+
+		return &response{object: setting}, nil
+	}
+}
+
+func (s *Server) removeCustomFieldSetting(parentType string) handlerFunc {
+	return func(r *request) (*response, error) {
+		parent, err := s.get(parentType, r.params[0])
+		if err != nil {
+			return nil, err
+		}
+		fieldID, err := required(r, "custom_field")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, setting := range s.customFieldSettings(parent) {
+			if setting.refs["custom_field"] == fieldID {
+				parent.ordered["custom_field_settings"] = remove(parent.ordered["custom_field_settings"], setting.gid)
+				s.delete(setting.gid)
+				return &response{data: map[string]interface{}{}}, nil
+			}
+		}
+		return nil, errorf(http.StatusBadRequest, "custom_field: The custom field is not on the %s", parentType)
+	}
+}
+
+// Custom field values
+
+// customFieldChange is a validated change to a custom field value on a task
+type customFieldChange struct {
+	field    *object
+	previous interface{}
+	value    interface{}
+}
+
+// setCustomFields validates and applies custom field values to a task
+func (s *Server) setCustomFields(task *object, values interface{}) ([]customFieldChange, error) {
+	changes, err := s.checkCustomFields(task, values)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		s.setCustomField(task, change)
+	}
+	return changes, nil
+}
+
+func (s *Server) setCustomField(task *object, change customFieldChange) {
+	if change.value == nil {
+		delete(task.customFields, change.field.gid)
+		return
+	}
+	task.customFields[change.field.gid] = change.value
+}
+
+// checkCustomFields validates custom field values for a task. The fields must
+// be on one of the task's projects, and the values must suit the field type.
+func (s *Server) checkCustomFields(task *object, values interface{}) ([]customFieldChange, error) {
+	m, ok := values.(map[string]interface{})
+	if !ok {
+		return nil, errorf(http.StatusBadRequest, "custom_fields: Not an object")
+	}
+
+	available := map[string]bool{}
+	for _, project := range s.resolve(task.lists["projects"]) {
+		for _, setting := range s.customFieldSettings(project) {
+			available[setting.refs["custom_field"]] = true
+		}
+	}
+
+	var changes []customFieldChange
+	for gid, value := range m {
+		field, err := s.get("custom_field", gid)
+		if err != nil || !available[gid] {
+			return nil, errorf(http.StatusBadRequest, "custom_fields: Custom field with ID %s is not on given object", gid)
+		}
+		if !toBool(field.fields["enabled"]) {
+			return nil, errorf(http.StatusBadRequest, "custom_fields: Custom field %s is disabled", gid)
+		}
+
+		value, err := s.checkValue(field, value)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, customFieldChange{
+			field:    field,
+			previous: task.customFields[gid],
+			value:    value,
+		})
+	}
+	return changes, nil
+}
+
+// checkValue validates a custom field value and returns it in the form it is
+// stored
+func (s *Server) checkValue(field *object, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	invalid := func(format string, args ...interface{}) error {
+		return errorf(http.StatusBadRequest, "custom_fields: %s: %s", field.gid, fmt.Sprintf(format, args...))
+	}
+
+	switch field.fields["resource_subtype"] {
+	case "text":
+		text, ok := value.(string)
+		if !ok {
+			return nil, invalid("Text value must be a string")
+		}
+		return text, nil
+	case "number":
+		number, ok := toNumber(value)
+		if !ok {
+			return nil, invalid("Number value must be a number")
+		}
+		precision, _ := toNumber(field.fields["precision"])
+		scale := math.Pow(10, precision)
+		return math.Round(number*scale) / scale, nil
+	case "enum":
+		gid, ok := value.(string)
+		if !ok {
+			return nil, invalid("Enum value must be an enum option ID")
+		}
+		if err := s.checkOption(field, gid, invalid); err != nil {
+			return nil, err
+		}
+		return gid, nil
+	case "multi_enum":
+		gids, ok := value.([]interface{})
+		if !ok {
+			return nil, invalid("Multi-enum value must be an array of enum option IDs")
+		}
+		result := []string{}
+		for _, v := range gids {
+			gid := toString(v)
+			if err := s.checkOption(field, gid, invalid); err != nil {
+				return nil, err
+			}
+			result = append(result, gid)
+		}
+		return result, nil
+	case "date":
+		date, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, invalid("Date value must be an object with date or date_time")
+		}
+		result := map[string]interface{}{"date": nil, "date_time": nil}
+		if d := toString(date["date"]); d != "" {
+			if !datePattern.MatchString(d) {
+				return nil, invalid("Not a valid date: %s", d)
+			}
+			result["date"] = d
+		}
+		if dt := toString(date["date_time"]); dt != "" {
+			result["date_time"] = dt
+			if result["date"] == nil && len(dt) >= 10 {
+				result["date"] = dt[:10]
+			}
+		}
+		if result["date"] == nil {
+			return nil, nil
+		}
+		return result, nil
+	case "people":
+		gids, ok := value.([]interface{})
+		if !ok {
+			return nil, invalid("People value must be an array of user IDs")
+		}
+		result := []string{}
+		for _, v := range gids {
+			gid, err := s.reference("custom_fields", v)
+			if err != nil || s.objects[gid].resourceType != "user" {
+				return nil, invalid("Not a recognized user: %v", v)
+			}
+			result = append(result, gid)
+		}
+		return result, nil
+	}
+	return nil, invalid("Unsupported custom field type")
+}
+
+func (s *Server) checkOption(field *object, gid string, invalid func(string, ...interface{}) error) error {
+	if !contains(field.lists["enum_options"], gid) {
+		return invalid("Not a valid enum option: %s", gid)
+	}
+	if option, ok := s.objects[gid]; !ok || !toBool(option.fields["enabled"]) {
+		return invalid("Enum option %s is disabled", gid)
+	}
+	return nil
+}
+
+// addCustomFieldStory records a story for a changed custom field value
+func (s *Server) addCustomFieldStory(task *object, change customFieldChange) {
+	if fmt.Sprint(change.previous) == fmt.Sprint(change.value) {
+		return
+	}
+
+	subtype := toString(change.field.fields["resource_subtype"])
+	key, previous, _ := s.renderValue(subtype, change.previous)
+	_, value, display := s.renderValue(subtype, change.value)
+
+	text := fmt.Sprintf("cleared %s", name(change.field))
+	if display != nil {
+		text = fmt.Sprintf("changed %s to %v", name(change.field), display)
+	}
+
+	s.addStory(task, subtype+"_custom_field_changed", text, map[string]interface{}{
+		"custom_field": change.field.gid,
+		"old_" + key:   previous,
+		"new_" + key:   value,
+	})
+}
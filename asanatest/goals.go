@@ -0,0 +1,352 @@
+package asanatest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	asana "github.com/incident-io/asana-go"
+)
+
+// Time periods
+
+// AddTimePeriod adds a time period to a workspace, which cannot be done
+// through the API. Period is the cadence code, such as FY or Q1, and dates
+// are given as YYYY-MM-DD. The parent may be empty.
+func (s *Server) AddTimePeriod(workspaceID, period, startOn, endOn, parentID string) *asana.TimePeriod {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	displayName := "FY" + endOn[2:4]
+	if period != "FY" {
+		displayName = period + " " + displayName
+	}
+	fields := map[string]interface{}{
+		"workspace":    workspaceID,
+		"period":       period,
+		"display_name": displayName,
+		"start_on":     startOn,
+		"end_on":       endOn,
+	}
+	if parentID != "" {
+		fields["parent"] = parentID
+	}
+	o := s.create("time_period", fields)
+	return &asana.TimePeriod{ID: o.gid, Period: period, DisplayName: displayName}
+}
+
+func (s *Server) listTimePeriods(r *request) (*response, error) {
+	workspace, err := required(r, "workspace")
+	if err != nil {
+		return nil, err
+	}
+	startOn, endOn := r.query.Get("start_on"), r.query.Get("end_on")
+
+	// Dates compare correctly as YYYY-MM-DD strings
+	return &response{list: s.list("time_period", func(o *object) bool {
+		return o.refs["workspace"] == workspace &&
+			(startOn == "" || toString(o.fields["start_on"]) >= startOn) &&
+			(endOn == "" || toString(o.fields["end_on"]) <= endOn)
+	})}, nil
+}
+
+// Goals
+
+var goalFields = fieldSet("name", "notes", "html_notes", "due_on", "start_on", "is_workspace_level",
+	"owner", "team", "time_period", "status", "followers")
+
+var goalStatuses = fieldSet("green", "yellow", "red", "achieved", "partial", "missed", "dropped")
+
+func (s *Server) listGoals(r *request) (*response, error) {
+	filters := map[string]string{}
+	for _, key := range []string{"workspace", "team", "portfolio", "project", "time_periods"} {
+		if value := r.query.Get(key); value != "" {
+			filters[key] = value
+		}
+	}
+	if len(filters) == 0 {
+		return nil, errorf(http.StatusBadRequest, "workspace: Missing input")
+	}
+
+	return &response{list: s.list("goal", func(o *object) bool {
+		for key, value := range filters {
+			switch key {
+			case "portfolio", "project":
+				if !contains(s.supportingResources(o), value) {
+					return false
+				}
+			case "time_periods":
+				if !contains(strings.Split(value, ","), o.refs["time_period"]) {
+					return false
+				}
+			default:
+				if o.refs[key] != value {
+					return false
+				}
+			}
+		}
+		return true
+	})}, nil
+}
+
+func (s *Server) createGoal(r *request) (*response, error) {
+	if _, err := required(r, "name"); err != nil {
+		return nil, err
+	}
+	workspace, err := required(r, "workspace")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.get("workspace", workspace); err != nil {
+		return nil, errorf(http.StatusBadRequest, "workspace: Not a recognized ID: %s", workspace)
+	}
+
+	goal := s.create("goal", map[string]interface{}{
+		"workspace":          workspace,
+		"owner":              s.me,
+		"followers":          []string{s.me},
+		"is_workspace_level": false,
+		"status":             nil,
+		"metric":             nil,
+	})
+	if err := s.updateGoal(goal, r.data); err != nil {
+		s.delete(goal.gid)
+		return nil, err
+	}
+	return &response{status: http.StatusCreated, object: goal}, nil
+}
+
+func (s *Server) putGoal(r *request) (*response, error) {
+	goal, err := s.get("goal", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	if err := s.updateGoal(goal, r.data); err != nil {
+		return nil, err
+	}
+	return &response{object: goal}, nil
+}
+
+func (s *Server) updateGoal(goal *object, data map[string]interface{}) error {
+	if status, ok := data["status"]; ok && status != nil && !goalStatuses[toString(status)] {
+		return errorf(http.StatusBadRequest, "status: Not a valid goal status: %v", status)
+	}
+	return s.apply(goal, data, goalFields)
+}
+
+var metricUnits = fieldSet("none", "currency", "percentage")
+
+func (s *Server) setGoalMetric(r *request) (*response, error) {
+	goal, err := s.get("goal", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	unit := toString(r.data["unit"])
+	if !metricUnits[unit] {
+		return nil, errorf(http.StatusBadRequest, "unit: Not a valid unit: %s", unit)
+	}
+	if unit == "currency" && toString(r.data["currency_code"]) == "" {
+		return nil, errorf(http.StatusBadRequest, "currency_code: Missing input")
+	}
+	initial, _ := toNumber(r.data["initial_number_value"])
+	target, _ := toNumber(r.data["target_number_value"])
+	precision, _ := toNumber(r.data["precision"])
+	source := toString(r.data["progress_source"])
+	if source == "" {
+		source = "manual"
+	}
+
+	s.nextID++
+	goal.fields["metric"] = map[string]interface{}{
+		"gid":                   strconv.Itoa(s.nextID),
+		"resource_type":         "goal_metric",
+		"resource_subtype":      "number",
+		"precision":             precision,
+		"unit":                  unit,
+		"currency_code":         r.data["currency_code"],
+		"initial_number_value":  initial,
+		"target_number_value":   target,
+		"current_number_value":  initial,
+		"current_display_value": formatNumber(initial),
+		"progress_source":       source,
+	}
+	return &response{object: goal}, nil
+}
+
+func (s *Server) setGoalMetricCurrentValue(r *request) (*response, error) {
+	goal, err := s.get("goal", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	metric, ok := goal.fields["metric"].(map[string]interface{})
+	if !ok {
+		return nil, errorf(http.StatusBadRequest, "The goal does not have a metric")
+	}
+	if metric["progress_source"] != "manual" {
+		return nil, errorf(http.StatusBadRequest, "The goal's progress is not set manually")
+	}
+	value, ok := toNumber(r.data["current_number_value"])
+	if !ok {
+		return nil, errorf(http.StatusBadRequest, "current_number_value: Not a number")
+	}
+	metric["current_number_value"] = value
+	metric["current_display_value"] = formatNumber(value)
+	return &response{object: goal}, nil
+}
+
+func (s *Server) changeGoalFollowers(add bool) handlerFunc {
+	return func(r *request) (*response, error) {
+		goal, err := s.get("goal", r.params[0])
+		if err != nil {
+			return nil, err
+		}
+		users, err := s.references("followers", r.data["followers"])
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			if _, err := s.get("user", user); err != nil {
+				return nil, errorf(http.StatusBadRequest, "followers: Not a user: %s", user)
+			}
+			goal.lists["followers"] = remove(goal.lists["followers"], user)
+			if add {
+				goal.lists["followers"] = append(goal.lists["followers"], user)
+			}
+		}
+		return &response{object: goal}, nil
+	}
+}
+
+// Goal relationships
+
+// relationshipTypes maps supporting resource types to relationship subtypes
+var relationshipTypes = map[string]string{
+	"project":   "supporting_project",
+	"portfolio": "supporting_portfolio",
+	"goal":      "subgoal",
+}
+
+// supportingResources returns the IDs of the resources supporting a goal
+func (s *Server) supportingResources(goal *object) []string {
+	var result []string
+	for _, relationship := range s.resolve(goal.ordered["relationships"]) {
+		result = append(result, relationship.refs["supporting_resource"])
+	}
+	return result
+}
+
+func (s *Server) addSupportingRelationship(r *request) (*response, error) {
+	goal, err := s.get("goal", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	gid, err := required(r, "supporting_resource")
+	if err != nil {
+		return nil, err
+	}
+	resource, err := s.get("", gid)
+	if err != nil || relationshipTypes[resource.resourceType] == "" || gid == goal.gid {
+		return nil, errorf(http.StatusBadRequest, "supporting_resource: Not a project, portfolio or goal: %s", gid)
+	}
+	if contains(s.supportingResources(goal), gid) {
+		return nil, errorf(http.StatusBadRequest, "supporting_resource: Already supports the goal: %s", gid)
+	}
+
+	// Subgoals are positioned relative to other subgoals
+	before, after := toString(r.data["insert_before"]), toString(r.data["insert_after"])
+	if (before != "" || after != "") && resource.resourceType != "goal" {
+		return nil, errorf(http.StatusBadRequest, "Only subgoals can be inserted at a position")
+	}
+	anchor := func(subgoal string) string {
+		for _, relationship := range s.resolve(goal.ordered["relationships"]) {
+			if subgoal != "" && relationship.refs["supporting_resource"] == subgoal {
+				return relationship.gid
+			}
+		}
+		return subgoal
+	}
+
+	weight := 0.0
+	if value, ok := r.data["contribution_weight"]; ok {
+		if weight, ok = toNumber(value); !ok || weight < 0 || weight > 1 {
+			return nil, errorf(http.StatusBadRequest, "contribution_weight: Must be between 0 and 1")
+		}
+	}
+
+	relationship := s.create("goal_relationship", map[string]interface{}{
+		"resource_subtype":    relationshipTypes[resource.resourceType],
+		"supported_goal":      goal.gid,
+		"supporting_resource": gid,
+		"contribution_weight": weight,
+	})
+	goal.ordered["relationships"], err = insert(goal.ordered["relationships"], relationship.gid, anchor(before), anchor(after))
+	if err != nil {
+		s.delete(relationship.gid)
+		return nil, err
+	}
+	return &response{status: http.StatusCreated, object: relationship}, nil
+}
+
+func (s *Server) removeSupportingRelationship(r *request) (*response, error) {
+	goal, err := s.get("goal", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	gid, err := required(r, "supporting_resource")
+	if err != nil {
+		return nil, err
+	}
+	for _, relationship := range s.resolve(goal.ordered["relationships"]) {
+		if relationship.refs["supporting_resource"] == gid {
+			goal.ordered["relationships"] = remove(goal.ordered["relationships"], relationship.gid)
+			s.delete(relationship.gid)
+			return &response{data: map[string]interface{}{}}, nil
+		}
+	}
+	return nil, errorf(http.StatusBadRequest, "supporting_resource: Does not support the goal: %s", gid)
+}
+
+func (s *Server) listGoalRelationships(r *request) (*response, error) {
+	gid, err := required(r, "supported_goal")
+	if err != nil {
+		return nil, err
+	}
+	goal, err := s.get("goal", gid)
+	if err != nil {
+		return nil, err
+	}
+	subtype := r.query.Get("resource_subtype")
+
+	result := []*object{}
+	for _, relationship := range s.resolve(goal.ordered["relationships"]) {
+		if subtype == "" || relationship.fields["resource_subtype"] == subtype {
+			result = append(result, relationship)
+		}
+	}
+	return &response{list: result}, nil
+}
+
+func (s *Server) updateGoalRelationship(r *request) (*response, error) {
+	relationship, err := s.get("goal_relationship", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	if value, ok := r.data["contribution_weight"]; ok {
+		weight, ok := toNumber(value)
+		if !ok || weight < 0 || weight > 1 {
+			return nil, errorf(http.StatusBadRequest, "contribution_weight: Must be between 0 and 1")
+		}
+		relationship.fields["contribution_weight"] = weight
+	}
+	return &response{object: relationship}, nil
+}
+
+func (s *Server) listParentGoals(r *request) (*response, error) {
+	goal, err := s.get("goal", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	return &response{list: s.list("goal", func(o *object) bool {
+		return contains(s.supportingResources(o), goal.gid)
+	})}, nil
+}
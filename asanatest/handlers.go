@@ -0,0 +1,600 @@
+package asanatest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	asana "github.com/incident-io/asana-go"
+)
+
+func (s *Server) registerRoutes() {
+	// Workspaces, users and teams
+	s.handle(http.MethodGet, "/workspaces", s.listWorkspaces)
+	s.handle(http.MethodGet, "/workspaces/{}", s.getObject("workspace"))
+	s.handle(http.MethodGet, "/users", s.listUsers)
+	s.handle(http.MethodGet, "/users/{}", s.getUser)
+	s.handle(http.MethodGet, "/users/{}/user_task_list", s.getUserTaskList)
+	s.handle(http.MethodGet, "/user_task_lists/{}", s.getObject("user_task_list"))
+	s.handle(http.MethodGet, "/user_task_lists/{}/tasks", s.listUserTaskListTasks)
+	s.handle(http.MethodGet, "/organizations/{}/teams", s.listTeams)
+	s.handle(http.MethodGet, "/teams/{}", s.getObject("team"))
+
+	// Projects
+	s.handle(http.MethodGet, "/projects", s.listProjects)
+	s.handle(http.MethodGet, "/workspaces/{}/projects", s.listWorkspaceProjects)
+	s.handle(http.MethodGet, "/teams/{}/projects", s.listTeamProjects)
+	s.handle(http.MethodPost, "/projects", s.createProject)
+	s.handle(http.MethodPost, "/workspaces/{}/projects", s.createProjectIn("workspace"))
+	s.handle(http.MethodPost, "/teams/{}/projects", s.createProjectIn("team"))
+	s.handle(http.MethodGet, "/projects/{}", s.getObject("project"))
+	s.handle(http.MethodPut, "/projects/{}", s.updateObject("project", projectFields))
+	s.handle(http.MethodDelete, "/projects/{}", s.deleteObject("project"))
+	s.handle(http.MethodPost, "/projects/{}/duplicate", s.duplicateProject)
+	s.handle(http.MethodPost, "/projects/{}/addMembers", s.changeUsers("project", "members", true))
+	s.handle(http.MethodPost, "/projects/{}/removeMembers", s.changeUsers("project", "members", false))
+	s.handle(http.MethodPost, "/projects/{}/addFollowers", s.changeUsers("project", "followers", true))
+	s.handle(http.MethodPost, "/projects/{}/removeFollowers", s.changeUsers("project", "followers", false))
+	s.handle(http.MethodGet, "/projects/{}/task_counts", s.projectTaskCounts)
+
+	// Portfolios
+	s.handle(http.MethodGet, "/portfolios", s.listPortfolios)
+	s.handle(http.MethodPost, "/portfolios", s.createPortfolio)
+	s.handle(http.MethodGet, "/portfolios/{}", s.getObject("portfolio"))
+	s.handle(http.MethodPut, "/portfolios/{}", s.updateObject("portfolio", portfolioFields))
+	s.handle(http.MethodDelete, "/portfolios/{}", s.deleteObject("portfolio"))
+	s.handle(http.MethodGet, "/portfolios/{}/items", s.listPortfolioItems)
+	s.handle(http.MethodPost, "/portfolios/{}/addItem", s.addPortfolioItem)
+	s.handle(http.MethodPost, "/portfolios/{}/removeItem", s.removePortfolioItem)
+	s.handle(http.MethodPost, "/portfolios/{}/addMembers", s.changeUsers("portfolio", "members", true))
+	s.handle(http.MethodPost, "/portfolios/{}/removeMembers", s.changeUsers("portfolio", "members", false))
+
+	// Sections
+	s.handle(http.MethodGet, "/projects/{}/sections", s.listSections)
+	s.handle(http.MethodPost, "/projects/{}/sections", s.createSection)
+	s.handle(http.MethodPost, "/projects/{}/sections/insert", s.insertSection)
+	s.handle(http.MethodGet, "/sections/{}", s.getObject("section"))
+	s.handle(http.MethodPut, "/sections/{}", s.updateObject("section", sectionFields))
+	s.handle(http.MethodDelete, "/sections/{}", s.deleteSection)
+	s.handle(http.MethodPost, "/sections/{}/addTask", s.addTaskToSection)
+
+	// Tasks
+	s.handle(http.MethodGet, "/tasks", s.queryTasks)
+	s.handle(http.MethodPost, "/tasks", s.createTask)
+	s.handle(http.MethodGet, "/projects/{}/tasks", s.listProjectTasks)
+	s.handle(http.MethodGet, "/sections/{}/tasks", s.listSectionTasks)
+	s.handle(http.MethodGet, "/tags/{}/tasks", s.listTagTasks)
+	s.handle(http.MethodGet, "/tasks/{}", s.getObject("task"))
+	s.handle(http.MethodPut, "/tasks/{}", s.updateTask)
+	s.handle(http.MethodDelete, "/tasks/{}", s.deleteObject("task"))
+	s.handle(http.MethodGet, "/tasks/{}/subtasks", s.listSubtasks)
+	s.handle(http.MethodPost, "/tasks/{}/subtasks", s.createSubtask)
+	s.handle(http.MethodPost, "/tasks/{}/addProject", s.addProject)
+	s.handle(http.MethodPost, "/tasks/{}/removeProject", s.removeProject)
+	s.handle(http.MethodPost, "/tasks/{}/setParent", s.setParent)
+	s.handle(http.MethodPost, "/tasks/{}/duplicate", s.duplicateTask)
+	s.handle(http.MethodPost, "/tasks/{}/addDependencies", s.addTaskLinks("dependencies", "dependents"))
+	s.handle(http.MethodPost, "/tasks/{}/addDependents", s.addTaskLinks("dependents", "dependencies"))
+	s.handle(http.MethodGet, "/tasks/{}/tags", s.listTaskTags)
+	s.handle(http.MethodPost, "/tasks/{}/addTag", s.addTag)
+	s.handle(http.MethodPost, "/tasks/{}/removeTag", s.removeTag)
+
+	// Stories
+	s.handle(http.MethodGet, "/tasks/{}/stories", s.listStories)
+	s.handle(http.MethodPost, "/tasks/{}/stories", s.createComment)
+	s.handle(http.MethodGet, "/stories/{}", s.getObject("story"))
+	s.handle(http.MethodPut, "/stories/{}", s.updateStory)
+	s.handle(http.MethodDelete, "/stories/{}", s.deleteObject("story"))
+
+	// Tags
+	s.handle(http.MethodGet, "/tags", s.listTags)
+	s.handle(http.MethodGet, "/workspaces/{}/tags", s.listWorkspaceTags)
+	s.handle(http.MethodPost, "/tags", s.createTag)
+	s.handle(http.MethodPost, "/workspaces/{}/tags", s.createWorkspaceTag)
+	s.handle(http.MethodGet, "/tags/{}", s.getObject("tag"))
+	s.handle(http.MethodPut, "/tags/{}", s.updateObject("tag", tagFields))
+	s.handle(http.MethodDelete, "/tags/{}", s.deleteObject("tag"))
+
+	// Custom fields
+	s.handle(http.MethodPost, "/custom_fields", s.createCustomField)
+	s.handle(http.MethodGet, "/workspaces/{}/custom_fields", s.listCustomFields)
+	s.handle(http.MethodGet, "/custom_fields/{}", s.getObject("custom_field"))
+	s.handle(http.MethodPut, "/custom_fields/{}", s.updateCustomField)
+	s.handle(http.MethodDelete, "/custom_fields/{}", s.deleteObject("custom_field"))
+	s.handle(http.MethodPost, "/custom_fields/{}/enum_options", s.createEnumOption)
+	s.handle(http.MethodPost, "/custom_fields/{}/enum_options/insert", s.insertEnumOption)
+	s.handle(http.MethodPut, "/enum_options/{}", s.updateObject("enum_option", enumOptionFields))
+	s.handle(http.MethodGet, "/projects/{}/custom_field_settings", s.listCustomFieldSettings("project"))
+	s.handle(http.MethodPost, "/projects/{}/addCustomFieldSetting", s.addCustomFieldSetting("project"))
+	s.handle(http.MethodPost, "/projects/{}/removeCustomFieldSetting", s.removeCustomFieldSetting("project"))
+	s.handle(http.MethodGet, "/portfolios/{}/custom_field_settings", s.listCustomFieldSettings("portfolio"))
+	s.handle(http.MethodPost, "/portfolios/{}/addCustomFieldSetting", s.addCustomFieldSetting("portfolio"))
+	s.handle(http.MethodPost, "/portfolios/{}/removeCustomFieldSetting", s.removeCustomFieldSetting("portfolio"))
+
+	// Project templates
+	s.handle(http.MethodGet, "/project_templates", s.listProjectTemplates)
+	s.handle(http.MethodGet, "/teams/{}/project_templates", s.listTeamProjectTemplates)
+	s.handle(http.MethodGet, "/project_templates/{}", s.getObject("project_template"))
+	s.handle(http.MethodPost, "/project_templates/{}/instantiateProject", s.instantiateProject)
+
+	// Status updates
+	s.handle(http.MethodGet, "/status_updates", s.listStatusUpdates)
+	s.handle(http.MethodPost, "/status_updates", s.createStatusUpdate)
+	s.handle(http.MethodGet, "/status_updates/{}", s.getObject("status_update"))
+	s.handle(http.MethodDelete, "/status_updates/{}", s.deleteObject("status_update"))
+
+	// Goals
+	s.handle(http.MethodGet, "/goals", s.listGoals)
+	s.handle(http.MethodPost, "/goals", s.createGoal)
+	s.handle(http.MethodGet, "/goals/{}", s.getObject("goal"))
+	s.handle(http.MethodPut, "/goals/{}", s.putGoal)
+	s.handle(http.MethodDelete, "/goals/{}", s.deleteObject("goal"))
+	s.handle(http.MethodPost, "/goals/{}/setMetric", s.setGoalMetric)
+	s.handle(http.MethodPost, "/goals/{}/setMetricCurrentValue", s.setGoalMetricCurrentValue)
+	s.handle(http.MethodPost, "/goals/{}/addFollowers", s.changeGoalFollowers(true))
+	s.handle(http.MethodPost, "/goals/{}/removeFollowers", s.changeGoalFollowers(false))
+	s.handle(http.MethodPost, "/goals/{}/addSupportingRelationship", s.addSupportingRelationship)
+	s.handle(http.MethodPost, "/goals/{}/removeSupportingRelationship", s.removeSupportingRelationship)
+	s.handle(http.MethodGet, "/goals/{}/parentGoals", s.listParentGoals)
+	s.handle(http.MethodGet, "/goal_relationships", s.listGoalRelationships)
+	s.handle(http.MethodGet, "/goal_relationships/{}", s.getObject("goal_relationship"))
+	s.handle(http.MethodPut, "/goal_relationships/{}", s.updateGoalRelationship)
+	s.handle(http.MethodGet, "/time_periods", s.listTimePeriods)
+	s.handle(http.MethodGet, "/time_periods/{}", s.getObject("time_period"))
+
+	// Jobs
+	s.handle(http.MethodGet, "/jobs/{}", s.getJob)
+
+	// Webhooks
+	s.handle(http.MethodGet, "/webhooks", s.listWebhooks)
+	s.handle(http.MethodPost, "/webhooks", s.createWebhook)
+	s.handle(http.MethodGet, "/webhooks/{}", s.getObject("webhook"))
+	s.handle(http.MethodDelete, "/webhooks/{}", s.deleteObject("webhook"))
+
+	// Batch API
+	s.handle(http.MethodPost, "/batch", s.batch)
+}
+
+// Fields which may be set by create and update requests
+var (
+	projectFields = fieldSet("name", "notes", "html_notes", "color", "archived", "public",
+		"default_view", "due_on", "start_on", "icon", "owner", "team", "followers")
+	portfolioFields = fieldSet("name", "color", "public", "start_on", "due_on", "owner", "members")
+	sectionFields   = fieldSet("name")
+	tagFields       = fieldSet("name", "notes", "color", "followers")
+)
+
+func fieldSet(fields ...string) map[string]bool {
+	result := map[string]bool{}
+	for _, field := range fields {
+		result[field] = true
+	}
+	return result
+}
+
+// Me returns the user the server's clients are authenticated as
+func (s *Server) Me() *asana.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return &asana.User{ID: s.me, Name: "Test User", Email: "test@example.com"}
+}
+
+// AddWorkspace adds a workspace, which cannot be created through the API
+func (s *Server) AddWorkspace(name string) *asana.Workspace {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o := s.create("workspace", map[string]interface{}{
+		"name":            name,
+		"is_organization": true,
+	})
+	return &asana.Workspace{ID: o.gid, Name: name, IsOrganization: true}
+}
+
+// AddUser adds a user to every workspace
+func (s *Server) AddUser(name, email string) *asana.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o := s.create("user", map[string]interface{}{
+		"name":  name,
+		"email": email,
+	})
+	return &asana.User{ID: o.gid, Name: name, Email: email}
+}
+
+// AddTeam adds a team to an organization
+func (s *Server) AddTeam(workspaceID, name string) *asana.Team {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o := s.create("team", map[string]interface{}{
+		"name":         name,
+		"organization": workspaceID,
+	})
+	return &asana.Team{ID: o.gid, Name: name}
+}
+
+// Generic handlers
+
+func (s *Server) getObject(resourceType string) handlerFunc {
+	return func(r *request) (*response, error) {
+		o, err := s.get(resourceType, r.params[0])
+		if err != nil {
+			return nil, err
+		}
+		return &response{object: o}, nil
+	}
+}
+
+func (s *Server) updateObject(resourceType string, writable map[string]bool) handlerFunc {
+	return func(r *request) (*response, error) {
+		o, err := s.get(resourceType, r.params[0])
+		if err != nil {
+			return nil, err
+		}
+		if err := s.apply(o, r.data, writable); err != nil {
+			return nil, err
+		}
+		return &response{object: o}, nil
+	}
+}
+
+func (s *Server) deleteObject(resourceType string) handlerFunc {
+	return func(r *request) (*response, error) {
+		o, err := s.get(resourceType, r.params[0])
+		if err != nil {
+			return nil, err
+		}
+		s.delete(o.gid)
+		return &response{data: map[string]interface{}{}}, nil
+	}
+}
+
+// required returns a string field from the request data or query, or a 400
+// error if it is missing
+func required(r *request, key string) (string, error) {
+	if value, ok := r.data[key].(string); ok && value != "" {
+		return value, nil
+	}
+	if value := r.query.Get(key); value != "" {
+		return value, nil
+	}
+	return "", errorf(http.StatusBadRequest, "%s: Missing input", key)
+}
+
+// Workspaces, users and teams
+
+func (s *Server) listWorkspaces(r *request) (*response, error) {
+	return &response{list: s.list("workspace", nil)}, nil
+}
+
+func (s *Server) listUsers(r *request) (*response, error) {
+	return &response{list: s.list("user", nil)}, nil
+}
+
+func (s *Server) getUser(r *request) (*response, error) {
+	gid := r.params[0]
+	if gid == "me" {
+		gid = s.me
+	}
+	return s.getObject("user")(&request{params: []string{gid}})
+}
+
+func (s *Server) listTeams(r *request) (*response, error) {
+	if _, err := s.get("workspace", r.params[0]); err != nil {
+		return nil, err
+	}
+	return &response{list: s.list("team", func(o *object) bool {
+		return o.refs["organization"] == r.params[0]
+	})}, nil
+}
+
+// Projects
+
+func (s *Server) listProjects(r *request) (*response, error) {
+	workspace, team := r.query.Get("workspace"), r.query.Get("team")
+	if workspace == "" && team == "" {
+		return nil, errorf(http.StatusBadRequest, "workspace: Missing input")
+	}
+	return s.projects(r.query, func(o *object) bool {
+		return (workspace == "" || o.refs["workspace"] == workspace) && (team == "" || o.refs["team"] == team)
+	}), nil
+}
+
+func (s *Server) listWorkspaceProjects(r *request) (*response, error) {
+	if _, err := s.get("workspace", r.params[0]); err != nil {
+		return nil, err
+	}
+	return s.projects(r.query, func(o *object) bool {
+		return o.refs["workspace"] == r.params[0]
+	}), nil
+}
+
+func (s *Server) listTeamProjects(r *request) (*response, error) {
+	if _, err := s.get("team", r.params[0]); err != nil {
+		return nil, err
+	}
+	return s.projects(r.query, func(o *object) bool {
+		return o.refs["team"] == r.params[0]
+	}), nil
+}
+
+// projects lists projects, honouring the archived filter
+func (s *Server) projects(query url.Values, match func(o *object) bool) *response {
+	archived := query.Get("archived")
+	return &response{list: s.list("project", func(o *object) bool {
+		if archived != "" && (archived == "true") != toBool(o.fields["archived"]) {
+			return false
+		}
+		return match(o)
+	})}
+}
+
+func (s *Server) createProject(r *request) (*response, error) {
+	if team, ok := r.data["team"].(string); ok {
+		t, err := s.get("team", team)
+		if err != nil {
+			return nil, errorf(http.StatusBadRequest, "team: Not a recognized ID: %s", team)
+		}
+		r.data["workspace"] = t.refs["organization"]
+	}
+
+	workspace, err := required(r, "workspace")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.reference("workspace", workspace); err != nil {
+		return nil, err
+	}
+
+	project := s.create("project", map[string]interface{}{
+		"workspace":    workspace,
+		"owner":        s.me,
+		"members":      []string{s.me},
+		"followers":    []string{s.me},
+		"archived":     false,
+		"default_view": "list",
+	})
+	if err := s.apply(project, r.data, projectFields); err != nil {
+		s.delete(project.gid)
+		return nil, err
+	}
+
+	// Asana creates new projects with a single section
+	section := s.create("section", map[string]interface{}{
+		"name":    "Untitled section",
+		"project": project.gid,
+	})
+	project.ordered["sections"] = []string{section.gid}
+
+	return &response{status: http.StatusCreated, object: project}, nil
+}
+
+func (s *Server) createProjectIn(parent string) handlerFunc {
+	return func(r *request) (*response, error) {
+		r.data[parent] = r.params[0]
+		return s.createProject(r)
+	}
+}
+
+// Sections
+
+func (s *Server) listSections(r *request) (*response, error) {
+	project, err := s.get("project", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	return &response{list: s.resolve(project.ordered["sections"])}, nil
+}
+
+func (s *Server) createSection(r *request) (*response, error) {
+	project, err := s.get("project", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	name, err := required(r, "name")
+	if err != nil {
+		return nil, err
+	}
+
+	before, _ := r.data["insert_before"].(string)
+	after, _ := r.data["insert_after"].(string)
+
+	section := s.create("section", map[string]interface{}{
+		"name":    name,
+		"project": project.gid,
+	})
+	sections, err := insert(project.ordered["sections"], section.gid, before, after)
+	if err != nil {
+		s.delete(section.gid)
+		return nil, err
+	}
+	project.ordered["sections"] = sections
+
+	return &response{status: http.StatusCreated, object: section}, nil
+}
+
+func (s *Server) insertSection(r *request) (*response, error) {
+	project, err := s.get("project", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	section, err := required(r, "section")
+	if err != nil {
+		return nil, err
+	}
+	if !contains(project.ordered["sections"], section) {
+		return nil, errorf(http.StatusBadRequest, "section: Not a section in the project: %s", section)
+	}
+
+	before, _ := r.data["before_section"].(string)
+	after, _ := r.data["after_section"].(string)
+	if (before == "") == (after == "") {
+		return nil, errorf(http.StatusBadRequest, "One of before_section or after_section is required")
+	}
+
+	sections, err := insert(project.ordered["sections"], section, before, after)
+	if err != nil {
+		return nil, err
+	}
+	project.ordered["sections"] = sections
+
+	return &response{data: map[string]interface{}{}}, nil
+}
+
+func (s *Server) deleteSection(r *request) (*response, error) {
+	section, err := s.get("section", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	parent, err := s.sectionParent(section.refs["project"])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(s.sectionTasks(section)) > 0 {
+		return nil, errorf(http.StatusBadRequest, "Sections must be empty to be deleted")
+	}
+	if len(parent.ordered["sections"]) == 1 {
+		return nil, errorf(http.StatusBadRequest, "The last section in a %s cannot be deleted", kind(parent))
+	}
+
+	parent.ordered["sections"] = remove(parent.ordered["sections"], section.gid)
+	s.delete(section.gid)
+	return &response{data: map[string]interface{}{}}, nil
+}
+
+func (s *Server) sectionTasks(section *object) []*object {
+	parent, ok := s.objects[section.refs["project"]]
+	if !ok {
+		return []*object{}
+	}
+	tasks := s.resolve(parent.ordered["tasks"])
+	if parent.resourceType == "user_task_list" {
+		tasks = s.taskListTasks(parent)
+	}
+
+	result := []*object{}
+	for _, task := range tasks {
+		if task.sections[parent.gid] == section.gid {
+			result = append(result, task)
+		}
+	}
+	return result
+}
+
+// Webhooks
+
+func (s *Server) listWebhooks(r *request) (*response, error) {
+	workspace, err := required(r, "workspace")
+	if err != nil {
+		return nil, err
+	}
+	resource := r.query.Get("resource")
+
+	return &response{list: s.list("webhook", func(o *object) bool {
+		return o.refs["workspace"] == workspace && (resource == "" || o.refs["resource"] == resource)
+	})}, nil
+}
+
+// createWebhook registers a webhook. Unlike Asana, the server does not
+// perform the handshake with the target.
+func (s *Server) createWebhook(r *request) (*response, error) {
+	resource, err := required(r, "resource")
+	if err != nil {
+		return nil, err
+	}
+	target, err := required(r, "target")
+	if err != nil {
+		return nil, err
+	}
+	o, err := s.get("", resource)
+	if err != nil {
+		return nil, errorf(http.StatusBadRequest, "resource: Not a recognized ID: %s", resource)
+	}
+
+	workspace := o.refs["workspace"]
+	if o.resourceType == "workspace" {
+		workspace = o.gid
+	}
+
+	filters := r.data["filters"]
+	if filters == nil {
+		filters = []interface{}{}
+	}
+
+	webhook := s.create("webhook", map[string]interface{}{
+		"resource":  resource,
+		"workspace": workspace,
+		"active":    true,
+		"filters":   filters,
+	})
+
+	// The target is a URL rather than a reference to another object
+	webhook.fields["target"] = target
+	return &response{status: http.StatusCreated, object: webhook}, nil
+}
+
+// Batch API
+
+func (s *Server) batch(r *request) (*response, error) {
+	actions, ok := r.data["actions"].([]interface{})
+	if !ok {
+		return nil, errorf(http.StatusBadRequest, "actions: Missing input")
+	}
+	if len(actions) > 10 {
+		return nil, errorf(http.StatusBadRequest, "actions: Too many actions, the maximum is 10")
+	}
+
+	results := []interface{}{}
+	for _, a := range actions {
+		action, _ := a.(map[string]interface{})
+		method := strings.ToUpper(toString(action["method"]))
+		path := toString(action["relative_path"])
+
+		query := url.Values{}
+		if i := strings.Index(path, "?"); i >= 0 {
+			query, _ = url.ParseQuery(path[i+1:])
+			path = path[:i]
+		}
+
+		// GET actions take their parameters from data, other actions send it
+		// as the request body
+		body := map[string]interface{}{}
+		data, _ := action["data"].(map[string]interface{})
+		if method == http.MethodGet {
+			for key, value := range data {
+				query.Set(key, toString(value))
+			}
+		} else if data != nil {
+			body["data"] = data
+		}
+		if options, ok := action["options"].(map[string]interface{}); ok {
+			if fields := toStrings(options["fields"]); fields != nil {
+				query.Set("opt_fields", strings.Join(fields, ","))
+			}
+			if limit, ok := toNumber(options["limit"]); ok {
+				query.Set("limit", formatNumber(limit))
+			}
+			if offset := toString(options["offset"]); offset != "" {
+				query.Set("offset", offset)
+			}
+		}
+
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		status, result := s.dispatch(method, path, query, encoded)
+		results = append(results, map[string]interface{}{
+			"status_code": status,
+			"headers":     map[string]interface{}{},
+			"body":        result,
+		})
+	}
+
+	return &response{data: results}, nil
+}
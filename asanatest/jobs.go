@@ -0,0 +1,34 @@
+package asanatest
+
+// newJob records an asynchronous job which created the object gid, held in
+// key as new_project or new_task. The object itself is created straight
+// away; the job reports success after Server.JobPolls fetches.
+func (s *Server) newJob(subtype, key, gid string) *object {
+	status := "succeeded"
+	if s.JobPolls > 0 {
+		status = "in_progress"
+	}
+
+	job := s.create("job", map[string]interface{}{
+		"resource_subtype": subtype,
+		"status":           status,
+		key:                gid,
+	})
+	job.polls = s.JobPolls
+	return job
+}
+
+func (s *Server) getJob(r *request) (*response, error) {
+	job, err := s.get("job", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if job.polls > 0 {
+		job.polls--
+		if job.polls == 0 {
+			job.fields["status"] = "succeeded"
+		}
+	}
+	return &response{object: job}, nil
+}
@@ -0,0 +1,106 @@
+package asanatest
+
+import (
+	"net/http"
+)
+
+// Portfolios
+
+func (s *Server) listPortfolios(r *request) (*response, error) {
+	workspace, err := required(r, "workspace")
+	if err != nil {
+		return nil, err
+	}
+	owner, err := required(r, "owner")
+	if err != nil {
+		return nil, err
+	}
+	if owner == "me" {
+		owner = s.me
+	}
+
+	return &response{list: s.list("portfolio", func(o *object) bool {
+		return o.refs["workspace"] == workspace && o.refs["owner"] == owner
+	})}, nil
+}
+
+func (s *Server) createPortfolio(r *request) (*response, error) {
+	if _, err := required(r, "name"); err != nil {
+		return nil, err
+	}
+	workspace, err := required(r, "workspace")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.get("workspace", workspace); err != nil {
+		return nil, errorf(http.StatusBadRequest, "workspace: Not a recognized ID: %s", workspace)
+	}
+
+	portfolio := s.create("portfolio", map[string]interface{}{
+		"workspace":  workspace,
+		"owner":      s.me,
+		"created_by": s.me,
+		"public":     false,
+	})
+	if err := s.apply(portfolio, r.data, portfolioFields); err != nil {
+		s.delete(portfolio.gid)
+		return nil, err
+	}
+
+	// The creator is always a member
+	if !contains(portfolio.lists["members"], s.me) {
+		portfolio.lists["members"] = append([]string{s.me}, portfolio.lists["members"]...)
+	}
+	portfolio.fields["permalink_url"] = "https://app.asana.com/0/portfolio/" + portfolio.gid
+	return &response{status: http.StatusCreated, object: portfolio}, nil
+}
+
+func (s *Server) listPortfolioItems(r *request) (*response, error) {
+	portfolio, err := s.get("portfolio", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	return &response{list: s.resolve(portfolio.ordered["items"])}, nil
+}
+
+func (s *Server) addPortfolioItem(r *request) (*response, error) {
+	portfolio, err := s.get("portfolio", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	gid, err := required(r, "item")
+	if err != nil {
+		return nil, err
+	}
+	item, err := s.get("", gid)
+	if err != nil || (item.resourceType != "project" && item.resourceType != "portfolio") || gid == portfolio.gid {
+		return nil, errorf(http.StatusBadRequest, "item: Not a project or portfolio: %s", gid)
+	}
+
+	before, after := toString(r.data["insert_before"]), toString(r.data["insert_after"])
+	if before != "" && after != "" {
+		return nil, errorf(http.StatusBadRequest, "Only one of insert_before and insert_after may be given")
+	}
+	items, err := insert(portfolio.ordered["items"], item.gid, before, after)
+	if err != nil {
+		return nil, err
+	}
+	portfolio.ordered["items"] = items
+	return &response{data: map[string]interface{}{}}, nil
+}
+
+func (s *Server) removePortfolioItem(r *request) (*response, error) {
+	portfolio, err := s.get("portfolio", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	gid, err := required(r, "item")
+	if err != nil {
+		return nil, err
+	}
+	if !contains(portfolio.ordered["items"], gid) {
+		return nil, errorf(http.StatusBadRequest, "item: Not in the portfolio: %s", gid)
+	}
+	portfolio.ordered["items"] = remove(portfolio.ordered["items"], gid)
+	return &response{data: map[string]interface{}{}}, nil
+}
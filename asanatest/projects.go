@@ -0,0 +1,247 @@
+package asanatest
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Project members and followers
+
+// changeUsers adds or removes users from the members or followers of a
+// project or portfolio. Users may be given as an array or a comma separated
+// string.
+func (s *Server) changeUsers(resourceType, key string, add bool) handlerFunc {
+	return func(r *request) (*response, error) {
+		project, err := s.get(resourceType, r.params[0])
+		if err != nil {
+			return nil, err
+		}
+
+		value := r.data[key]
+		if list, ok := value.(string); ok {
+			var values []interface{}
+			for _, gid := range strings.Split(list, ",") {
+				values = append(values, strings.TrimSpace(gid))
+			}
+			value = values
+		}
+		users, err := s.references(key, value)
+		if err != nil {
+			return nil, err
+		}
+		if len(users) == 0 {
+			return nil, errorf(http.StatusBadRequest, "%s: Missing input", key)
+		}
+
+		for _, user := range users {
+			if _, err := s.get("user", user); err != nil {
+				return nil, errorf(http.StatusBadRequest, "%s: Not a user: %s", key, user)
+			}
+			switch {
+			case add:
+				if !contains(project.lists[key], user) {
+					project.lists[key] = append(project.lists[key], user)
+				}
+				// Followers are always members
+				if key == "followers" && !contains(project.lists["members"], user) {
+					project.lists["members"] = append(project.lists["members"], user)
+				}
+			default:
+				project.lists[key] = remove(project.lists[key], user)
+				// Members who leave stop following
+				if key == "members" {
+					project.lists["followers"] = remove(project.lists["followers"], user)
+				}
+			}
+		}
+		return &response{object: project}, nil
+	}
+}
+
+// Task counts
+
+func (s *Server) projectTaskCounts(r *request) (*response, error) {
+	project, err := s.get("project", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	for _, task := range s.projectTasks(project) {
+		kind := "tasks"
+		if task.fields["resource_subtype"] == "milestone" {
+			kind = "milestones"
+		}
+		state := "incomplete"
+		if toBool(task.fields["completed"]) {
+			state = "completed"
+		}
+		counts["num_"+kind]++
+		counts["num_"+state+"_"+kind]++
+	}
+
+	// Like Asana, only return the counts which were asked for
+	result := map[string]interface{}{}
+	for _, field := range r.fields {
+		result[field] = counts[field]
+	}
+	return &response{data: result}, nil
+}
+
+// Duplication
+
+func (s *Server) duplicateProject(r *request) (*response, error) {
+	source, err := s.get("project", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	name, err := required(r, "name")
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := s.copyProject(source, name, toString(r.data["team"]), includes(r.data))
+	if err != nil {
+		return nil, err
+	}
+	return &response{status: http.StatusCreated, object: s.newJob("duplicate_project", "new_project", project.gid)}, nil
+}
+
+// includes parses the comma separated include option of a duplicate request
+func includes(data map[string]interface{}) map[string]bool {
+	result := map[string]bool{}
+	for _, part := range strings.Split(toString(data["include"]), ",") {
+		result[strings.TrimSpace(part)] = true
+	}
+	return result
+}
+
+// copyProject copies a project with its sections, custom fields and tasks.
+// Include holds the optional parts to copy, named as for the duplicate
+// endpoint; the parts of tasks to copy are prefixed with task_.
+func (s *Server) copyProject(source *object, name, team string, include map[string]bool) (*object, error) {
+	fields := map[string]interface{}{
+		"name":         name,
+		"workspace":    source.refs["workspace"],
+		"owner":        s.me,
+		"members":      []string{s.me},
+		"followers":    []string{s.me},
+		"archived":     false,
+		"default_view": source.fields["default_view"],
+	}
+	for _, key := range []string{"color", "public", "icon"} {
+		if value, ok := source.fields[key]; ok {
+			fields[key] = value
+		}
+	}
+	if team != "" {
+		if _, err := s.get("team", team); err != nil {
+			return nil, errorf(http.StatusBadRequest, "team: Not a recognized ID: %s", team)
+		}
+		fields["team"] = team
+	} else if team, ok := source.refs["team"]; ok {
+		fields["team"] = team
+	}
+	if include["notes"] {
+		fields["notes"] = source.fields["notes"]
+		fields["html_notes"] = source.fields["html_notes"]
+	}
+	if include["members"] {
+		members := append([]string{}, source.lists["members"]...)
+		if !contains(members, s.me) {
+			members = append(members, s.me)
+		}
+		fields["members"] = members
+	}
+	project := s.create("project", fields)
+
+	sections := map[string]string{}
+	for _, section := range s.resolve(source.ordered["sections"]) {
+		copied := s.create("section", map[string]interface{}{
+			"name":    section.fields["name"],
+			"project": project.gid,
+		})
+		project.ordered["sections"] = append(project.ordered["sections"], copied.gid)
+		sections[section.gid] = copied.gid
+	}
+
+	for _, setting := range s.customFieldSettings(source) {
+		copied := s.create("custom_field_setting", map[string]interface{}{
+			"project":      project.gid,
+			"custom_field": setting.refs["custom_field"],
+			"is_important": setting.fields["is_important"],
+		})
+		project.ordered["custom_field_settings"] = append(project.ordered["custom_field_settings"], copied.gid)
+	}
+
+	taskInclude := map[string]bool{}
+	for part := range include {
+		if strings.HasPrefix(part, "task_") {
+			taskInclude[strings.TrimPrefix(part, "task_")] = true
+		}
+	}
+	for _, task := range s.projectTasks(source) {
+		copied := s.copyTask(task, taskInclude)
+		if err := s.addToProject(copied, project.gid, sections[task.sections[source.gid]], nil, false); err != nil {
+			return nil, err
+		}
+	}
+	return project, nil
+}
+
+// copyTask copies a task, including the parts named in include as for the
+// task duplicate endpoint. The copy is not added to any projects.
+func (s *Server) copyTask(task *object, include map[string]bool) *object {
+	fields := map[string]interface{}{
+		"workspace":        task.refs["workspace"],
+		"created_by":       s.me,
+		"name":             task.fields["name"],
+		"notes":            "",
+		"completed":        task.fields["completed"],
+		"completed_at":     task.fields["completed_at"],
+		"resource_subtype": task.fields["resource_subtype"],
+		"followers":        []string{s.me},
+	}
+	if include["notes"] {
+		fields["notes"] = task.fields["notes"]
+		fields["html_notes"] = task.fields["html_notes"]
+	}
+	if include["assignee"] {
+		if assignee, ok := task.refs["assignee"]; ok {
+			fields["assignee"] = assignee
+		}
+	}
+	if include["dates"] {
+		for key, value := range taskDates(task) {
+			fields[key] = value
+		}
+	}
+	if include["followers"] {
+		fields["followers"] = append([]string{}, task.lists["followers"]...)
+	}
+
+	copied := s.create("task", fields)
+	copied.lists["tags"] = []string{}
+	if include["tags"] {
+		copied.lists["tags"] = append([]string{}, task.lists["tags"]...)
+	}
+	for field, value := range task.customFields {
+		copied.customFields[field] = value
+	}
+
+	if include["dependencies"] {
+		for _, dependency := range s.resolve(task.lists["dependencies"]) {
+			copied.lists["dependencies"] = append(copied.lists["dependencies"], dependency.gid)
+			dependency.lists["dependents"] = append(dependency.lists["dependents"], copied.gid)
+		}
+	}
+
+	if include["subtasks"] {
+		for _, subtask := range s.subtasks(task) {
+			child := s.copyTask(subtask, include)
+			child.refs["parent"] = copied.gid
+			copied.ordered["subtasks"] = append(copied.ordered["subtasks"], child.gid)
+		}
+	}
+	return copied
+}
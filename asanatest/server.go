@@ -0,0 +1,383 @@
+// Package asanatest provides an in-process fake of the Asana API for testing
+// code built on the asana package.
+//
+// The fake keeps workspaces, users, teams, projects, project templates,
+// portfolios, sections, tasks, user task lists, stories, status updates,
+// goals, time periods, tags, custom fields, webhooks and jobs in memory. It
+// honours opt_fields, limit and offset pagination, returns Asana-shaped error
+// responses and can be told to fail requests with rate limit or server
+// errors.
+//
+//	server := asanatest.NewServer()
+//	defer server.Close()
+//
+//	workspace := server.AddWorkspace("Acme")
+//	client := server.NewClient()
+//	project, err := client.CreateProject(ctx, &asana.CreateProjectRequest{
+//		ProjectBase: asana.ProjectBase{Name: "Incidents"},
+//		Workspace:   workspace.ID,
+//	})
+package asanatest // import "github.com/incident-io/asana-go/asanatest"
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	asana "github.com/incident-io/asana-go"
+)
+
+// Server is a fake Asana API server. The zero value is not usable; create
+// servers with NewServer.
+type Server struct {
+	*httptest.Server
+
+	// Now returns the time used for created_at and modified_at fields.
+	// Defaults to time.Now.
+	Now func() time.Time
+
+	// JobPolls is the number of times a job must be fetched before it
+	// succeeds. Zero completes jobs as soon as they are created.
+	JobPolls int
+
+	mu       sync.Mutex
+	nextID   int
+	objects  map[string]*object
+	order    []string
+	me       string
+	faults   []*Fault
+	requests int
+	routes   []route
+}
+
+// Fault describes requests the server should fail instead of handling
+type Fault struct {
+	// The HTTP method to match, or empty to match any method
+	Method string
+
+	// A prefix of the request path to match, such as "/tasks", or empty to
+	// match any path
+	Path string
+
+	// The status code to respond with, for example 429 or 503
+	StatusCode int
+
+	// The value of the Retry-After header, in seconds, if non-zero
+	RetryAfter int
+
+	// The number of matching requests to fail. Zero fails a single request;
+	// a negative number fails every matching request.
+	Times int
+}
+
+// NewServer starts a fake Asana server. The authenticated user is created
+// automatically and is available from Me.
+func NewServer() *Server {
+	s := &Server{
+		Now:     time.Now,
+		nextID:  1000,
+		objects: map[string]*object{},
+	}
+	s.me = s.create("user", map[string]interface{}{
+		"name":  "Test User",
+		"email": "test@example.com",
+	}).gid
+	s.registerRoutes()
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// NewClient returns an asana.Client which sends requests to this server
+func (s *Server) NewClient() *asana.Client {
+	client := asana.NewClient(s.Server.Client())
+	client.BaseURL, _ = url.Parse(s.URL)
+	return client
+}
+
+// AddFault makes the server fail matching requests
+func (s *Server) AddFault(fault *Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if fault.Times == 0 {
+		fault.Times = 1
+	}
+	s.faults = append(s.faults, fault)
+}
+
+// RequestCount returns the number of requests the server has received
+func (s *Server) RequestCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests
+}
+
+// apiError is an error which is reported to the client with an Asana-shaped
+// error envelope
+type apiError struct {
+	status  int
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func errorf(status int, format string, args ...interface{}) *apiError {
+	return &apiError{status: status, message: fmt.Sprintf(format, args...)}
+}
+
+func notFound(gid string) *apiError {
+	return errorf(http.StatusNotFound, "%s: Unknown object: %s", gid, gid)
+}
+
+// request is a decoded API request passed to route handlers
+type request struct {
+	method string
+	params []string
+	query  url.Values
+	data   map[string]interface{}
+	fields []string
+}
+
+// response is what a route handler returns: either a single object, a
+// list of objects which will be paginated, or raw data
+type response struct {
+	status int
+	object *object
+	list   []*object
+	data   interface{}
+}
+
+type handlerFunc func(r *request) (*response, error)
+
+type route struct {
+	method   string
+	segments []string
+	handler  handlerFunc
+}
+
+func (s *Server) handle(method, pattern string, handler handlerFunc) {
+	s.routes = append(s.routes, route{
+		method:   method,
+		segments: strings.Split(strings.Trim(pattern, "/"), "/"),
+		handler:  handler,
+	})
+}
+
+func (s *Server) match(method, path string) (handlerFunc, []string, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	pathFound := false
+
+	for _, route := range s.routes {
+		if len(route.segments) != len(segments) {
+			continue
+		}
+
+		var params []string
+		matched := true
+		for i, segment := range route.segments {
+			if segment == "{}" {
+				params = append(params, segments[i])
+			} else if segment != segments[i] {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		pathFound = true
+		if route.method == method {
+			return route.handler, params, true
+		}
+	}
+	return nil, nil, pathFound
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+
+	if fault := s.fault(r); fault != nil {
+		if fault.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(fault.RetryAfter))
+		}
+		writeError(w, errorf(fault.StatusCode, "%s", http.StatusText(fault.StatusCode)))
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, errorf(http.StatusBadRequest, "Unable to read request body"))
+		return
+	}
+
+	status, result := s.dispatch(r.Method, r.URL.Path, r.URL.Query(), body)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
+
+func (s *Server) fault(r *http.Request) *Fault {
+	for i, fault := range s.faults {
+		if fault.Method != "" && fault.Method != r.Method {
+			continue
+		}
+		if !strings.HasPrefix(r.URL.Path, fault.Path) {
+			continue
+		}
+
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return fault
+	}
+	return nil
+}
+
+// dispatch handles a single API request and returns the status code and
+// response envelope
+func (s *Server) dispatch(method, path string, query url.Values, body []byte) (int, interface{}) {
+	handler, params, found := s.match(method, path)
+	if handler == nil {
+		if found {
+			return envelope(errorf(http.StatusMethodNotAllowed, "%s is not allowed on %s", method, path))
+		}
+		return envelope(errorf(http.StatusNotFound, "No matching route for request"))
+	}
+
+	req := &request{
+		method: method,
+		params: params,
+		query:  query,
+	}
+
+	// Decode the request body and options
+	if len(body) > 0 {
+		payload := struct {
+			Data    map[string]interface{} `json:"data"`
+			Options struct {
+				Fields []string `json:"fields"`
+			} `json:"options"`
+		}{}
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		if err := decoder.Decode(&payload); err != nil {
+			return errorEnvelope(errorf(http.StatusBadRequest, "Could not parse request data, invalid JSON"))
+		}
+		req.data = payload.Data
+		req.fields = payload.Options.Fields
+	}
+	if req.data == nil {
+		req.data = map[string]interface{}{}
+	}
+	if fields := query.Get("opt_fields"); fields != "" {
+		req.fields = strings.Split(fields, ",")
+	}
+
+	resp, err := handler(req)
+	if err != nil {
+		return errorEnvelope(err)
+	}
+	return s.render(req, resp)
+}
+
+func (s *Server) render(req *request, resp *response) (int, interface{}) {
+	status := resp.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	switch {
+	case resp.object != nil:
+		return status, map[string]interface{}{"data": s.view(resp.object, req.fields, false)}
+	case resp.list != nil:
+		return s.page(req, resp.list)
+	case resp.data != nil:
+		return status, map[string]interface{}{"data": resp.data}
+	}
+	return status, map[string]interface{}{"data": map[string]interface{}{}}
+}
+
+// page applies limit and offset pagination to a list of objects
+func (s *Server) page(req *request, list []*object) (int, interface{}) {
+	start, end := 0, len(list)
+
+	if offset := req.query.Get("offset"); offset != "" {
+		n, err := strconv.Atoi(strings.TrimPrefix(offset, "offset-"))
+		if err != nil || !strings.HasPrefix(offset, "offset-") || n < 0 || n > len(list) {
+			return errorEnvelope(errorf(http.StatusBadRequest, "offset: Your pagination token is invalid"))
+		}
+		start = n
+	}
+
+	var nextPage interface{}
+	if limit := req.query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > 100 {
+			return errorEnvelope(errorf(http.StatusBadRequest, "limit: Must be between 1 and 100"))
+		}
+		if start+n < len(list) {
+			end = start + n
+			offset := fmt.Sprintf("offset-%d", end)
+			nextPage = map[string]interface{}{
+				"offset": offset,
+				"path":   "?offset=" + offset,
+				"uri":    s.URL + "?offset=" + offset,
+			}
+		}
+	}
+
+	data := []interface{}{}
+	for _, o := range list[start:end] {
+		data = append(data, s.view(o, req.fields, true))
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"data":      data,
+		"next_page": nextPage,
+	}
+}
+
+func errorEnvelope(err error) (int, interface{}) {
+	apiErr, ok := err.(*apiError)
+	if !ok {
+		apiErr = errorf(http.StatusInternalServerError, "%s", err)
+	}
+	return envelope(apiErr)
+}
+
+func envelope(err *apiError) (int, interface{}) {
+	return err.status, map[string]interface{}{
+		"errors": []interface{}{
+			map[string]interface{}{
+				"message": err.message,
+				"help":    "For more information on API status codes and how to handle them, read the docs on errors: https://developers.asana.com/docs/errors",
+			},
+		},
+	}
+}
+
+func writeError(w http.ResponseWriter, err *apiError) {
+	status, body := envelope(err)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
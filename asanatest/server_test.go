@@ -0,0 +1,1172 @@
+package asanatest
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	asana "github.com/incident-io/asana-go"
+	"github.com/incident-io/asana-go/asanahtml"
+)
+
+func newTestServer(t *testing.T) (*Server, *asana.Client, *asana.Workspace) {
+	server := NewServer()
+	t.Cleanup(server.Close)
+
+	client := server.NewClient()
+	client.RetryPolicy = &asana.RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  5 * time.Millisecond,
+	}
+	return server, client, server.AddWorkspace("Acme")
+}
+
+func createProject(t *testing.T, client *asana.Client, workspace *asana.Workspace, name string) *asana.Project {
+	project, err := client.CreateProject(context.Background(), &asana.CreateProjectRequest{
+		ProjectBase: asana.ProjectBase{Name: name},
+		Workspace:   workspace.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return project
+}
+
+func TestProjectTasks(t *testing.T) {
+	ctx := context.Background()
+	_, client, workspace := newTestServer(t)
+	project := createProject(t, client, workspace, "Incidents")
+
+	sections, _, err := project.Sections(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sections) != 1 || sections[0].Name != "Untitled section" {
+		t.Fatalf("Expected a single default section, saw %+v", sections)
+	}
+
+	for _, name := range []string{"One", "Two", "Three", "Four", "Five"} {
+		_, err := client.CreateTask(ctx, &asana.CreateTaskRequest{
+			TaskBase: asana.TaskBase{Name: name},
+			Projects: []string{project.ID},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	it := project.IterateTasks(ctx, client, &asana.Options{Fields: []string{"name", "memberships.section.name"}})
+	it.PageSize = 2
+
+	var names []string
+	for it.Next() {
+		task := it.Value()
+		names = append(names, task.Name)
+		if len(task.Memberships) != 1 || task.Memberships[0].Section.Name != "Untitled section" {
+			t.Errorf("Expected task %q to be in the default section, saw %+v", task.Name, task.Memberships)
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 5 || names[0] != "One" || names[4] != "Five" {
+		t.Errorf("Expected five tasks in creation order, saw %v", names)
+	}
+}
+
+func TestTaskStories(t *testing.T) {
+	ctx := context.Background()
+	server, client, workspace := newTestServer(t)
+
+	task, err := client.CreateTask(ctx, &asana.CreateTaskRequest{
+		TaskBase:  asana.TaskBase{Name: "Investigate"},
+		Workspace: workspace.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	completed := true
+	err = task.Update(ctx, client, &asana.UpdateTaskRequest{
+		TaskBase: asana.TaskBase{Name: "Resolve", Completed: &completed},
+		Assignee: server.Me().ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Name != "Resolve" || task.CompletedAt == nil || task.Assignee == nil {
+		t.Errorf("Expected the updated task to be returned, saw %+v", task)
+	}
+
+	stories, _, err := task.Stories(ctx, client, &asana.Options{Fields: []string{"resource_subtype", "old_name", "new_name"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subtypes := map[string]*asana.Story{}
+	for _, story := range stories {
+		subtypes[story.ResourceSubtype] = story
+	}
+	for _, subtype := range []string{"assigned", "name_changed", "marked_complete"} {
+		if subtypes[subtype] == nil {
+			t.Errorf("Expected a %s story, saw %v", subtype, subtypes)
+		}
+	}
+	if story := subtypes["name_changed"]; story != nil && (story.OldName != "Investigate" || story.NewName != "Resolve") {
+		t.Errorf("Unexpected name change %q -> %q", story.OldName, story.NewName)
+	}
+}
+
+func TestCustomFieldValues(t *testing.T) {
+	ctx := context.Background()
+	server, client, workspace := newTestServer(t)
+	project := createProject(t, client, workspace, "Incidents")
+
+	field, err := client.CreateCustomField(ctx, &asana.CreateCustomFieldRequest{
+		CustomFieldBase: asana.CustomFieldBase{Name: "Severity", ResourceSubtype: asana.Enum},
+		Workspace:       workspace.ID,
+		EnumOptions:     []*asana.EnumValueBase{{Name: "Major"}, {Name: "Minor"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(field.EnumOptions) != 2 {
+		t.Fatalf("Expected two enum options, saw %d", len(field.EnumOptions))
+	}
+
+	_, err = client.CreateCustomField(ctx, &asana.CreateCustomFieldRequest{
+		CustomFieldBase: asana.CustomFieldBase{Name: "severity", ResourceSubtype: asana.Text},
+		Workspace:       workspace.ID,
+	})
+	if err == nil {
+		t.Error("Expected a duplicate custom field name to be rejected")
+	}
+
+	// Values can only be set for fields on one of the task's projects
+	request := &asana.CreateTaskRequest{
+		TaskBase:     asana.TaskBase{Name: "Outage"},
+		Projects:     []string{project.ID},
+		CustomFields: map[string]interface{}{field.ID: field.EnumOptions[0].ID},
+	}
+	if _, err := client.CreateTask(ctx, request); err == nil {
+		t.Error("Expected a custom field which is not on the project to be rejected")
+	}
+
+	if _, err := project.AddCustomFieldSetting(ctx, client, &asana.AddCustomFieldSettingRequest{CustomField: field.ID}); err != nil {
+		t.Fatal(err)
+	}
+	task, err := client.CreateTask(ctx, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(task.CustomFields) != 1 || task.CustomFields[0].EnumValue == nil || task.CustomFields[0].EnumValue.Name != "Major" {
+		t.Errorf("Expected the enum value to be set, saw %+v", task.CustomFields)
+	}
+
+	request.CustomFields = map[string]interface{}{field.ID: "12345"}
+	_, err = client.CreateTask(ctx, request)
+	if asanaErr, ok := asana.IsAsanaError(err); !ok || asanaErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected an invalid enum option to be rejected with 400, saw %v", err)
+	}
+
+	// Typed values are checked against the field before they are sent
+	severity := task.CustomFieldByName("Severity")
+	before := server.RequestCount()
+	if err := task.SetCustomField(ctx, client, &severity.CustomField, asana.NewEnumValue("12345")); err == nil {
+		t.Error("Expected an unknown enum option to be rejected")
+	}
+	if server.RequestCount() != before {
+		t.Error("Expected an invalid value not to be sent")
+	}
+
+	minor := severity.EnumOptionByName("Minor")
+	if err := task.SetCustomField(ctx, client, &severity.CustomField, asana.NewEnumValue(minor.ID)); err != nil {
+		t.Fatal(err)
+	}
+	if value := task.CustomFieldByID(field.ID); value.EnumValue == nil || value.EnumValue.ID != minor.ID {
+		t.Errorf("Expected the enum value to be updated, saw %+v", value)
+	}
+}
+
+func TestFaults(t *testing.T) {
+	server, client, workspace := newTestServer(t)
+	server.AddFault(&Fault{Path: "/workspaces", StatusCode: http.StatusTooManyRequests, Times: 2})
+
+	before := server.RequestCount()
+	if err := workspace.Fetch(context.Background(), client); err != nil {
+		t.Fatal(err)
+	}
+	if requests := server.RequestCount() - before; requests != 3 {
+		t.Errorf("Expected the request to be retried twice, saw %d requests", requests)
+	}
+
+	server.AddFault(&Fault{Method: http.MethodGet, StatusCode: http.StatusInternalServerError, Times: -1})
+	err := workspace.Fetch(context.Background(), client)
+	if asanaErr, ok := asana.IsAsanaError(err); !ok || asanaErr.StatusCode != http.StatusInternalServerError || asanaErr.Attempts != 3 {
+		t.Errorf("Expected a server error after three attempts, saw %v", err)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	_, client, _ := newTestServer(t)
+
+	err := (&asana.Task{ID: "404"}).Fetch(context.Background(), client)
+	if !asana.IsNotFoundError(err) {
+		t.Errorf("Expected a not found error, saw %v", err)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	ctx := context.Background()
+	_, client, workspace := newTestServer(t)
+
+	batch := client.NewBatch()
+	var actions []*asana.BatchAction
+	for i := 0; i < 12; i++ {
+		_, action := batch.CreateTask(&asana.CreateTaskRequest{
+			TaskBase:  asana.TaskBase{Name: "Task"},
+			Workspace: workspace.ID,
+		})
+		actions = append(actions, action)
+	}
+	missing := batch.FetchTask(&asana.Task{ID: "404"})
+
+	if err := batch.Execute(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, action := range actions {
+		if err := action.Err(); err != nil {
+			t.Error(err)
+		}
+	}
+	if !asana.IsNotFoundError(missing.Err()) {
+		t.Errorf("Expected a not found error, saw %v", missing.Err())
+	}
+}
+
+func TestCustomFieldLifecycle(t *testing.T) {
+	ctx := context.Background()
+	_, client, workspace := newTestServer(t)
+	project := createProject(t, client, workspace, "Incidents")
+
+	field, err := client.CreateCustomField(ctx, &asana.CreateCustomFieldRequest{
+		CustomFieldBase: asana.CustomFieldBase{Name: "Status", ResourceSubtype: asana.Enum},
+		Workspace:       workspace.ID,
+		EnumOptions:     []*asana.EnumValueBase{{Name: "Open"}, {Name: "Closed"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := field.Update(ctx, client, &asana.UpdateCustomFieldRequest{Name: "Incident status"}); err != nil {
+		t.Fatal(err)
+	}
+	if field.Name != "Incident status" || len(field.EnumOptions) != 2 {
+		t.Errorf("Expected the field to be renamed, saw %+v", field)
+	}
+
+	open := field.EnumOptionByName("Open")
+	fixing, err := field.CreateEnumOption(ctx, client, &asana.CreateEnumOptionRequest{Name: "Fixing", InsertAfter: open.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := field.InsertEnumOption(ctx, client, &asana.InsertEnumOptionRequest{EnumOption: fixing.ID, BeforeEnumOption: open.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	disabled := false
+	if err := open.Update(ctx, client, &asana.UpdateEnumOptionRequest{Color: "red", Enabled: &disabled}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The local order should match the server's
+	local := field.EnumOptions
+	if err := field.Fetch(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for i, option := range field.EnumOptions {
+		names = append(names, option.Name)
+		if local[i].ID != option.ID {
+			t.Errorf("Expected option %d to be %q locally, saw %q", i, option.Name, local[i].Name)
+		}
+	}
+	if len(names) != 3 || names[0] != "Fixing" || names[1] != "Open" || names[2] != "Closed" {
+		t.Errorf("Unexpected option order %v", names)
+	}
+	if o := field.EnumOptionByName("Open"); o.Enabled || o.Color != "red" {
+		t.Errorf("Expected the option to be disabled and recolored, saw %+v", o)
+	}
+
+	if _, err := project.AddCustomFieldSetting(ctx, client, &asana.AddCustomFieldSettingRequest{CustomField: field.ID}); err != nil {
+		t.Fatal(err)
+	}
+	settings, _, err := project.ListCustomFieldSettings(ctx, client, &asana.Options{Fields: []string{"custom_field.name"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(settings) != 1 || settings[0].CustomField.Name != "Incident status" {
+		t.Errorf("Expected the field to be on the project, saw %+v", settings)
+	}
+
+	if err := field.Delete(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	if err := field.Fetch(ctx, client); !asana.IsNotFoundError(err) {
+		t.Errorf("Expected the field to be deleted, saw %v", err)
+	}
+}
+
+func TestProjectLifecycle(t *testing.T) {
+	ctx := context.Background()
+	server, client, workspace := newTestServer(t)
+	project := createProject(t, client, workspace, "Incident template")
+	alice := server.AddUser("Alice", "alice@example.com")
+
+	if err := project.AddFollowers(ctx, client, alice.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(project.Members) != 2 || len(project.Followers) != 2 {
+		t.Errorf("Expected a new follower to become a member, saw %+v", project)
+	}
+	if err := project.RemoveMembers(ctx, client, alice.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(project.Members) != 1 || len(project.Followers) != 1 {
+		t.Errorf("Expected a removed member to stop following, saw %+v", project)
+	}
+
+	done := true
+	for _, name := range []string{"Triage", "Mitigate"} {
+		_, err := client.CreateTask(ctx, &asana.CreateTaskRequest{
+			TaskBase: asana.TaskBase{Name: name, Notes: "Steps for " + name, Completed: &done},
+			Projects: []string{project.ID},
+			Assignee: server.Me().ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		done = false
+	}
+
+	counts, err := project.TaskCounts(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts.NumTasks != 2 || counts.NumCompletedTasks != 1 || counts.NumIncompleteTasks != 1 {
+		t.Errorf("Unexpected task counts %+v", counts)
+	}
+
+	job, err := project.Duplicate(ctx, client, &asana.DuplicateProjectRequest{
+		Name:    "Incident 42",
+		Include: []asana.ProjectInclude{asana.ProjectIncludeTaskNotes},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.ResourceSubtype != "duplicate_project" || job.NewProject == nil || job.NewProject.Name != "Incident 42" {
+		t.Fatalf("Unexpected job %+v", job)
+	}
+
+	tasks, _, err := job.NewProject.Tasks(ctx, client, &asana.Options{Fields: []string{"name", "notes", "assignee"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 || tasks[0].Notes != "Steps for Triage" || tasks[0].Assignee != nil {
+		t.Errorf("Expected tasks to be copied with notes but not assignees, saw %+v", tasks)
+	}
+
+	if err := project.Archive(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	if err := project.Unarchive(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	if project.Archived {
+		t.Error("Expected the project to be unarchived")
+	}
+
+	if err := project.Delete(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	if err := project.Fetch(ctx, client); !asana.IsNotFoundError(err) {
+		t.Errorf("Expected the project to be deleted, saw %v", err)
+	}
+}
+
+func TestJobs(t *testing.T) {
+	ctx := context.Background()
+	server, client, workspace := newTestServer(t)
+	project := createProject(t, client, workspace, "Template")
+	server.JobPolls = 2
+
+	job, err := project.Duplicate(ctx, client, &asana.DuplicateProjectRequest{Name: "Copy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != asana.JobInProgress {
+		t.Errorf("Expected the job to be in progress, saw %q", job.Status)
+	}
+
+	copied, _, err := job.Wait(ctx, client, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if copied == nil || copied.Name != "Copy" || job.Status != asana.JobSucceeded {
+		t.Errorf("Expected the new project once the job succeeded, saw %+v", job)
+	}
+}
+
+func TestDuplicateTask(t *testing.T) {
+	ctx := context.Background()
+	_, client, workspace := newTestServer(t)
+	project := createProject(t, client, workspace, "Runbooks")
+
+	task, err := client.CreateTask(ctx, &asana.CreateTaskRequest{
+		TaskBase: asana.TaskBase{Name: "Checklist", Notes: "Page the on-call"},
+		Projects: []string{project.ID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := task.CreateSubtask(ctx, client, &asana.Task{TaskBase: asana.TaskBase{Name: "Open a channel"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	job, err := task.Duplicate(ctx, client, &asana.DuplicateTaskRequest{
+		Name:    "Checklist for incident 42",
+		Include: []asana.TaskInclude{asana.TaskIncludeNotes, asana.TaskIncludeSubtasks, asana.TaskIncludeProjects},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, copied, err := job.Wait(ctx, client, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := copied.Fetch(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	if copied.Notes != "Page the on-call" || copied.NumSubtasks != 1 || len(copied.Projects) != 1 {
+		t.Errorf("Expected notes, subtasks and projects to be copied, saw %+v", copied)
+	}
+}
+
+func TestProjectTemplates(t *testing.T) {
+	ctx := context.Background()
+	server, client, workspace := newTestServer(t)
+	team := server.AddTeam(workspace.ID, "Platform")
+	project, err := team.CreateProject(ctx, client, &asana.CreateProjectRequest{ProjectBase: asana.ProjectBase{Name: "Postmortem"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CreateTask(ctx, &asana.CreateTaskRequest{TaskBase: asana.TaskBase{Name: "Write timeline"}, Projects: []string{project.ID}}); err != nil {
+		t.Fatal(err)
+	}
+	server.AddProjectTemplate(project.ID, "Postmortem template", []string{"Incident date"}, []string{"Author"})
+
+	templates, _, err := team.ProjectTemplates(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(templates) != 1 {
+		t.Fatalf("Expected one template, saw %d", len(templates))
+	}
+	template := templates[0]
+	if err := template.Fetch(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	if len(template.RequestedDates) != 1 || len(template.RequestedRoles) != 1 || template.Team == nil {
+		t.Fatalf("Unexpected template %+v", template)
+	}
+
+	request := &asana.InstantiateProjectRequest{Name: "Postmortem: incident 42", IsStrict: true}
+	if _, err := template.InstantiateProject(ctx, client, request); err == nil {
+		t.Error("Expected missing requested dates to be rejected")
+	}
+
+	date := asana.Date(time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC))
+	request.RequestedDates = []*asana.RequestedDate{{ID: template.RequestedDates[0].ID, Value: &date}}
+	request.RequestedRoles = []*asana.RoleAssignment{{ID: template.RequestedRoles[0].ID, Value: server.Me().ID}}
+	job, err := template.InstantiateProject(ctx, client, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, _, err := job.Wait(ctx, client, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tasks, _, err := created.Tasks(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Name != "Postmortem: incident 42" || len(tasks) != 1 || tasks[0].Name != "Write timeline" {
+		t.Errorf("Expected the template's tasks to be copied, saw %+v with %+v", created, tasks)
+	}
+
+	if _, _, err := workspace.ProjectTemplates(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStatusUpdates(t *testing.T) {
+	ctx := context.Background()
+	_, client, workspace := newTestServer(t)
+	project := createProject(t, client, workspace, "Migration")
+
+	if _, err := project.CreateStatusUpdate(ctx, client, &asana.StatusUpdateBase{Title: "Week 1"}); err == nil {
+		t.Error("Expected a status update without a status type or text to be rejected")
+	}
+	for _, update := range []*asana.StatusUpdateBase{
+		{Title: "Week 1", Text: "Started", StatusType: asana.StatusOnTrack},
+		{Title: "Week 2", HTMLText: "<body>Blocked on <strong>review</strong></body>", StatusType: asana.StatusAtRisk},
+	} {
+		if _, err := project.CreateStatusUpdate(ctx, client, update); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	it := project.IterateStatusUpdates(ctx, client, &asana.Options{Fields: []string{"title", "status_type", "parent.name"}})
+	it.PageSize = 1
+	var updates []*asana.StatusUpdate
+	for it.Next() {
+		updates = append(updates, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 2 || updates[0].Title != "Week 2" || updates[0].StatusType != asana.StatusAtRisk || updates[1].Parent.Name != "Migration" {
+		t.Fatalf("Expected both updates newest first, saw %+v", updates)
+	}
+
+	latest := updates[0]
+	if err := latest.Fetch(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	if latest.ResourceSubtype != "project_status_update" || latest.Author == nil || latest.CreatedAt == nil {
+		t.Errorf("Unexpected status update %+v", latest)
+	}
+
+	if err := latest.Delete(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	remaining, _, err := project.StatusUpdates(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining) != 1 {
+		t.Errorf("Expected one status update after deleting, saw %d", len(remaining))
+	}
+}
+
+func TestGoals(t *testing.T) {
+	ctx := context.Background()
+	server, client, workspace := newTestServer(t)
+	project := createProject(t, client, workspace, "Error budgets")
+	year := server.AddTimePeriod(workspace.ID, "FY", "2021-01-01", "2021-12-31", "")
+	quarter := server.AddTimePeriod(workspace.ID, "Q1", "2021-01-01", "2021-03-31", year.ID)
+
+	periods, err := workspace.AllTimePeriods(ctx, client, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(periods) != 2 {
+		t.Fatalf("Expected two time periods, saw %d", len(periods))
+	}
+	if err := quarter.Fetch(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	if quarter.DisplayName != "Q1 FY21" || quarter.Parent == nil || quarter.Parent.ID != year.ID {
+		t.Errorf("Unexpected time period %+v", quarter)
+	}
+
+	goal, err := client.CreateGoal(ctx, &asana.CreateGoalRequest{
+		GoalBase:   asana.GoalBase{Name: "99.9% availability"},
+		Workspace:  workspace.ID,
+		TimePeriod: quarter.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := goal.SetMetric(ctx, client, &asana.SetMetricRequest{Unit: asana.MetricPercentage, Precision: 1, TargetNumberValue: 99.9}); err != nil {
+		t.Fatal(err)
+	}
+	if err := goal.UpdateMetricCurrentValue(ctx, client, 99.5); err != nil {
+		t.Fatal(err)
+	}
+	if goal.Metric == nil || goal.Metric.CurrentNumberValue != 99.5 || goal.Metric.TargetNumberValue != 99.9 {
+		t.Errorf("Unexpected metric %+v", goal.Metric)
+	}
+	if err := goal.Update(ctx, client, &asana.UpdateGoalRequest{Status: asana.GoalAtRisk}); err != nil {
+		t.Fatal(err)
+	}
+
+	subgoal, err := client.CreateGoal(ctx, &asana.CreateGoalRequest{GoalBase: asana.GoalBase{Name: "API availability"}, Workspace: workspace.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	weight := 0.5
+	for _, request := range []*asana.AddSupportingRelationshipRequest{
+		{SupportingResource: project.ID},
+		{SupportingResource: subgoal.ID, ContributionWeight: &weight},
+	} {
+		if _, err := goal.AddSupportingRelationship(ctx, client, request); err != nil {
+			t.Fatal(err)
+		}
+	}
+	relationships, _, err := goal.Relationships(ctx, client, asana.Subgoal, &asana.Options{Fields: []string{"supporting_resource.name", "contribution_weight"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(relationships) != 1 || relationships[0].SupportingResource.Name != "API availability" || relationships[0].ContributionWeight != 0.5 {
+		t.Errorf("Unexpected relationships %+v", relationships)
+	}
+	parents, _, err := subgoal.ParentGoals(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parents) != 1 || parents[0].ID != goal.ID {
+		t.Errorf("Expected the goal to be the subgoal's parent, saw %+v", parents)
+	}
+
+	supported, err := project.AllGoals(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(supported) != 1 || supported[0].ID != goal.ID {
+		t.Errorf("Expected the project to support the goal, saw %+v", supported)
+	}
+	if err := goal.RemoveSupportingRelationship(ctx, client, project.ID); err != nil {
+		t.Fatal(err)
+	}
+	if supported, _, err = project.Goals(ctx, client); err != nil || len(supported) != 0 {
+		t.Errorf("Expected the project to support no goals, saw %+v, %v", supported, err)
+	}
+
+	inQuarter, err := workspace.AllTimePeriodGoals(ctx, client, quarter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inQuarter) != 1 || inQuarter[0].ID != goal.ID {
+		t.Errorf("Expected one goal in the quarter, saw %+v", inQuarter)
+	}
+
+	user := server.AddUser("Alice", "alice@example.com")
+	if err := goal.AddFollowers(ctx, client, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(goal.Followers) != 2 {
+		t.Errorf("Expected two followers, saw %+v", goal.Followers)
+	}
+
+	if err := subgoal.Delete(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	all, err := workspace.AllGoals(ctx, client, &asana.Options{Fields: []string{"name", "status"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 1 || all[0].Status != asana.GoalAtRisk {
+		t.Errorf("Expected the remaining goal to be at risk, saw %+v", all)
+	}
+}
+
+func TestPortfolios(t *testing.T) {
+	ctx := context.Background()
+	server, client, workspace := newTestServer(t)
+	user := server.AddUser("Alice", "alice@example.com")
+
+	portfolio, err := client.CreatePortfolio(ctx, &asana.CreatePortfolioRequest{
+		PortfolioBase: asana.PortfolioBase{Name: "Reliability", Color: "dark-red"},
+		Workspace:     workspace.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var projects []*asana.Project
+	for _, name := range []string{"Alerting", "Runbooks", "Postmortems"} {
+		project := createProject(t, client, workspace, name)
+		if err := portfolio.AddItem(ctx, client, &asana.AddPortfolioItemRequest{Item: project.ID}); err != nil {
+			t.Fatal(err)
+		}
+		projects = append(projects, project)
+	}
+	if err := portfolio.AddItem(ctx, client, &asana.AddPortfolioItemRequest{Item: projects[2].ID, InsertBefore: projects[0].ID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := portfolio.RemoveItem(ctx, client, projects[1].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	it := portfolio.IterateItems(ctx, client, &asana.Options{Fields: []string{"name"}})
+	it.PageSize = 1
+	var names []string
+	for it.Next() {
+		names = append(names, it.Value().Name)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[0] != "Postmortems" || names[1] != "Alerting" {
+		t.Errorf("Unexpected portfolio items %v", names)
+	}
+
+	if err := portfolio.AddMembers(ctx, client, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(portfolio.Members) != 2 {
+		t.Errorf("Expected two members, saw %+v", portfolio.Members)
+	}
+
+	field, err := client.CreateCustomField(ctx, &asana.CreateCustomFieldRequest{
+		CustomFieldBase: asana.CustomFieldBase{Name: "Budget", ResourceSubtype: asana.Number},
+		Workspace:       workspace.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := portfolio.AddCustomFieldSetting(ctx, client, &asana.AddCustomFieldSettingRequest{CustomField: field.ID, Important: true}); err != nil {
+		t.Fatal(err)
+	}
+	date := asana.Date(time.Date(2021, 6, 30, 0, 0, 0, 0, time.UTC))
+	if err := portfolio.Update(ctx, client, &asana.UpdatePortfolioRequest{PortfolioBase: asana.PortfolioBase{DueOn: &date}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := portfolio.Fetch(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	if portfolio.Name != "Reliability" || portfolio.DueOn == nil || len(portfolio.CustomFieldSettings) != 1 || portfolio.Owner == nil {
+		t.Errorf("Unexpected portfolio %+v", portfolio)
+	}
+	if err := portfolio.RemoveCustomFieldSetting(ctx, client, field.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Options can list another user's portfolios
+	mine, err := workspace.AllPortfolios(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	theirs, _, err := workspace.Portfolios(ctx, client, &asana.Options{Owner: user.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mine) != 1 || len(theirs) != 0 {
+		t.Errorf("Expected one portfolio of mine and none of theirs, saw %d and %d", len(mine), len(theirs))
+	}
+
+	if err := portfolio.Delete(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	if mine, _, err = workspace.OwnedPortfolios(ctx, client, server.Me().ID); err != nil || len(mine) != 0 {
+		t.Errorf("Expected the portfolio to be deleted, saw %+v, %v", mine, err)
+	}
+}
+
+func TestMoveTask(t *testing.T) {
+	ctx := context.Background()
+	_, client, workspace := newTestServer(t)
+	project := createProject(t, client, workspace, "Tickets")
+
+	sections := map[string]*asana.Section{}
+	for _, name := range []string{"Triage", "Investigating", "Resolve"} {
+		section, err := project.CreateSection(ctx, client, &asana.SectionBase{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sections[name] = section
+	}
+	resolved := sections["Resolve"]
+	if err := resolved.Update(ctx, client, &asana.SectionBase{Name: "Resolved"}); err != nil {
+		t.Fatal(err)
+	}
+	if resolved.Name != "Resolved" {
+		t.Errorf("Expected the section to be renamed, saw %q", resolved.Name)
+	}
+	err := project.InsertSection(ctx, client, &asana.SectionInsertRequest{Section: resolved.ID, BeforeSection: sections["Triage"].ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tasks := map[string]*asana.Task{}
+	for _, name := range []string{"A", "B", "C", "D"} {
+		task, err := client.CreateTask(ctx, &asana.CreateTaskRequest{TaskBase: asana.TaskBase{Name: name}, Workspace: workspace.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tasks[name] = task
+	}
+
+	triage := sections["Triage"]
+	moves := []struct {
+		task     string
+		section  *asana.Section
+		position asana.TaskPosition
+	}{
+		{"A", triage, asana.SectionBottom},
+		{"B", triage, asana.SectionTop},
+		{"C", triage, asana.SectionBottom},
+		{"D", triage, asana.AfterTask(tasks["B"].ID)},
+		{"C", triage, asana.BeforeTask(tasks["B"].ID)},
+		{"A", sections["Investigating"], asana.SectionTop},
+	}
+	for _, move := range moves {
+		if err := project.MoveTask(ctx, client, tasks[move.task], move.section, move.position); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	inTriage, _, err := triage.Tasks(ctx, client, &asana.Options{Fields: []string{"name"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, task := range inTriage {
+		names = append(names, task.Name)
+	}
+	if len(names) != 3 || names[0] != "C" || names[1] != "B" || names[2] != "D" {
+		t.Errorf("Unexpected tasks in triage %v", names)
+	}
+
+	err = project.MoveTask(ctx, client, tasks["B"], triage, asana.AfterTask(tasks["A"].ID))
+	if err == nil {
+		t.Error("Expected an anchor in another section to be rejected")
+	}
+
+	listed, _, err := project.Sections(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 4 || listed[1].Name != "Resolved" {
+		t.Errorf("Expected Resolved to be moved before Triage, saw %+v", listed)
+	}
+}
+
+func TestUserTaskList(t *testing.T) {
+	ctx := context.Background()
+	server, client, workspace := newTestServer(t)
+
+	list, err := server.Me().GetTaskList(ctx, client, workspace.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	today := server.AddTaskListSection(server.Me().ID, workspace.ID, "Today")
+
+	tasks := map[string]*asana.Task{}
+	for _, name := range []string{"A", "B", "C", "D"} {
+		task, err := client.CreateTask(ctx, &asana.CreateTaskRequest{TaskBase: asana.TaskBase{Name: name}, Assignee: "me", Workspace: workspace.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tasks[name] = task
+	}
+
+	moves := []struct {
+		task     string
+		position asana.TaskPosition
+	}{
+		{"A", asana.SectionTop},
+		{"B", asana.AfterTask(tasks["A"].ID)},
+		{"C", asana.BeforeTask(tasks["A"].ID)},
+	}
+	for _, move := range moves {
+		before := server.RequestCount()
+		if err := list.MoveTask(ctx, client, tasks[move.task], today, move.position); err != nil {
+			t.Fatal(err)
+		}
+		if requests := server.RequestCount() - before; requests != 1 {
+			t.Errorf("Expected moving %s to take one request, saw %d", move.task, requests)
+		}
+	}
+
+	before := server.RequestCount()
+	if err := list.MoveTask(ctx, client, tasks["D"], today, asana.SectionBottom); err == nil || server.RequestCount() != before {
+		t.Error("Expected moving to the bottom of a section to be rejected without a request")
+	}
+
+	sections, err := list.Sections(ctx, client, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sections) != 2 || sections[0].Name != "Recently assigned" || sections[1].ID != today.ID {
+		t.Errorf("Unexpected sections %+v", sections)
+	}
+
+	names := func(tasks []*asana.Task) []string {
+		var result []string
+		for _, task := range tasks {
+			result = append(result, task.Name)
+		}
+		return result
+	}
+	inToday, _, err := today.Tasks(ctx, client, &asana.Options{Fields: []string{"name"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(inToday); strings.Join(got, ",") != "C,A,B" {
+		t.Errorf("Unexpected tasks in Today %v", got)
+	}
+
+	completed := true
+	if err := tasks["A"].Update(ctx, client, &asana.UpdateTaskRequest{TaskBase: asana.TaskBase{Completed: &completed}}); err != nil {
+		t.Fatal(err)
+	}
+	server.Now = func() time.Time { return time.Now().Add(time.Minute) }
+	open, err := list.AllTasks(ctx, client, "now", &asana.Options{Fields: []string{"name"}, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(open); strings.Join(got, ",") != "D,C,B" {
+		t.Errorf("Unexpected open tasks %v", got)
+	}
+
+	task := &asana.Task{ID: tasks["D"].ID}
+	if err := task.Fetch(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	if task.AssigneeSection == nil || task.AssigneeSection.Name != "Recently assigned" {
+		t.Errorf("Expected D to be recently assigned, saw %+v", task.AssigneeSection)
+	}
+	if err := task.Update(ctx, client, &asana.UpdateTaskRequest{AssigneeSection: today.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if task.AssigneeSection == nil || task.AssigneeSection.ID != today.ID {
+		t.Errorf("Expected D to be moved to Today, saw %+v", task.AssigneeSection)
+	}
+
+	other := server.AddUser("Other", "other@example.com")
+	err = tasks["B"].Update(ctx, client, &asana.UpdateTaskRequest{Assignee: other.ID, AssigneeSection: today.ID})
+	if err == nil {
+		t.Error("Expected a section in another user's My Tasks to be rejected")
+	}
+	if err := tasks["B"].Update(ctx, client, &asana.UpdateTaskRequest{Assignee: other.ID}); err != nil {
+		t.Fatal(err)
+	}
+	inToday, _, err = today.Tasks(ctx, client, &asana.Options{Fields: []string{"name"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(inToday); strings.Join(got, ",") != "D,C,A" {
+		t.Errorf("Expected B to leave Today when reassigned, saw %v", got)
+	}
+}
+
+func TestComments(t *testing.T) {
+	ctx := context.Background()
+	_, client, workspace := newTestServer(t)
+
+	task, err := client.CreateTask(ctx, &asana.CreateTaskRequest{TaskBase: asana.TaskBase{Name: "INC-42"}, Workspace: workspace.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := asanahtml.Body(
+		asanahtml.Strong(asanahtml.Text("Resolved <prod> & staging")),
+		asanahtml.UL(asanahtml.LI(asanahtml.Text("Rolled back"))),
+	)
+	story, err := task.CreateComment(ctx, client, &asana.StoryBase{HTMLText: body.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if story.Text != "Resolved <prod> & stagingRolled back" {
+		t.Errorf("Unexpected comment text %q", story.Text)
+	}
+
+	_, err = task.CreateComment(ctx, client, &asana.StoryBase{HTMLText: "<body><strong>Unclosed</body>"})
+	if e, ok := asana.IsAsanaError(err); !ok || e.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected malformed rich text to be rejected, saw %v", err)
+	}
+
+	if err := story.Like(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	if !story.Liked || story.NumLikes != 1 {
+		t.Errorf("Expected the comment to be liked once, saw %v and %d", story.Liked, story.NumLikes)
+	}
+	if err := story.Unlike(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	if story.Liked || story.NumLikes != 0 {
+		t.Errorf("Expected the like to be removed, saw %v and %d", story.Liked, story.NumLikes)
+	}
+
+	if err := story.Pin(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	if !story.IsPinned {
+		t.Error("Expected the comment to be pinned")
+	}
+	if err := story.Unpin(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	fetched := &asana.Story{ID: story.ID}
+	if err := fetched.Fetch(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	if fetched.IsPinned {
+		t.Error("Expected the comment to be unpinned")
+	}
+}
+
+func TestTaskHistory(t *testing.T) {
+	ctx := context.Background()
+	server, client, workspace := newTestServer(t)
+	project := createProject(t, client, workspace, "Incidents")
+
+	var mu sync.Mutex
+	start := time.Date(2021, 6, 1, 9, 0, 0, 0, time.UTC)
+	now := start
+	server.Now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+	advance := func(minutes int) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = at(minutes)
+	}
+
+	sections := map[string]*asana.Section{}
+	for _, name := range []string{"Triage", "Investigating", "Fixed"} {
+		section, err := project.CreateSection(ctx, client, &asana.SectionBase{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sections[name] = section
+	}
+	field, err := client.CreateCustomField(ctx, &asana.CreateCustomFieldRequest{
+		CustomFieldBase: asana.CustomFieldBase{Name: "Severity", ResourceSubtype: asana.Enum},
+		Workspace:       workspace.ID,
+		EnumOptions:     []*asana.EnumValueBase{{Name: "Major"}, {Name: "Minor"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := project.AddCustomFieldSetting(ctx, client, &asana.AddCustomFieldSettingRequest{CustomField: field.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	task, err := client.CreateTask(ctx, &asana.CreateTaskRequest{
+		TaskBase:  asana.TaskBase{Name: "Database down"},
+		Workspace: workspace.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	completed := true
+	steps := []struct {
+		minutes int
+		change  func() error
+	}{
+		{1, func() error {
+			return task.AddProject(ctx, client, &asana.AddProjectRequest{Project: project.ID, Section: sections["Triage"].ID})
+		}},
+		{5, func() error { return task.Update(ctx, client, &asana.UpdateTaskRequest{Assignee: server.Me().ID}) }},
+		{10, func() error {
+			return task.AddProject(ctx, client, &asana.AddProjectRequest{Project: project.ID, Section: sections["Investigating"].ID})
+		}},
+		{15, func() error {
+			return task.Update(ctx, client, &asana.UpdateTaskRequest{CustomFields: map[string]interface{}{field.ID: field.EnumOptions[0].ID}})
+		}},
+		{40, func() error {
+			return task.Update(ctx, client, &asana.UpdateTaskRequest{TaskBase: asana.TaskBase{Name: "Database down in eu-west"}})
+		}},
+		{45, func() error {
+			return task.AddProject(ctx, client, &asana.AddProjectRequest{Project: project.ID, Section: sections["Fixed"].ID})
+		}},
+		{60, func() error {
+			return task.Update(ctx, client, &asana.UpdateTaskRequest{TaskBase: asana.TaskBase{Completed: &completed}})
+		}},
+	}
+	for _, step := range steps {
+		advance(step.minutes)
+		if err := step.change(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	history, err := task.History(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if assignee := history.AssigneeAt(at(4)); assignee != nil {
+		t.Errorf("Expected the task to be unassigned at first, saw %+v", assignee)
+	}
+	if assignee := history.AssigneeAt(at(5)); assignee == nil || assignee.ID != server.Me().ID {
+		t.Errorf("Expected the task to be assigned after 5 minutes, saw %+v", assignee)
+	}
+	acknowledged := history.Assignee.Find(func(value interface{}) bool { return value != nil })
+	if acknowledged == nil || acknowledged.At.Sub(history.Created) != 5*time.Minute {
+		t.Errorf("Expected the task to be acknowledged after 5 minutes, saw %+v", acknowledged)
+	}
+
+	if section := history.SectionAt(project.ID, at(0)); section != nil {
+		t.Errorf("Expected the task not to be in the project at first, saw %+v", section)
+	}
+	if section := history.SectionAt(project.ID, at(2)); section == nil || section.ID != sections["Triage"].ID {
+		t.Errorf("Expected the task to be added to Triage, saw %+v", section)
+	}
+	durations := history.TimeInSections(project.ID, *task.CompletedAt)
+	expected := map[string]time.Duration{
+		sections["Triage"].ID:        9 * time.Minute,
+		sections["Investigating"].ID: 35 * time.Minute,
+		sections["Fixed"].ID:         15 * time.Minute,
+	}
+	if !reflect.DeepEqual(durations, expected) {
+		t.Errorf("Unexpected time in sections %v", durations)
+	}
+
+	if name := history.NameAt(at(30)); name != "Database down" {
+		t.Errorf("Unexpected name before the rename %q", name)
+	}
+	if name := history.NameAt(at(50)); name != "Database down in eu-west" {
+		t.Errorf("Unexpected name after the rename %q", name)
+	}
+	if value := history.CustomFieldAt(field.ID, at(14)); value == nil || value.EnumValue != nil {
+		t.Errorf("Expected no severity at first, saw %+v", value)
+	}
+	if value := history.CustomFieldAt(field.ID, at(15)); value == nil || value.EnumValue == nil || value.EnumValue.Name != "Major" {
+		t.Errorf("Expected the severity to be set, saw %+v", value)
+	}
+	if history.IsCompleteAt(at(59)) || !history.IsCompleteAt(at(60)) {
+		t.Error("Expected the task to be completed after an hour")
+	}
+	if dates := history.DatesAt(at(30)); dates != nil {
+		t.Errorf("Expected no dates, saw %+v", dates)
+	}
+
+	// A task created with an assignee has them from the start
+	ada := server.AddUser("Ada Lovelace", "ada@example.com")
+	advance(70)
+	handover, err := client.CreateTask(ctx, &asana.CreateTaskRequest{
+		TaskBase:  asana.TaskBase{Name: "Rotate credentials"},
+		Assignee:  ada.ID,
+		Workspace: workspace.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	advance(75)
+	if err := handover.Update(ctx, client, &asana.UpdateTaskRequest{Assignee: server.Me().ID}); err != nil {
+		t.Fatal(err)
+	}
+	history, err = handover.History(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assignee := history.AssigneeAt(at(70)); assignee == nil || assignee.ID != ada.ID {
+		t.Errorf("Expected the task to be assigned to Ada when created, saw %+v", assignee)
+	}
+	if assignee := history.AssigneeAt(at(75)); assignee == nil || assignee.ID != server.Me().ID {
+		t.Errorf("Expected the task to be reassigned, saw %+v", assignee)
+	}
+}
@@ -0,0 +1,62 @@
+package asanatest
+
+import (
+	"net/http"
+)
+
+// Status updates
+
+var statusTypes = fieldSet("on_track", "at_risk", "off_track", "on_hold", "complete")
+
+// Resource types which may have status updates
+var statusUpdateParents = fieldSet("project", "portfolio", "goal")
+
+func (s *Server) listStatusUpdates(r *request) (*response, error) {
+	parent, err := required(r, "parent")
+	if err != nil {
+		return nil, err
+	}
+
+	// Newest first
+	updates := s.list("status_update", func(o *object) bool {
+		return o.refs["parent"] == parent
+	})
+	for i, j := 0, len(updates)-1; i < j; i, j = i+1, j-1 {
+		updates[i], updates[j] = updates[j], updates[i]
+	}
+	return &response{list: updates}, nil
+}
+
+func (s *Server) createStatusUpdate(r *request) (*response, error) {
+	parentID, err := required(r, "parent")
+	if err != nil {
+		return nil, err
+	}
+	statusType, err := required(r, "status_type")
+	if err != nil {
+		return nil, err
+	}
+	parent, err := s.get("", parentID)
+	if err != nil || !statusUpdateParents[parent.resourceType] {
+		return nil, errorf(http.StatusBadRequest, "parent: Not a project, portfolio or goal: %s", parentID)
+	}
+	if !statusTypes[statusType] {
+		return nil, errorf(http.StatusBadRequest, "status_type: Not a valid status type: %s", statusType)
+	}
+	text, html := toString(r.data["text"]), toString(r.data["html_text"])
+	if text == "" && html == "" {
+		return nil, errorf(http.StatusBadRequest, "text: Missing input")
+	}
+
+	update := s.create("status_update", map[string]interface{}{
+		"title":            toString(r.data["title"]),
+		"text":             text,
+		"html_text":        html,
+		"status_type":      statusType,
+		"resource_subtype": parent.resourceType + "_status_update",
+		"parent":           parent.gid,
+		"author":           s.me,
+		"created_by":       s.me,
+	})
+	return &response{status: http.StatusCreated, object: update}, nil
+}
@@ -0,0 +1,622 @@
+package asanatest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// object is a single resource held by the server. Scalar fields are kept as
+// decoded JSON, while references to other resources are kept as IDs so that
+// they are always rendered with the current state of the referenced object.
+type object struct {
+	gid          string
+	resourceType string
+	fields       map[string]interface{}
+	refs         map[string]string
+	lists        map[string][]string
+
+	// Ordered children which are not rendered as part of the object, such as
+	// the sections and tasks of a project
+	ordered map[string][]string
+
+	// Tasks only: the section the task is in for each of its projects, and
+	// the values of its custom fields keyed by custom field ID
+	sections     map[string]string
+	customFields map[string]interface{}
+
+	// Jobs only: the number of fetches left before the job succeeds
+	polls int
+}
+
+// Fields which hold a reference to a single object
+var refFields = map[string]bool{
+	"assignee":            true,
+	"author":              true,
+	"created_by":          true,
+	"custom_field":        true,
+	"new_project":         true,
+	"new_task":            true,
+	"organization":        true,
+	"owner":               true,
+	"parent":              true,
+	"project":             true,
+	"resource":            true,
+	"supported_goal":      true,
+	"supporting_resource": true,
+	"tag":                 true,
+	"target":              true,
+	"team":                true,
+	"time_period":         true,
+	"workspace":           true,
+	"user":                true,
+	"assignee_user":       true,
+}
+
+// Fields which hold a list of references
+var listFields = map[string]bool{
+	"dependencies": true,
+	"dependents":   true,
+	"enum_options": true,
+	"followers":    true,
+	"members":      true,
+	"projects":     true,
+	"tags":         true,
+}
+
+// Lists which are rendered in full rather than in compact form
+var fullListFields = map[string]bool{
+	"enum_options": true,
+}
+
+// Resource types which carry created_at and modified_at timestamps
+var timestamped = map[string]bool{
+	"custom_field":  true,
+	"project":       true,
+	"section":       true,
+	"status_update": true,
+	"story":         true,
+	"tag":           true,
+	"task":          true,
+	"webhook":       true,
+}
+
+func (s *Server) now() string {
+	return s.Now().UTC().Format(time.RFC3339Nano)
+}
+
+// create adds a new object with the given fields. References must already
+// have been validated.
+func (s *Server) create(resourceType string, fields map[string]interface{}) *object {
+	s.nextID++
+	o := &object{
+		gid:          strconv.Itoa(s.nextID),
+		resourceType: resourceType,
+		fields:       map[string]interface{}{},
+		refs:         map[string]string{},
+		lists:        map[string][]string{},
+		ordered:      map[string][]string{},
+		sections:     map[string]string{},
+		customFields: map[string]interface{}{},
+	}
+	if timestamped[resourceType] {
+		o.fields["created_at"] = s.now()
+		o.fields["modified_at"] = s.now()
+	}
+
+	for key, value := range fields {
+		switch v := value.(type) {
+		case string:
+			if refFields[key] {
+				o.refs[key] = v
+				continue
+			}
+		case []string:
+			o.lists[key] = v
+			continue
+		}
+		o.fields[key] = value
+	}
+
+	s.objects[o.gid] = o
+	s.order = append(s.order, o.gid)
+	return o
+}
+
+// get returns an object of the given type
+func (s *Server) get(resourceType, gid string) (*object, error) {
+	o, ok := s.objects[gid]
+	if !ok || (resourceType != "" && o.resourceType != resourceType) {
+		return nil, notFound(gid)
+	}
+	return o, nil
+}
+
+// delete removes an object. References to it are dropped when rendering.
+func (s *Server) delete(gid string) {
+	delete(s.objects, gid)
+}
+
+// list returns the objects of the given type which match the predicate, in
+// the order they were created
+func (s *Server) list(resourceType string, match func(o *object) bool) []*object {
+	result := []*object{}
+	for _, gid := range s.order {
+		o, ok := s.objects[gid]
+		if !ok || o.resourceType != resourceType {
+			continue
+		}
+		if match == nil || match(o) {
+			result = append(result, o)
+		}
+	}
+	return result
+}
+
+// resolve returns the objects for a list of IDs, skipping deleted objects
+func (s *Server) resolve(gids []string) []*object {
+	result := []*object{}
+	for _, gid := range gids {
+		if o, ok := s.objects[gid]; ok {
+			result = append(result, o)
+		}
+	}
+	return result
+}
+
+// apply updates an object from request data, validating references
+func (s *Server) apply(o *object, data map[string]interface{}, writable map[string]bool) error {
+	for key, value := range data {
+		if !writable[key] {
+			continue
+		}
+
+		switch {
+		case refFields[key]:
+			if value == nil {
+				delete(o.refs, key)
+				continue
+			}
+			gid, err := s.reference(key, value)
+			if err != nil {
+				return err
+			}
+			o.refs[key] = gid
+		case listFields[key]:
+			gids, err := s.references(key, value)
+			if err != nil {
+				return err
+			}
+			o.lists[key] = gids
+		default:
+			o.fields[key] = value
+		}
+	}
+
+	if timestamped[o.resourceType] {
+		o.fields["modified_at"] = s.now()
+	}
+	return nil
+}
+
+// reference validates a reference to an object given in request data
+func (s *Server) reference(key string, value interface{}) (string, error) {
+	gid, ok := value.(string)
+	if !ok {
+		return "", errorf(http.StatusBadRequest, "%s: Not a valid reference", key)
+	}
+	if gid == "me" {
+		gid = s.me
+	}
+	if _, ok := s.objects[gid]; !ok {
+		return "", errorf(http.StatusBadRequest, "%s: Not a recognized ID: %s", key, gid)
+	}
+	return gid, nil
+}
+
+func (s *Server) references(key string, value interface{}) ([]string, error) {
+	values, ok := value.([]interface{})
+	if !ok {
+		return nil, errorf(http.StatusBadRequest, "%s: Not an array", key)
+	}
+
+	result := []string{}
+	for _, v := range values {
+		gid, err := s.reference(key, v)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, gid)
+	}
+	return result, nil
+}
+
+// compact renders the compact representation of an object
+func (s *Server) compact(o *object) map[string]interface{} {
+	result := map[string]interface{}{
+		"gid":           o.gid,
+		"resource_type": o.resourceType,
+	}
+	for _, key := range []string{"name", "resource_subtype"} {
+		if v, ok := o.fields[key]; ok {
+			result[key] = v
+		}
+	}
+	return result
+}
+
+// full renders every field of an object
+func (s *Server) full(o *object) map[string]interface{} {
+	result := map[string]interface{}{
+		"gid":           o.gid,
+		"resource_type": o.resourceType,
+	}
+	for key, value := range o.fields {
+		result[key] = value
+	}
+	for key, gid := range o.refs {
+		result[key] = nil
+		if ref, ok := s.objects[gid]; ok {
+			result[key] = s.compact(ref)
+		}
+	}
+	for key, gids := range o.lists {
+		list := []interface{}{}
+		for _, ref := range s.resolve(gids) {
+			if fullListFields[key] {
+				list = append(list, s.full(ref))
+			} else {
+				list = append(list, s.compact(ref))
+			}
+		}
+		result[key] = list
+	}
+	for key, value := range s.computed(o) {
+		result[key] = value
+	}
+	return result
+}
+
+// view renders an object, either in full, compact or restricted to the
+// requested fields. Fields may be paths such as "assignee.name".
+func (s *Server) view(o *object, fields []string, compact bool) map[string]interface{} {
+	if len(fields) == 0 {
+		if compact {
+			return s.compact(o)
+		}
+		return s.full(o)
+	}
+
+	result := map[string]interface{}{
+		"gid":           o.gid,
+		"resource_type": o.resourceType,
+	}
+
+	var computed map[string]interface{}
+	for key, sub := range groupFields(fields) {
+		switch {
+		case key == "gid" || key == "resource_type":
+			// Always included
+		case refFields[key] && o.refs[key] != "":
+			result[key] = nil
+			if ref, ok := s.objects[o.refs[key]]; ok {
+				result[key] = s.view(ref, sub, true)
+			}
+		case listFields[key] && o.lists[key] != nil:
+			list := []interface{}{}
+			for _, ref := range s.resolve(o.lists[key]) {
+				list = append(list, s.view(ref, sub, !fullListFields[key]))
+			}
+			result[key] = list
+		default:
+			if value, ok := o.fields[key]; ok {
+				result[key] = filterValue(value, sub)
+				continue
+			}
+			if computed == nil {
+				computed = s.computed(o)
+			}
+			result[key] = filterValue(computed[key], sub)
+		}
+	}
+	return result
+}
+
+// groupFields splits field paths by their first component
+func groupFields(fields []string) map[string][]string {
+	result := map[string][]string{}
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		parts := strings.SplitN(field, ".", 2)
+		if _, ok := result[parts[0]]; !ok {
+			result[parts[0]] = nil
+		}
+		if len(parts) > 1 {
+			result[parts[0]] = append(result[parts[0]], parts[1])
+		}
+	}
+	return result
+}
+
+// filterValue restricts rendered maps and lists of maps to the given fields
+func filterValue(value interface{}, fields []string) interface{} {
+	if len(fields) == 0 {
+		return value
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := map[string]interface{}{}
+		if gid, ok := v["gid"]; ok {
+			result["gid"] = gid
+		}
+		for key, sub := range groupFields(fields) {
+			result[key] = filterValue(v[key], sub)
+		}
+		return result
+	case []interface{}:
+		result := []interface{}{}
+		for _, item := range v {
+			result = append(result, filterValue(item, fields))
+		}
+		return result
+	}
+	return value
+}
+
+// computed renders fields which are derived from other objects
+func (s *Server) computed(o *object) map[string]interface{} {
+	switch o.resourceType {
+	case "task":
+		return map[string]interface{}{
+			"memberships":      s.memberships(o),
+			"assignee_section": s.assigneeSection(o),
+			"custom_fields":    s.taskCustomFields(o),
+			"num_subtasks":     len(s.subtasks(o)),
+			"permalink_url":    fmt.Sprintf("https://app.asana.com/0/0/%s", o.gid),
+		}
+	case "project", "portfolio":
+		settings := []interface{}{}
+		for _, setting := range s.customFieldSettings(o) {
+			settings = append(settings, s.full(setting))
+		}
+		return map[string]interface{}{
+			"custom_field_settings": settings,
+		}
+	case "custom_field_setting":
+		result := map[string]interface{}{}
+		if field, ok := s.objects[o.refs["custom_field"]]; ok {
+			result["custom_field"] = s.full(field)
+		}
+		return result
+	case "user":
+		workspaces := []interface{}{}
+		for _, w := range s.list("workspace", nil) {
+			workspaces = append(workspaces, s.compact(w))
+		}
+		return map[string]interface{}{
+			"workspaces": workspaces,
+		}
+	}
+	return nil
+}
+
+func (s *Server) memberships(task *object) []interface{} {
+	result := []interface{}{}
+	for _, project := range s.resolve(task.lists["projects"]) {
+		membership := map[string]interface{}{
+			"project": s.compact(project),
+			"section": nil,
+		}
+		if section, ok := s.objects[task.sections[project.gid]]; ok {
+			membership["section"] = s.compact(section)
+		}
+		result = append(result, membership)
+	}
+	return result
+}
+
+func (s *Server) subtasks(task *object) []*object {
+	return s.resolve(task.ordered["subtasks"])
+}
+
+// customFieldSettings returns the settings of a project or portfolio in
+// order, skipping settings for deleted custom fields
+func (s *Server) customFieldSettings(parent *object) []*object {
+	result := []*object{}
+	for _, setting := range s.resolve(parent.ordered["custom_field_settings"]) {
+		if _, ok := s.objects[setting.refs["custom_field"]]; ok {
+			result = append(result, setting)
+		}
+	}
+	return result
+}
+
+// taskCustomFields renders the custom field values of a task, including
+// empty values for fields attached to its projects
+func (s *Server) taskCustomFields(task *object) []interface{} {
+	var fieldIDs []string
+	seen := map[string]bool{}
+	for _, project := range s.resolve(task.lists["projects"]) {
+		for _, setting := range s.customFieldSettings(project) {
+			if gid := setting.refs["custom_field"]; !seen[gid] {
+				seen[gid] = true
+				fieldIDs = append(fieldIDs, gid)
+			}
+		}
+	}
+	for gid := range task.customFields {
+		if !seen[gid] {
+			seen[gid] = true
+			fieldIDs = append(fieldIDs, gid)
+		}
+	}
+
+	result := []interface{}{}
+	for _, field := range s.resolve(fieldIDs) {
+		result = append(result, s.customFieldValue(field, task.customFields[field.gid]))
+	}
+	return result
+}
+
+// customFieldValue renders a custom field along with its value on a task
+func (s *Server) customFieldValue(field *object, value interface{}) map[string]interface{} {
+	result := s.full(field)
+	subtype := toString(field.fields["resource_subtype"])
+	key, rendered, display := s.renderValue(subtype, value)
+
+	result["type"] = subtype
+	result["display_value"] = display
+	if key != "" {
+		result[key] = rendered
+	}
+	return result
+}
+
+// renderValue renders a custom field value, returning the field it is
+// rendered as, such as "enum_value", and its display value
+func (s *Server) renderValue(subtype string, value interface{}) (string, interface{}, interface{}) {
+	switch subtype {
+	case "text":
+		return "text_value", value, value
+	case "number":
+		if value == nil {
+			return "number_value", nil, nil
+		}
+		return "number_value", value, fmt.Sprint(value)
+	case "enum":
+		option, ok := s.objects[toString(value)]
+		if !ok {
+			return "enum_value", nil, nil
+		}
+		return "enum_value", s.full(option), option.fields["name"]
+	case "multi_enum":
+		values := []interface{}{}
+		var names []string
+		for _, option := range s.resolve(toStrings(value)) {
+			values = append(values, s.full(option))
+			names = append(names, fmt.Sprint(option.fields["name"]))
+		}
+		if len(names) == 0 {
+			return "multi_enum_values", values, nil
+		}
+		return "multi_enum_values", values, strings.Join(names, ", ")
+	case "date":
+		date, _ := value.(map[string]interface{})
+		if date == nil {
+			return "date_value", nil, nil
+		}
+		display := date["date_time"]
+		if display == nil {
+			display = date["date"]
+		}
+		return "date_value", date, display
+	case "people":
+		values := []interface{}{}
+		var names []string
+		for _, user := range s.resolve(toStrings(value)) {
+			values = append(values, s.compact(user))
+			names = append(names, fmt.Sprint(user.fields["name"]))
+		}
+		if len(names) == 0 {
+			return "people_value", values, nil
+		}
+		return "people_value", values, strings.Join(names, ", ")
+	}
+	return "", nil, nil
+}
+
+func toString(value interface{}) string {
+	s, _ := value.(string)
+	return s
+}
+
+func toStrings(value interface{}) []string {
+	var result []string
+	switch v := value.(type) {
+	case []string:
+		result = v
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+	}
+	return result
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func toBool(value interface{}) bool {
+	b, _ := value.(bool)
+	return b
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// insert adds an ID to an ordered list, before or after another ID if
+// given, or at the end. The ID is moved if it is already in the list.
+func insert(list []string, gid, before, after string) ([]string, error) {
+	list = remove(list, gid)
+
+	position := len(list)
+	if anchor := before + after; anchor != "" {
+		position = -1
+		for i, id := range list {
+			if id == anchor {
+				position = i
+				if after != "" {
+					position++
+				}
+			}
+		}
+		if position < 0 {
+			return nil, errorf(http.StatusBadRequest, "%s is not in the list", anchor)
+		}
+	}
+
+	list = append(list, "")
+	copy(list[position+1:], list[position:])
+	list[position] = gid
+	return list, nil
+}
+
+// remove deletes an ID from a list
+func remove(list []string, gid string) []string {
+	result := []string{}
+	for _, id := range list {
+		if id != gid {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+func contains(list []string, gid string) bool {
+	for _, id := range list {
+		if id == gid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,1020 @@
+package asanatest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Fields which may be set when creating or updating a task. Projects, tags
+// and custom fields are handled separately.
+var taskFields = fieldSet("name", "notes", "html_notes", "resource_subtype", "assignee",
+	"assignee_status", "completed", "due_on", "due_at", "start_on", "external",
+	"is_rendered_as_separator", "followers")
+
+// Tasks
+
+func (s *Server) queryTasks(r *request) (*response, error) {
+	q := r.query
+
+	var tasks []*object
+	switch {
+	case q.Get("project") != "":
+		project, err := s.get("project", q.Get("project"))
+		if err != nil {
+			return nil, err
+		}
+		tasks = s.projectTasks(project)
+	case q.Get("section") != "":
+		section, err := s.get("section", q.Get("section"))
+		if err != nil {
+			return nil, err
+		}
+		tasks = s.sectionTasks(section)
+	case q.Get("tag") != "":
+		tag, err := s.get("tag", q.Get("tag"))
+		if err != nil {
+			return nil, err
+		}
+		tasks = s.tagTasks(tag)
+	case q.Get("assignee") != "" && q.Get("workspace") != "":
+		assignee := q.Get("assignee")
+		if assignee == "me" {
+			assignee = s.me
+		}
+		tasks = s.list("task", func(o *object) bool {
+			return o.refs["assignee"] == assignee && o.refs["workspace"] == q.Get("workspace")
+		})
+	default:
+		return nil, errorf(http.StatusBadRequest, "Must specify exactly one of project, tag, section, user task list, or assignee + workspace")
+	}
+
+	return s.tasks(q, tasks)
+}
+
+func (s *Server) listProjectTasks(r *request) (*response, error) {
+	project, err := s.get("project", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	return s.tasks(r.query, s.projectTasks(project))
+}
+
+func (s *Server) listSectionTasks(r *request) (*response, error) {
+	section, err := s.get("section", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	return s.tasks(r.query, s.sectionTasks(section))
+}
+
+func (s *Server) listTagTasks(r *request) (*response, error) {
+	tag, err := s.get("tag", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	return s.tasks(r.query, s.tagTasks(tag))
+}
+
+func (s *Server) listSubtasks(r *request) (*response, error) {
+	task, err := s.get("task", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	return s.tasks(r.query, s.subtasks(task))
+}
+
+// tasks applies the completed_since and modified_since filters to a list of
+// tasks
+func (s *Server) tasks(query url.Values, tasks []*object) (*response, error) {
+	completedSince, err := s.parseSince(query, "completed_since")
+	if err != nil {
+		return nil, err
+	}
+	modifiedSince, err := s.parseSince(query, "modified_since")
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*object{}
+	for _, task := range tasks {
+		if completedSince != nil && toBool(task.fields["completed"]) {
+			completedAt, _ := time.Parse(time.RFC3339Nano, toString(task.fields["completed_at"]))
+			if completedAt.Before(*completedSince) {
+				continue
+			}
+		}
+		if modifiedSince != nil {
+			modifiedAt, _ := time.Parse(time.RFC3339Nano, toString(task.fields["modified_at"]))
+			if modifiedAt.Before(*modifiedSince) {
+				continue
+			}
+		}
+		result = append(result, task)
+	}
+	return &response{list: result}, nil
+}
+
+func (s *Server) parseSince(query url.Values, key string) (*time.Time, error) {
+	value := query.Get(key)
+	if value == "" {
+		return nil, nil
+	}
+	if value == "now" {
+		t := s.Now()
+		return &t, nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, errorf(http.StatusBadRequest, "%s: Not a valid date: %s", key, value)
+}
+
+// projectTasks returns the tasks in a project, ordered by section
+func (s *Server) projectTasks(project *object) []*object {
+	result := []*object{}
+	for _, section := range s.resolve(project.ordered["sections"]) {
+		result = append(result, s.sectionTasks(section)...)
+	}
+	return result
+}
+
+func (s *Server) tagTasks(tag *object) []*object {
+	return s.list("task", func(o *object) bool {
+		return contains(o.lists["tags"], tag.gid)
+	})
+}
+
+func (s *Server) createTask(r *request) (*response, error) {
+	var workspace string
+	if parent, ok := r.data["parent"].(string); ok {
+		p, err := s.get("task", parent)
+		if err != nil {
+			return nil, errorf(http.StatusBadRequest, "parent: Not a recognized ID: %s", parent)
+		}
+		workspace = p.refs["workspace"]
+	}
+	for _, gid := range append(toStrings(r.data["projects"]), membershipProjects(r.data)...) {
+		if project, ok := s.objects[gid]; ok && project.resourceType == "project" {
+			workspace = project.refs["workspace"]
+		}
+	}
+	if w := toString(r.data["workspace"]); w != "" {
+		workspace = w
+	}
+	if workspace == "" {
+		return nil, errorf(http.StatusBadRequest, "workspace: Missing input")
+	}
+	if _, err := s.reference("workspace", workspace); err != nil {
+		return nil, err
+	}
+
+	task := s.create("task", map[string]interface{}{
+		"workspace":        workspace,
+		"created_by":       s.me,
+		"name":             "",
+		"notes":            "",
+		"completed":        false,
+		"completed_at":     nil,
+		"resource_subtype": "default_task",
+		"followers":        []string{s.me},
+	})
+	if err := s.initTask(task, r.data); err != nil {
+		s.delete(task.gid)
+		return nil, err
+	}
+
+	// Asana records the assignee a task was created with
+	if assignee := task.refs["assignee"]; assignee != "" {
+		s.addStory(task, "assigned", "assigned to "+name(s.objects[assignee]), map[string]interface{}{
+			"assignee": assignee,
+		})
+	}
+	return &response{status: http.StatusCreated, object: task}, nil
+}
+
+// initTask applies the data of a create request to a new task
+func (s *Server) initTask(task *object, data map[string]interface{}) error {
+	if err := s.apply(task, data, taskFields); err != nil {
+		return err
+	}
+	if toBool(task.fields["completed"]) {
+		task.fields["completed_at"] = s.now()
+	}
+
+	if parent := toString(data["parent"]); parent != "" {
+		p, err := s.get("task", parent)
+		if err != nil {
+			return errorf(http.StatusBadRequest, "parent: Not a recognized ID: %s", parent)
+		}
+		task.refs["parent"] = p.gid
+		p.ordered["subtasks"] = append(p.ordered["subtasks"], task.gid)
+	}
+
+	for _, project := range toStrings(data["projects"]) {
+		if err := s.addToProject(task, project, "", nil, false); err != nil {
+			return err
+		}
+	}
+	if memberships, ok := data["memberships"].([]interface{}); ok {
+		for _, m := range memberships {
+			membership, _ := m.(map[string]interface{})
+			if err := s.addToProject(task, toString(membership["project"]), toString(membership["section"]), nil, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, tag := range toStrings(data["tags"]) {
+		if _, err := s.get("tag", tag); err != nil {
+			return errorf(http.StatusBadRequest, "tags: Not a recognized ID: %s", tag)
+		}
+		task.lists["tags"] = append(task.lists["tags"], tag)
+	}
+	if _, ok := task.lists["tags"]; !ok {
+		task.lists["tags"] = []string{}
+	}
+
+	if values, ok := data["custom_fields"]; ok {
+		if _, err := s.setCustomFields(task, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func membershipProjects(data map[string]interface{}) []string {
+	var result []string
+	memberships, _ := data["memberships"].([]interface{})
+	for _, m := range memberships {
+		membership, _ := m.(map[string]interface{})
+		result = append(result, toString(membership["project"]))
+	}
+	return result
+}
+
+func (s *Server) createSubtask(r *request) (*response, error) {
+	parent, err := s.get("task", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+
+	task := s.create("task", map[string]interface{}{
+		"workspace":        parent.refs["workspace"],
+		"created_by":       s.me,
+		"name":             "",
+		"notes":            "",
+		"completed":        false,
+		"completed_at":     nil,
+		"resource_subtype": "default_task",
+		"followers":        []string{s.me},
+	})
+	r.data["parent"] = parent.gid
+	if err := s.initTask(task, r.data); err != nil {
+		s.delete(task.gid)
+		parent.ordered["subtasks"] = remove(parent.ordered["subtasks"], task.gid)
+		return nil, err
+	}
+	return &response{status: http.StatusCreated, object: task}, nil
+}
+
+func (s *Server) updateTask(r *request) (*response, error) {
+	task, err := s.get("task", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+
+	before := map[string]interface{}{
+		"assignee":  task.refs["assignee"],
+		"name":      task.fields["name"],
+		"completed": toBool(task.fields["completed"]),
+		"dates":     taskDates(task),
+	}
+
+	// Validate custom fields and the My Tasks section before changing
+	// anything else
+	var changes []customFieldChange
+	if values, ok := r.data["custom_fields"]; ok {
+		changes, err = s.checkCustomFields(task, values)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var assigneeSection *object
+	if value, ok := r.data["assignee_section"]; ok && value != nil {
+		if assigneeSection, err = s.checkAssigneeSection(task, r.data, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.apply(task, r.data, taskFields); err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		s.setCustomField(task, change)
+	}
+	if assigneeSection != nil {
+		list := s.objects[assigneeSection.refs["project"]]
+		s.taskListTasks(list)
+		if err := s.place(list, task, assigneeSection.gid, map[string]interface{}{"insert_after": nil}); err != nil {
+			return nil, err
+		}
+	}
+
+	// Record system stories for the changes
+	if assignee := task.refs["assignee"]; assignee != before["assignee"] {
+		if assignee == "" {
+			previous := s.objects[before["assignee"].(string)]
+			s.addStory(task, "unassigned", "unassigned from "+name(previous), nil)
+		} else {
+			s.addStory(task, "assigned", "assigned to "+name(s.objects[assignee]), map[string]interface{}{
+				"assignee": assignee,
+			})
+		}
+	}
+	if task.fields["name"] != before["name"] {
+		s.addStory(task, "name_changed", fmt.Sprintf("changed the name to %q", task.fields["name"]), map[string]interface{}{
+			"old_name": before["name"],
+			"new_name": task.fields["name"],
+		})
+	}
+	if completed := toBool(task.fields["completed"]); completed != before["completed"] {
+		if completed {
+			task.fields["completed_at"] = s.now()
+			s.addStory(task, "marked_complete", "marked this task complete", nil)
+		} else {
+			task.fields["completed_at"] = nil
+			s.addStory(task, "marked_incomplete", "marked incomplete", nil)
+		}
+	}
+	if dates := taskDates(task); fmt.Sprint(dates) != fmt.Sprint(before["dates"]) {
+		text := "removed the due date"
+		if due := dates["due_at"]; due != nil {
+			text = fmt.Sprintf("changed the due date to %s", due)
+		} else if due := dates["due_on"]; due != nil {
+			text = fmt.Sprintf("changed the due date to %s", due)
+		}
+		s.addStory(task, "due_date_changed", text, map[string]interface{}{
+			"old_dates": before["dates"],
+			"new_dates": dates,
+		})
+	}
+	for _, change := range changes {
+		s.addCustomFieldStory(task, change)
+	}
+
+	return &response{object: task}, nil
+}
+
+// checkAssigneeSection validates a section of My Tasks to move a task to. It
+// must belong to whoever the task will be assigned to after the update.
+func (s *Server) checkAssigneeSection(task *object, data map[string]interface{}, value interface{}) (*object, error) {
+	section, err := s.get("section", toString(value))
+	if err != nil {
+		return nil, errorf(http.StatusBadRequest, "assignee_section: Not a recognized ID: %v", value)
+	}
+	assignee := task.refs["assignee"]
+	if value, ok := data["assignee"]; ok {
+		assignee, _ = s.reference("assignee", value)
+	}
+	list, ok := s.objects[section.refs["project"]]
+	if !ok || list.resourceType != "user_task_list" || list.refs["owner"] != assignee || list.refs["workspace"] != task.refs["workspace"] {
+		return nil, errorf(http.StatusBadRequest, "assignee_section: Not a section in the assignee's My Tasks: %s", section.gid)
+	}
+	return section, nil
+}
+
+func taskDates(task *object) map[string]interface{} {
+	return map[string]interface{}{
+		"start_on": task.fields["start_on"],
+		"due_on":   task.fields["due_on"],
+		"due_at":   task.fields["due_at"],
+	}
+}
+
+func name(o *object) string {
+	if o == nil {
+		return "unknown"
+	}
+	return toString(o.fields["name"])
+}
+
+// addToProject adds a task to a project, or moves it within the project if
+// it is already there. position holds the insert_before or insert_after
+// parameters of the request, if any.
+func (s *Server) addToProject(task *object, projectID, sectionID string, position map[string]interface{}, stories bool) error {
+	project, err := s.get("project", projectID)
+	if err != nil {
+		return errorf(http.StatusBadRequest, "project: Not a recognized ID: %s", projectID)
+	}
+	if project.refs["workspace"] != task.refs["workspace"] {
+		return errorf(http.StatusBadRequest, "project: Not in the same workspace as the task")
+	}
+
+	previous, member := task.sections[project.gid]
+	if err := s.place(project, task, sectionID, position); err != nil {
+		return err
+	}
+	sectionID = task.sections[project.gid]
+	if !contains(task.lists["projects"], project.gid) {
+		task.lists["projects"] = append(task.lists["projects"], project.gid)
+	}
+	task.fields["modified_at"] = s.now()
+
+	if !stories {
+		return nil
+	}
+	switch {
+	case !member:
+		s.addStory(task, "added_to_project", "added to "+name(project), map[string]interface{}{
+			"project": project.gid,
+		})
+	case previous != sectionID:
+		oldSection, newSection := s.objects[previous], s.objects[sectionID]
+		s.addStory(task, "section_changed", fmt.Sprintf("moved this task from %q to %q in %s", name(oldSection), name(newSection), name(project)), map[string]interface{}{
+			"project":     project.gid,
+			"old_section": s.snapshot(oldSection),
+			"new_section": s.snapshot(newSection),
+		})
+	}
+	return nil
+}
+
+// place puts a task in a section of a project or user task list, at the
+// position given by insert_before or insert_after, or at the end of the
+// section. Anchor tasks must be in the list, and the task takes the anchor's
+// section.
+func (s *Server) place(list, task *object, sectionID string, position map[string]interface{}) error {
+	sections := list.ordered["sections"]
+	if sectionID != "" && !contains(sections, sectionID) {
+		return errorf(http.StatusBadRequest, "section: Not a section in the %s: %s", kind(list), sectionID)
+	}
+
+	tasks := remove(list.ordered["tasks"], task.gid)
+	before, after := toString(position["insert_before"]), toString(position["insert_after"])
+	for _, anchor := range []string{before, after} {
+		if anchor == "" {
+			continue
+		}
+		a, ok := s.objects[anchor]
+		if !ok || !contains(tasks, anchor) {
+			return errorf(http.StatusBadRequest, "The task %s is not in the %s", anchor, kind(list))
+		}
+		sectionID = a.sections[list.gid]
+	}
+
+	var index int
+	switch {
+	case before != "" || after != "":
+		index = indexOf(tasks, before+after)
+		if after != "" {
+			index++
+		}
+	case hasNull(position, "insert_after"):
+		// The start of the section, or of the list
+		if sectionID == "" && len(sections) > 0 {
+			sectionID = sections[0]
+		}
+		index = len(tasks)
+		for i, gid := range tasks {
+			if o, ok := s.objects[gid]; ok && o.sections[list.gid] == sectionID {
+				index = i
+				break
+			}
+		}
+	default:
+		// The end of the section, or of the list
+		if sectionID == "" && len(sections) > 0 {
+			sectionID = sections[0]
+			if hasNull(position, "insert_before") {
+				sectionID = sections[len(sections)-1]
+			}
+		}
+		index = len(tasks)
+	}
+
+	tasks = append(tasks, "")
+	copy(tasks[index+1:], tasks[index:])
+	tasks[index] = task.gid
+	list.ordered["tasks"] = tasks
+	task.sections[list.gid] = sectionID
+	return nil
+}
+
+// kind names a project or user task list in error messages
+func kind(list *object) string {
+	if list.resourceType == "user_task_list" {
+		return "user task list"
+	}
+	return "project"
+}
+
+// hasNull reports whether a key is present with a null value, which means
+// the start or end of a list
+func hasNull(data map[string]interface{}, key string) bool {
+	value, ok := data[key]
+	return ok && value == nil
+}
+
+func indexOf(list []string, gid string) int {
+	for i, id := range list {
+		if id == gid {
+			return i
+		}
+	}
+	return -1
+}
+
+// snapshot renders the compact form of an object at the time a story was
+// created
+func (s *Server) snapshot(o *object) interface{} {
+	if o == nil {
+		return nil
+	}
+	return s.compact(o)
+}
+
+func (s *Server) addProject(r *request) (*response, error) {
+	task, err := s.get("task", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	project, err := required(r, "project")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.addToProject(task, project, toString(r.data["section"]), r.data, true); err != nil {
+		return nil, err
+	}
+	return &response{data: map[string]interface{}{}}, nil
+}
+
+func (s *Server) removeProject(r *request) (*response, error) {
+	task, err := s.get("task", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	projectID, err := required(r, "project")
+	if err != nil {
+		return nil, err
+	}
+	project, err := s.get("project", projectID)
+	if err != nil {
+		return nil, errorf(http.StatusBadRequest, "project: Not a recognized ID: %s", projectID)
+	}
+
+	if contains(task.lists["projects"], project.gid) {
+		task.lists["projects"] = remove(task.lists["projects"], project.gid)
+		delete(task.sections, project.gid)
+		project.ordered["tasks"] = remove(project.ordered["tasks"], task.gid)
+		s.addStory(task, "removed_from_project", "removed from "+name(project), map[string]interface{}{
+			"project": project.gid,
+		})
+	}
+	return &response{data: map[string]interface{}{}}, nil
+}
+
+func (s *Server) addTaskToSection(r *request) (*response, error) {
+	section, err := s.get("section", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	taskID, err := required(r, "task")
+	if err != nil {
+		return nil, err
+	}
+	task, err := s.get("task", taskID)
+	if err != nil {
+		return nil, errorf(http.StatusBadRequest, "task: Not a recognized ID: %s", taskID)
+	}
+
+	// Sections of My Tasks only hold tasks assigned to the owner of the list
+	list, ok := s.objects[section.refs["project"]]
+	myTasks := ok && list.resourceType == "user_task_list"
+	if myTasks {
+		if task.refs["assignee"] != list.refs["owner"] || task.refs["workspace"] != list.refs["workspace"] {
+			return nil, errorf(http.StatusBadRequest, "task: Not assigned to the owner of the user task list: %s", task.gid)
+		}
+		s.taskListTasks(list)
+	}
+
+	// Anchors must be in the section, and without one the task goes to the
+	// top of the section
+	before, after := toString(r.data["insert_before"]), toString(r.data["insert_after"])
+	if before != "" && after != "" {
+		return nil, errorf(http.StatusBadRequest, "Only one of insert_before and insert_after may be given")
+	}
+	position := map[string]interface{}{"insert_after": nil}
+	if anchor := before + after; anchor != "" {
+		if a, ok := s.objects[anchor]; !ok || a.sections[section.refs["project"]] != section.gid {
+			return nil, errorf(http.StatusBadRequest, "The task %s is not in the section", anchor)
+		}
+		position = r.data
+	}
+
+	if myTasks {
+		err = s.place(list, task, section.gid, position)
+	} else {
+		err = s.addToProject(task, section.refs["project"], section.gid, position, true)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &response{data: map[string]interface{}{}}, nil
+}
+
+func (s *Server) setParent(r *request) (*response, error) {
+	task, err := s.get("task", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := r.data["parent"]; !ok {
+		return nil, errorf(http.StatusBadRequest, "parent: Missing input")
+	}
+
+	if previous, ok := s.objects[task.refs["parent"]]; ok {
+		previous.ordered["subtasks"] = remove(previous.ordered["subtasks"], task.gid)
+	}
+	delete(task.refs, "parent")
+
+	parentID := toString(r.data["parent"])
+	if parentID == "" {
+		return &response{data: map[string]interface{}{}}, nil
+	}
+
+	parent, err := s.get("task", parentID)
+	if err != nil {
+		return nil, errorf(http.StatusBadRequest, "parent: Not a recognized ID: %s", parentID)
+	}
+	for p := parent; p != nil; p = s.objects[p.refs["parent"]] {
+		if p.gid == task.gid {
+			return nil, errorf(http.StatusBadRequest, "parent: A task cannot be its own ancestor")
+		}
+	}
+
+	subtasks := parent.ordered["subtasks"]
+	switch {
+	case hasNull(r.data, "insert_after"):
+		subtasks = append([]string{task.gid}, remove(subtasks, task.gid)...)
+	default:
+		subtasks, err = insert(subtasks, task.gid, toString(r.data["insert_before"]), toString(r.data["insert_after"]))
+		if err != nil {
+			return nil, err
+		}
+	}
+	parent.ordered["subtasks"] = subtasks
+	task.refs["parent"] = parent.gid
+
+	return &response{data: map[string]interface{}{}}, nil
+}
+
+func (s *Server) duplicateTask(r *request) (*response, error) {
+	task, err := s.get("task", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	name, err := required(r, "name")
+	if err != nil {
+		return nil, err
+	}
+
+	include := includes(r.data)
+	copied := s.copyTask(task, include)
+	copied.fields["name"] = name
+
+	// Copies go directly after the original
+	if parent, ok := s.objects[task.refs["parent"]]; ok && include["parent"] {
+		copied.refs["parent"] = parent.gid
+		if parent.ordered["subtasks"], err = insert(parent.ordered["subtasks"], copied.gid, "", task.gid); err != nil {
+			return nil, err
+		}
+	}
+	if include["projects"] {
+		for _, project := range task.lists["projects"] {
+			position := map[string]interface{}{"insert_after": task.gid}
+			if err := s.addToProject(copied, project, "", position, false); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &response{status: http.StatusCreated, object: s.newJob("duplicate_task", "new_task", copied.gid)}, nil
+}
+
+// addTaskLinks returns a handler which adds dependencies or dependents to a
+// task, along with the inverse link on the other task
+func (s *Server) addTaskLinks(key, inverse string) handlerFunc {
+	return func(r *request) (*response, error) {
+		task, err := s.get("task", r.params[0])
+		if err != nil {
+			return nil, err
+		}
+		gids, err := s.references(key, r.data[key])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, gid := range gids {
+			other, err := s.get("task", gid)
+			if err != nil || gid == task.gid {
+				return nil, errorf(http.StatusBadRequest, "%s: Not a valid task: %s", key, gid)
+			}
+			if !contains(task.lists[key], gid) {
+				task.lists[key] = append(task.lists[key], gid)
+			}
+			if !contains(other.lists[inverse], task.gid) {
+				other.lists[inverse] = append(other.lists[inverse], task.gid)
+			}
+		}
+		return &response{data: map[string]interface{}{}}, nil
+	}
+}
+
+// Tags
+
+func (s *Server) listTags(r *request) (*response, error) {
+	workspace, err := required(r, "workspace")
+	if err != nil {
+		return nil, err
+	}
+	return &response{list: s.list("tag", func(o *object) bool {
+		return o.refs["workspace"] == workspace
+	})}, nil
+}
+
+func (s *Server) listWorkspaceTags(r *request) (*response, error) {
+	if _, err := s.get("workspace", r.params[0]); err != nil {
+		return nil, err
+	}
+	r.query.Set("workspace", r.params[0])
+	return s.listTags(r)
+}
+
+func (s *Server) createTag(r *request) (*response, error) {
+	workspace, err := required(r, "workspace")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.reference("workspace", workspace); err != nil {
+		return nil, err
+	}
+
+	tag := s.create("tag", map[string]interface{}{
+		"workspace": workspace,
+		"followers": []string{},
+		"notes":     "",
+	})
+	if err := s.apply(tag, r.data, tagFields); err != nil {
+		s.delete(tag.gid)
+		return nil, err
+	}
+	return &response{status: http.StatusCreated, object: tag}, nil
+}
+
+func (s *Server) createWorkspaceTag(r *request) (*response, error) {
+	r.data["workspace"] = r.params[0]
+	return s.createTag(r)
+}
+
+func (s *Server) listTaskTags(r *request) (*response, error) {
+	task, err := s.get("task", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	return &response{list: s.resolve(task.lists["tags"])}, nil
+}
+
+func (s *Server) addTag(r *request) (*response, error) {
+	task, err := s.get("task", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	tagID, err := required(r, "tag")
+	if err != nil {
+		return nil, err
+	}
+	tag, err := s.get("tag", tagID)
+	if err != nil {
+		return nil, errorf(http.StatusBadRequest, "tag: Not a recognized ID: %s", tagID)
+	}
+
+	if !contains(task.lists["tags"], tag.gid) {
+		task.lists["tags"] = append(task.lists["tags"], tag.gid)
+		s.addStory(task, "added_to_tag", "added to "+name(tag), map[string]interface{}{
+			"tag": tag.gid,
+		})
+	}
+	return &response{data: map[string]interface{}{}}, nil
+}
+
+func (s *Server) removeTag(r *request) (*response, error) {
+	task, err := s.get("task", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	tagID, err := required(r, "tag")
+	if err != nil {
+		return nil, err
+	}
+	tag, err := s.get("tag", tagID)
+	if err != nil {
+		return nil, errorf(http.StatusBadRequest, "tag: Not a recognized ID: %s", tagID)
+	}
+
+	if contains(task.lists["tags"], tag.gid) {
+		task.lists["tags"] = remove(task.lists["tags"], tag.gid)
+		s.addStory(task, "removed_from_tag", "removed from "+name(tag), map[string]interface{}{
+			"tag": tag.gid,
+		})
+	}
+	return &response{data: map[string]interface{}{}}, nil
+}
+
+// Stories
+
+func (s *Server) listStories(r *request) (*response, error) {
+	task, err := s.get("task", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	return &response{list: s.list("story", func(o *object) bool {
+		return o.refs["target"] == task.gid
+	})}, nil
+}
+
+// addStory records a system story on a task
+func (s *Server) addStory(task *object, subtype, text string, fields map[string]interface{}) *object {
+	story := s.create("story", map[string]interface{}{
+		"type":             "system",
+		"resource_subtype": subtype,
+		"text":             text,
+		"created_by":       s.me,
+		"target":           task.gid,
+		"source":           "api",
+	})
+	for key, value := range fields {
+		if refFields[key] {
+			story.refs[key] = toString(value)
+		} else {
+			story.fields[key] = value
+		}
+	}
+	task.fields["modified_at"] = s.now()
+	return story
+}
+
+func (s *Server) createComment(r *request) (*response, error) {
+	task, err := s.get("task", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+
+	story := s.create("story", map[string]interface{}{
+		"type":             "comment",
+		"resource_subtype": "comment_added",
+		"created_by":       s.me,
+		"target":           task.gid,
+		"source":           "api",
+		"is_pinned":        toBool(r.data["is_pinned"]),
+		"is_edited":        false,
+		"liked":            false,
+		"likes":            []interface{}{},
+		"num_likes":        0,
+	})
+	if err := setCommentText(story, r.data); err != nil {
+		s.delete(story.gid)
+		return nil, err
+	}
+	task.fields["modified_at"] = s.now()
+
+	return &response{status: http.StatusCreated, object: story}, nil
+}
+
+func (s *Server) updateStory(r *request) (*response, error) {
+	story, err := s.get("story", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+
+	_, hasText := r.data["text"]
+	_, hasHTML := r.data["html_text"]
+	if hasText || hasHTML {
+		if story.fields["type"] != "comment" {
+			return nil, errorf(http.StatusForbidden, "Only comments can be edited")
+		}
+		if err := setCommentText(story, r.data); err != nil {
+			return nil, err
+		}
+		story.fields["is_edited"] = true
+	}
+	if pinned, ok := r.data["is_pinned"]; ok {
+		story.fields["is_pinned"] = toBool(pinned)
+	}
+	if liked, ok := r.data["liked"]; ok {
+		if !likeable[toString(story.fields["resource_subtype"])] {
+			return nil, errorf(http.StatusBadRequest, "liked: This story cannot be liked")
+		}
+		s.setLiked(story, toBool(liked))
+	}
+	return &response{object: story}, nil
+}
+
+// Stories which can be liked
+var likeable = fieldSet("comment_added", "marked_complete", "attachment_added")
+
+// setLiked adds or removes the current user from the likes of a story
+func (s *Server) setLiked(story *object, liked bool) {
+	likes := []interface{}{}
+	previous, _ := story.fields["likes"].([]interface{})
+	for _, like := range previous {
+		if user, _ := like.(map[string]interface{})["user"].(map[string]interface{}); user["gid"] != s.me {
+			likes = append(likes, like)
+		}
+	}
+	if liked {
+		s.nextID++
+		likes = append(likes, map[string]interface{}{
+			"gid":  strconv.Itoa(s.nextID),
+			"user": s.compact(s.objects[s.me]),
+		})
+	}
+	story.fields["likes"] = likes
+	story.fields["num_likes"] = len(likes)
+	story.fields["liked"] = liked
+}
+
+// richText checks that rich text is well-formed XML with a single body
+// element, and returns its text without markup. This is deliberately
+// simpler than asanahtml, so that tests of that package against the fake
+// are not checking it against itself.
+func richText(text string) (string, error) {
+	decoder := xml.NewDecoder(strings.NewReader(text))
+	decoder.Entity = xml.HTMLEntity
+
+	plain := &strings.Builder{}
+	depth, roots := 0, 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				roots++
+				if t.Name.Local != "body" || roots > 1 {
+					return "", errors.New("expected a single <body> root element")
+				}
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if depth > 0 {
+				plain.Write(t)
+			}
+		}
+	}
+	if roots == 0 {
+		return "", errors.New("no <body> element")
+	}
+	return plain.String(), nil
+}
+
+// setCommentText sets the text and html_text of a comment from either one
+func setCommentText(story *object, data map[string]interface{}) error {
+	text, hasText := data["text"].(string)
+	htmlText, hasHTML := data["html_text"].(string)
+
+	switch {
+	case hasText && hasHTML:
+		return errorf(http.StatusBadRequest, "You may only specify one of text and html_text")
+	case hasHTML:
+		plain, err := richText(htmlText)
+		if err != nil {
+			return errorf(http.StatusBadRequest, "html_text: XML is invalid: %s", err)
+		}
+		story.fields["html_text"] = htmlText
+		story.fields["text"] = plain
+	case hasText:
+		story.fields["text"] = text
+		story.fields["html_text"] = "<body>" + html.EscapeString(text) + "</body>"
+	default:
+		return errorf(http.StatusBadRequest, "text: Missing input")
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package asanatest
+
+import (
+	"net/http"
+	"strconv"
+
+	asana "github.com/incident-io/asana-go"
+)
+
+// AddProjectTemplate saves a project as a template, which cannot be done
+// through the API. The template is shared with the project's team and asks
+// for the named dates and roles when it is instantiated.
+//
+// Projects instantiated from the template copy the project's sections,
+// custom fields and tasks as they are at instantiation. Task dates are not
+// shifted and roles are not assigned.
+func (s *Server) AddProjectTemplate(projectID, name string, dates, roles []string) *asana.ProjectTemplate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, ok := s.objects[projectID]
+	if !ok || project.resourceType != "project" {
+		panic("asanatest: unknown project " + projectID)
+	}
+
+	result := &asana.ProjectTemplate{Name: name}
+	requestedDates := []interface{}{}
+	for _, date := range dates {
+		s.nextID++
+		variable := &asana.DateVariable{ID: strconv.Itoa(s.nextID), Name: date}
+		result.RequestedDates = append(result.RequestedDates, variable)
+		requestedDates = append(requestedDates, map[string]interface{}{"gid": variable.ID, "name": date})
+	}
+	requestedRoles := []interface{}{}
+	for _, role := range roles {
+		s.nextID++
+		r := &asana.TemplateRole{ID: strconv.Itoa(s.nextID), Name: role}
+		result.RequestedRoles = append(result.RequestedRoles, r)
+		requestedRoles = append(requestedRoles, map[string]interface{}{"gid": r.ID, "name": role})
+	}
+
+	fields := map[string]interface{}{
+		"name":            name,
+		"description":     "",
+		"public":          false,
+		"owner":           s.me,
+		"workspace":       project.refs["workspace"],
+		"requested_dates": requestedDates,
+		"requested_roles": requestedRoles,
+	}
+	if team, ok := project.refs["team"]; ok {
+		fields["team"] = team
+	}
+	template := s.create("project_template", fields)
+	template.ordered["project"] = []string{project.gid}
+
+	result.ID = template.gid
+	return result
+}
+
+func (s *Server) listProjectTemplates(r *request) (*response, error) {
+	workspace, team := r.query.Get("workspace"), r.query.Get("team")
+	if workspace == "" && team == "" {
+		return nil, errorf(http.StatusBadRequest, "workspace: Missing input")
+	}
+	return &response{list: s.list("project_template", func(o *object) bool {
+		return (workspace == "" || o.refs["workspace"] == workspace) && (team == "" || o.refs["team"] == team)
+	})}, nil
+}
+
+func (s *Server) listTeamProjectTemplates(r *request) (*response, error) {
+	if _, err := s.get("team", r.params[0]); err != nil {
+		return nil, err
+	}
+	return &response{list: s.list("project_template", func(o *object) bool {
+		return o.refs["team"] == r.params[0]
+	})}, nil
+}
+
+func (s *Server) instantiateProject(r *request) (*response, error) {
+	template, err := s.get("project_template", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	name, err := required(r, "name")
+	if err != nil {
+		return nil, err
+	}
+
+	// Every requested date must be given, and roles too if strict
+	if err := checkVariables(template.fields["requested_dates"], r.data["requested_dates"], "requested_dates", true, nil); err != nil {
+		return nil, err
+	}
+	isUser := func(value string) bool {
+		o, ok := s.objects[value]
+		return ok && o.resourceType == "user"
+	}
+	if err := checkVariables(template.fields["requested_roles"], r.data["requested_roles"], "requested_roles", toBool(r.data["is_strict"]), isUser); err != nil {
+		return nil, err
+	}
+
+	source, err := s.get("project", template.ordered["project"][0])
+	if err != nil {
+		return nil, errorf(http.StatusBadRequest, "The template's project no longer exists")
+	}
+	include := map[string]bool{}
+	for _, part := range []string{"notes", "task_notes", "task_assignee", "task_dates", "task_subtasks", "task_tags", "task_dependencies"} {
+		include[part] = true
+	}
+	project, err := s.copyProject(source, name, toString(r.data["team"]), include)
+	if err != nil {
+		return nil, err
+	}
+	if public, ok := r.data["public"]; ok {
+		project.fields["public"] = toBool(public)
+	}
+
+	return &response{status: http.StatusCreated, object: s.newJob("instantiate_project", "new_project", project.gid)}, nil
+}
+
+// checkVariables validates the values given for a template's requested
+// dates or roles. Values must refer to a variable of the template, and all
+// variables must have a value if complete is set.
+func checkVariables(variables, values interface{}, key string, complete bool, valid func(string) bool) error {
+	var order []string
+	known := map[string]bool{}
+	list, _ := variables.([]interface{})
+	for _, v := range list {
+		variable, _ := v.(map[string]interface{})
+		order = append(order, toString(variable["gid"]))
+		known[toString(variable["gid"])] = true
+	}
+
+	given := map[string]bool{}
+	list, _ = values.([]interface{})
+	for _, v := range list {
+		value, _ := v.(map[string]interface{})
+		gid := toString(value["gid"])
+		if !known[gid] {
+			return errorf(http.StatusBadRequest, "%s: Not a variable of the template: %s", key, gid)
+		}
+		if toString(value["value"]) == "" || (valid != nil && !valid(toString(value["value"]))) {
+			return errorf(http.StatusBadRequest, "%s: Invalid value for %s", key, gid)
+		}
+		given[gid] = true
+	}
+
+	if complete {
+		for _, gid := range order {
+			if !given[gid] {
+				return errorf(http.StatusBadRequest, "%s: Missing a value for %s", key, gid)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package asanatest
+
+import (
+	"net/http"
+
+	asana "github.com/incident-io/asana-go"
+)
+
+// User task lists
+
+// userTaskList returns a user's My Tasks list in a workspace, creating it the
+// first time it is needed
+func (s *Server) userTaskList(user, workspace string) *object {
+	for _, list := range s.list("user_task_list", nil) {
+		if list.refs["owner"] == user && list.refs["workspace"] == workspace {
+			return list
+		}
+	}
+
+	// Asana creates My Tasks with a single section for new assignments
+	list := s.create("user_task_list", map[string]interface{}{
+		"name":      "My Tasks",
+		"owner":     user,
+		"workspace": workspace,
+	})
+	section := s.create("section", map[string]interface{}{
+		"name":    "Recently assigned",
+		"project": list.gid,
+	})
+	list.ordered["sections"] = []string{section.gid}
+	return list
+}
+
+// AddTaskListSection adds a section to the bottom of a user's My Tasks list
+// in a workspace, which cannot be done through the API
+func (s *Server) AddTaskListSection(userID, workspaceID, name string) *asana.Section {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user, ok := s.objects[userID]; !ok || user.resourceType != "user" {
+		panic("asanatest: unknown user " + userID)
+	}
+	if workspace, ok := s.objects[workspaceID]; !ok || workspace.resourceType != "workspace" {
+		panic("asanatest: unknown workspace " + workspaceID)
+	}
+
+	list := s.userTaskList(userID, workspaceID)
+	section := s.create("section", map[string]interface{}{
+		"name":    name,
+		"project": list.gid,
+	})
+	list.ordered["sections"] = append(list.ordered["sections"], section.gid)
+	return &asana.Section{ID: section.gid, SectionBase: asana.SectionBase{Name: name}}
+}
+
+// taskListTasks returns the tasks in a user task list in order. Tasks which
+// have been assigned to the owner since the list was last read go to the top
+// of its first section, and tasks which are no longer assigned are dropped.
+func (s *Server) taskListTasks(list *object) []*object {
+	owner, workspace := list.refs["owner"], list.refs["workspace"]
+
+	var kept []string
+	for _, task := range s.resolve(list.ordered["tasks"]) {
+		if task.refs["assignee"] == owner && task.refs["workspace"] == workspace {
+			kept = append(kept, task.gid)
+		} else {
+			delete(task.sections, list.gid)
+		}
+	}
+
+	var added []string
+	for _, task := range s.list("task", func(o *object) bool {
+		return o.refs["assignee"] == owner && o.refs["workspace"] == workspace && !contains(kept, o.gid)
+	}) {
+		task.sections[list.gid] = list.ordered["sections"][0]
+		added = append(added, task.gid)
+	}
+
+	list.ordered["tasks"] = append(added, kept...)
+	return s.resolve(list.ordered["tasks"])
+}
+
+// assigneeSection renders the section of the assignee's My Tasks which holds
+// a task
+func (s *Server) assigneeSection(task *object) interface{} {
+	assignee := task.refs["assignee"]
+	if _, ok := s.objects[assignee]; !ok {
+		return nil
+	}
+	list := s.userTaskList(assignee, task.refs["workspace"])
+	s.taskListTasks(list)
+	if section, ok := s.objects[task.sections[list.gid]]; ok {
+		return s.compact(section)
+	}
+	return nil
+}
+
+func (s *Server) getUserTaskList(r *request) (*response, error) {
+	user := r.params[0]
+	if user == "me" {
+		user = s.me
+	}
+	if _, err := s.get("user", user); err != nil {
+		return nil, err
+	}
+	workspace, err := required(r, "workspace")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.get("workspace", workspace); err != nil {
+		return nil, errorf(http.StatusBadRequest, "workspace: Not a recognized ID: %s", workspace)
+	}
+	return &response{object: s.userTaskList(user, workspace)}, nil
+}
+
+func (s *Server) listUserTaskListTasks(r *request) (*response, error) {
+	list, err := s.get("user_task_list", r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	return s.tasks(r.query, s.taskListTasks(list))
+}
+
+// sectionParent returns the project or user task list which holds sections
+func (s *Server) sectionParent(gid string) (*object, error) {
+	o, err := s.get("", gid)
+	if err != nil || (o.resourceType != "project" && o.resourceType != "user_task_list") {
+		return nil, notFound(gid)
+	}
+	return o, nil
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/pkg/errors"
@@ -19,6 +22,10 @@ type Attachment struct {
 	// Read-only. The name of the object.
 	Name string `json:"name,omitempty"`
 
+	// Read-only. The service hosting the attachment: asana, dropbox, gdrive,
+	// onedrive, box, vimeo or external.
+	ResourceSubtype string `json:"resource_subtype,omitempty"`
+
 	// Read-only. The task this object is attached to.
 	Parent *Task `json:"parent,omitempty"`
 
@@ -44,6 +51,29 @@ type Attachment struct {
 
 	// Undocumented. A permanent asana.com link which should be a permalink
 	PermanentURL string `json:"permanent_url,omitempty"`
+
+	// Read-only. The size of the attachment in bytes, if known.
+	Size int64 `json:"size,omitempty"`
+
+	// Read-only. Whether the attachment is connected to the app making the
+	// request, so that it is shown as a widget on the task.
+	ConnectedToApp bool `json:"connected_to_app,omitempty"`
+}
+
+// Fetch loads the full details for this Attachment, including a fresh
+// DownloadURL
+func (a *Attachment) Fetch(ctx context.Context, client *Client, options ...*Options) error {
+	client.trace("Loading details for attachment %q", a.ID)
+
+	_, err := client.Get(ctx, fmt.Sprintf("/attachments/%s", a.ID), nil, a, options...)
+	return err
+}
+
+// Delete removes this attachment from its parent
+func (a *Attachment) Delete(ctx context.Context, client *Client) error {
+	client.info("Deleting attachment %q", a.ID)
+
+	return client.delete(ctx, fmt.Sprintf("/attachments/%s", a.ID))
 }
 
 // Attachments lists all attachments attached to a task
@@ -57,19 +87,285 @@ func (t *Task) Attachments(ctx context.Context, client *Client, opts ...*Options
 	return result, nextPage, err
 }
 
+// MaxAttachmentSize is the largest file Asana accepts as an attachment, in
+// bytes
+const MaxAttachmentSize = 100 * 1024 * 1024
+
+// NewAttachment describes a file to upload as an attachment
 type NewAttachment struct {
-	Reader      io.ReadCloser
+	// The content of the file. It is closed once the upload has finished if
+	// it is an io.Closer. A failed upload is only retried if it is also an
+	// io.Seeker, in which case it is rewound to where it started.
+	Reader      io.Reader
 	FileName    string
 	ContentType string
+
+	// The size of the file in bytes, if known. If zero and Reader is an
+	// io.Seeker, the size is found by seeking to the end. Files known to be
+	// larger than MaxAttachmentSize are rejected before anything is sent.
+	Size int64
+
+	// Progress, if set, is called as the file is sent with the number of
+	// bytes sent so far and the size of the file, or -1 if the size is not
+	// known. It starts again from zero if the upload is retried.
+	Progress func(sent, total int64)
 }
 
-func (t *Task) CreateAttachment(ctx context.Context, client *Client, request *NewAttachment) (*Attachment, error) {
-	client.trace("Uploading attachment for %q", t.Name)
+// AttachmentTooLargeError is returned when a file is larger than
+// MaxAttachmentSize. IsPayloadTooLarge returns true for it.
+type AttachmentTooLargeError struct {
+	FileName string
+
+	// The size of the file, or if its size was not known in advance, the
+	// number of bytes read before the limit was passed
+	Size int64
+}
+
+func (err *AttachmentTooLargeError) Error() string {
+	return fmt.Sprintf("attachment %q is larger than the %d byte limit (%d bytes)", err.FileName, MaxAttachmentSize, err.Size)
+}
+
+// CreateAttachment uploads a file and attaches it to a parent object, which
+// may be a task, project or project brief
+func (c *Client) CreateAttachment(ctx context.Context, parentID string, request *NewAttachment) (*Attachment, error) {
+	c.info("Uploading attachment %q to %q", request.FileName, parentID)
+
+	fields := map[string]string{
+		"parent": parentID,
+	}
 
 	result := &Attachment{}
-	err := client.postMultipart(ctx, fmt.Sprintf("/tasks/%s/attachments", t.ID), result, "file", request.Reader, request.FileName, request.ContentType)
+	err := c.postMultipart(ctx, "/attachments", fields, request, result)
 	if err != nil {
 		return nil, errors.Wrap(err, "Upload attachment")
 	}
 	return result, nil
 }
+
+// CreateAttachment uploads a file and attaches it to this task
+func (t *Task) CreateAttachment(ctx context.Context, client *Client, request *NewAttachment) (*Attachment, error) {
+	return client.CreateAttachment(ctx, t.ID, request)
+}
+
+// CreateAttachment uploads a file and attaches it to this project
+func (p *Project) CreateAttachment(ctx context.Context, client *Client, request *NewAttachment) (*Attachment, error) {
+	return client.CreateAttachment(ctx, p.ID, request)
+}
+
+// upload streams the content of a new attachment, enforcing the size limit
+// and reporting progress as it is read
+type upload struct {
+	file     *NewAttachment
+	size     int64
+	seekable bool
+	start    int64
+	sent     int64
+
+	// Set if the file turned out to be too large while it was being sent
+	err error
+}
+
+func newUpload(file *NewAttachment) (*upload, error) {
+	if file.Reader == nil {
+		return nil, errors.New("An attachment requires a reader")
+	}
+
+	u := &upload{file: file, size: -1}
+	if file.Size > 0 {
+		u.size = file.Size
+	}
+	if seeker, ok := file.Reader.(io.Seeker); ok {
+		if start, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			u.seekable, u.start = true, start
+			if u.size < 0 {
+				if end, err := seeker.Seek(0, io.SeekEnd); err == nil {
+					u.size = end - start
+				}
+			}
+		}
+	}
+
+	if u.size > MaxAttachmentSize {
+		return nil, &AttachmentTooLargeError{FileName: file.FileName, Size: u.size}
+	}
+	return u, nil
+}
+
+// rewind prepares the upload to be sent, returning to the start of the file
+// if it has already been read
+func (u *upload) rewind() error {
+	u.sent, u.err = 0, nil
+	if !u.seekable {
+		return nil
+	}
+	_, err := u.file.Reader.(io.Seeker).Seek(u.start, io.SeekStart)
+	return err
+}
+
+func (u *upload) Read(p []byte) (int, error) {
+	n, err := u.file.Reader.Read(p)
+	u.sent += int64(n)
+	if u.sent > MaxAttachmentSize {
+		u.err = &AttachmentTooLargeError{FileName: u.file.FileName, Size: u.sent}
+		return n, u.err
+	}
+	if n > 0 && u.file.Progress != nil {
+		u.file.Progress(u.sent, u.size)
+	}
+	return n, err
+}
+
+// NewExternalAttachment describes a link to attach to a task
+type NewExternalAttachment struct {
+	// Required: The URL of the external resource.
+	URL string
+
+	// Required: The name shown for the link.
+	Name string
+
+	// Show the attachment as a widget on the task, provided by the app
+	// making the request.
+	ConnectToApp bool
+}
+
+// Validate checks that the request has the required fields
+func (r *NewExternalAttachment) Validate() error {
+	if r.URL == "" {
+		return errors.New("A URL is required for an external attachment")
+	}
+	if r.Name == "" {
+		return errors.New("A name is required for an external attachment")
+	}
+	return nil
+}
+
+// CreateExternalAttachment attaches a link to this task. Nothing is
+// uploaded; Asana only records the URL.
+func (t *Task) CreateExternalAttachment(ctx context.Context, client *Client, request *NewExternalAttachment) (*Attachment, error) {
+	client.info("Attaching %q to %q", request.URL, t.Name)
+
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Custom request encoding
+	fields := map[string]string{
+		"parent":           t.ID,
+		"resource_subtype": "external",
+		"url":              request.URL,
+		"name":             request.Name,
+	}
+	if request.ConnectToApp {
+		fields["connect_to_app"] = "true"
+	}
+
+	result := &Attachment{}
+	err := client.postMultipart(ctx, "/attachments", fields, nil, result)
+	return result, err
+}
+
+// Download writes the content of this attachment to w as it arrives. The
+// DownloadURL is short-lived, so it is refreshed first if it is missing or
+// has expired, and once more if the file host rejects it. An error is
+// returned if the content is shorter or longer than the host or Asana said
+// it would be, in which case w will have received a partial file.
+func (a *Attachment) Download(ctx context.Context, client *Client, w io.Writer) error {
+	client.trace("Downloading attachment %q", a.ID)
+
+	refreshed := false
+	if a.DownloadURL == "" || downloadURLExpired(a.DownloadURL, time.Now()) {
+		if err := a.Fetch(ctx, client); err != nil {
+			return err
+		}
+		refreshed = true
+	}
+
+	for {
+		if a.DownloadURL == "" {
+			return errors.Errorf("Attachment %s hosted by %s has no download URL", a.ID, a.Host)
+		}
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, a.DownloadURL, nil)
+		if err != nil {
+			return errors.Wrap(err, "Download attachment")
+		}
+		httpClient := client.DownloadClient
+		if httpClient == nil {
+			httpClient = http.DefaultClient
+		}
+		resp, err := httpClient.Do(request)
+		if err != nil {
+			return errors.Wrap(err, "Download attachment")
+		}
+
+		// Expired or revoked URLs are reported as access denied
+		if !refreshed && (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized) {
+			resp.Body.Close()
+			if err := a.Fetch(ctx, client); err != nil {
+				return err
+			}
+			refreshed = true
+			continue
+		}
+
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return errors.Errorf("Download attachment %s: %s", a.ID, resp.Status)
+		}
+
+		expected := resp.ContentLength
+		if expected < 0 && a.Size > 0 {
+			expected = a.Size
+		}
+		n, err := io.Copy(w, resp.Body)
+		if err != nil {
+			return errors.Wrapf(err, "Download attachment %s", a.ID)
+		}
+		if expected >= 0 && n != expected {
+			return errors.Errorf("Download attachment %s: received %d bytes, expected %d", a.ID, n, expected)
+		}
+		return nil
+	}
+}
+
+// downloadURLExpired reports whether a pre-signed download URL has passed
+// its expiry time. URLs without a recognisable expiry are assumed to be
+// valid.
+func downloadURLExpired(downloadURL string, now time.Time) bool {
+	u, err := url.Parse(downloadURL)
+	if err != nil {
+		return false
+	}
+	q := u.Query()
+
+	// Signature version 4: the signing time plus a lifetime in seconds
+	if signed, err := time.Parse("20060102T150405Z", q.Get("X-Amz-Date")); err == nil {
+		if seconds, err := strconv.ParseInt(q.Get("X-Amz-Expires"), 10, 64); err == nil {
+			return !now.Before(signed.Add(time.Duration(seconds) * time.Second))
+		}
+	}
+
+	// Signature version 2: a Unix timestamp
+	if expires, err := strconv.ParseInt(q.Get("Expires"), 10, 64); err == nil {
+		return !now.Before(time.Unix(expires, 0))
+	}
+	return false
+}
+
+// AttachmentIterator iterates over a list of attachments
+type AttachmentIterator struct {
+	*Iterator
+}
+
+// Value returns the current attachment
+func (it *AttachmentIterator) Value() *Attachment {
+	v, _ := it.Iterator.Value().(*Attachment)
+	return v
+}
+
+// IterateAttachments returns an iterator over the attachments on this task
+func (t *Task) IterateAttachments(ctx context.Context, client *Client, options ...*Options) *AttachmentIterator {
+	return &AttachmentIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return t.Attachments(ctx, client, options...)
+	}, options...)}
+}
@@ -0,0 +1,206 @@
+package asana
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAttachmentDownload(t *testing.T) {
+	var baseURL string
+	fetches := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/attachments/1":
+			fetches++
+			signed := time.Now().UTC().Format("20060102T150405Z")
+			fmt.Fprintf(w, `{"data": {"gid": "1", "size": 11, "download_url": "%s/files/1?X-Amz-Date=%s&X-Amz-Expires=3600&token=%d"}}`, baseURL, signed, fetches)
+		case "/files/1":
+			if r.Header.Get("Authorization") != "" {
+				t.Error("Expected no credentials to be sent to the file host")
+			}
+			if r.URL.Query().Get("token") == "revoked" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte("screenshot!"))
+		case "/files/2":
+			// Streamed without a Content-Length
+			w.Write([]byte("short"))
+			w.(http.Flusher).Flush()
+		default:
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+	})
+	baseURL = client.BaseURL.String()
+	ctx := context.Background()
+
+	// An expired URL is refreshed before downloading
+	attachment := &Attachment{ID: "1", DownloadURL: baseURL + "/files/1?X-Amz-Date=20200101T000000Z&X-Amz-Expires=3600"}
+	buffer := &bytes.Buffer{}
+	if err := attachment.Download(ctx, client, buffer); err != nil {
+		t.Fatal(err)
+	}
+	if buffer.String() != "screenshot!" || fetches != 1 {
+		t.Errorf("Expected one refresh and the file content, saw %d and %q", fetches, buffer)
+	}
+
+	// A URL which looks valid but is rejected is refreshed once
+	attachment.DownloadURL = baseURL + "/files/1?token=revoked"
+	buffer.Reset()
+	if err := attachment.Download(ctx, client, buffer); err != nil {
+		t.Fatal(err)
+	}
+	if buffer.String() != "screenshot!" || fetches != 2 {
+		t.Errorf("Expected a second refresh and the file content, saw %d and %q", fetches, buffer)
+	}
+
+	// The content must match the size Asana reported
+	short := &Attachment{ID: "2", Size: 11, DownloadURL: baseURL + "/files/2"}
+	err := short.Download(ctx, client, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "received 5 bytes, expected 11") {
+		t.Errorf("Expected a length mismatch, saw %v", err)
+	}
+}
+
+func TestDownloadURLExpired(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	cases := map[string]bool{
+		"https://s3.amazonaws.com/f?X-Amz-Date=20210601T113000Z&X-Amz-Expires=3600": false,
+		"https://s3.amazonaws.com/f?X-Amz-Date=20210601T103000Z&X-Amz-Expires=3600": true,
+		"https://s3.amazonaws.com/f?Expires=1622552400":                             false,
+		"https://s3.amazonaws.com/f?Expires=1622545200":                             true,
+		"https://example.com/f": false,
+	}
+	for downloadURL, expected := range cases {
+		if expired := downloadURLExpired(downloadURL, now); expired != expected {
+			t.Errorf("Expected expired to be %v for %s", expected, downloadURL)
+		}
+	}
+}
+
+func TestCreateExternalAttachment(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/attachments" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+		for key, expected := range map[string]string{
+			"parent":           "1",
+			"resource_subtype": "external",
+			"url":              "https://grafana.example.com/d/1",
+			"name":             "Dashboard",
+			"connect_to_app":   "",
+		} {
+			if value := r.FormValue(key); value != expected {
+				t.Errorf("Expected %s to be %q, saw %q", key, expected, value)
+			}
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"data": {"gid": "2", "resource_subtype": "external", "name": "Dashboard"}}`))
+	})
+
+	task := &Task{ID: "1"}
+	attachment, err := task.CreateExternalAttachment(context.Background(), client, &NewExternalAttachment{
+		URL:  "https://grafana.example.com/d/1",
+		Name: "Dashboard",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attachment.ID != "2" || attachment.ResourceSubtype != "external" {
+		t.Errorf("Unexpected attachment %+v", attachment)
+	}
+
+	if _, err := task.CreateExternalAttachment(context.Background(), client, &NewExternalAttachment{Name: "Missing"}); err == nil {
+		t.Error("Expected an attachment without a URL to be rejected")
+	}
+}
+
+func TestCreateAttachment(t *testing.T) {
+	requests := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/attachments" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		if r.ContentLength < 0 {
+			t.Error("Expected the upload to have a known length")
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+		if parent := r.FormValue("parent"); parent != "1" {
+			t.Errorf("Expected the parent to be 1, saw %q", parent)
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := ioutil.ReadAll(file)
+		if header.Filename != "timeline.txt" || string(content) != "file contents" {
+			t.Errorf("Unexpected file %q containing %q", header.Filename, content)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"data": {"gid": "2", "name": "timeline.txt"}}`))
+	})
+
+	var sent, total int64
+	attachment, err := (&Project{ID: "1"}).CreateAttachment(context.Background(), client, &NewAttachment{
+		Reader:      strings.NewReader("file contents"),
+		FileName:    "timeline.txt",
+		ContentType: "text/plain",
+		Progress: func(s, t int64) {
+			sent, total = s, t
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attachment.ID != "2" {
+		t.Errorf("Unexpected attachment %+v", attachment)
+	}
+	if sent != 13 || total != 13 {
+		t.Errorf("Expected progress to reach 13 of 13 bytes, saw %d of %d", sent, total)
+	}
+
+	// Files known to be too large are not sent
+	_, err = client.CreateAttachment(context.Background(), "1", &NewAttachment{
+		Reader:   strings.NewReader("small"),
+		FileName: "huge.mp4",
+		Size:     MaxAttachmentSize + 1,
+	})
+	if !IsPayloadTooLarge(err) {
+		t.Errorf("Expected a payload too large error, saw %v", err)
+	}
+	_, err = client.CreateAttachment(context.Background(), "1", &NewAttachment{
+		Reader:   hugeFile{},
+		FileName: "huge.mp4",
+	})
+	if !IsPayloadTooLarge(err) {
+		t.Errorf("Expected the size to be found by seeking, saw %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected oversize files not to be sent, saw %d requests", requests)
+	}
+}
+
+// hugeFile claims to be larger than the attachment limit
+type hugeFile struct{}
+
+func (hugeFile) Read(p []byte) (int, error) { return len(p), nil }
+
+func (hugeFile) Seek(offset int64, whence int) (int64, error) {
+	if whence == io.SeekEnd {
+		return 2 * MaxAttachmentSize, nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,265 @@
+package asana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MaxBatchActions is the number of actions Asana accepts in a single batch
+// request. Larger batches are split into several requests.
+const MaxBatchActions = 10
+
+// BatchAction is a single request queued in a Batch. After the batch has been
+// executed, the response is decoded into the result supplied when the action
+// was queued and Err reports whether the action succeeded.
+type BatchAction struct {
+	RelativePath string      `json:"relative_path"`
+	Method       string      `json:"method"`
+	Data         interface{} `json:"data,omitempty"`
+	Options      *Options    `json:"options,omitempty"`
+
+	// Read-only. The HTTP status code of the action's response
+	StatusCode int `json:"-"`
+
+	result interface{}
+	err    error
+	done   bool
+}
+
+// Err returns the error for this action, or nil if it succeeded. Actions
+// which have not been executed report an error.
+func (a *BatchAction) Err() error {
+	if !a.done {
+		return errors.Errorf("Batch action %s %s was not executed", a.Method, a.RelativePath)
+	}
+	return a.err
+}
+
+// Batch bundles several API requests together so that they can be sent with
+// a single round trip per MaxBatchActions actions.
+//
+// Actions are queued with the methods on Batch and sent by Execute. Actions
+// are independent: one failing does not stop the others, and each reports its
+// own error through BatchAction.Err.
+type Batch struct {
+	client  *Client
+	actions []*BatchAction
+}
+
+// NewBatch creates an empty batch of actions
+func (c *Client) NewBatch() *Batch {
+	return &Batch{client: c}
+}
+
+// Actions returns the actions queued in this batch
+func (b *Batch) Actions() []*BatchAction {
+	return b.actions
+}
+
+// Len returns the number of actions queued in this batch
+func (b *Batch) Len() int {
+	return len(b.actions)
+}
+
+// Get queues a GET request. data is sent in the action's data field, which
+// the batch API treats as the query parameters, and the response data is
+// decoded into result.
+func (b *Batch) Get(path string, data, result interface{}, opts ...*Options) *BatchAction {
+	return b.add(http.MethodGet, path, data, result, opts...)
+}
+
+// Post queues a POST request with the given data
+func (b *Batch) Post(path string, data, result interface{}, opts ...*Options) *BatchAction {
+	return b.add(http.MethodPost, path, data, result, opts...)
+}
+
+// Put queues a PUT request with the given data
+func (b *Batch) Put(path string, data, result interface{}, opts ...*Options) *BatchAction {
+	return b.add(http.MethodPut, path, data, result, opts...)
+}
+
+// Delete queues a DELETE request
+func (b *Batch) Delete(path string, opts ...*Options) *BatchAction {
+	return b.add(http.MethodDelete, path, nil, nil, opts...)
+}
+
+func (b *Batch) add(method, path string, data, result interface{}, opts ...*Options) *BatchAction {
+	action := &BatchAction{
+		RelativePath: path,
+		Method:       strings.ToLower(method),
+		Data:         data,
+		result:       result,
+	}
+
+	options, err := b.client.mergeOptions(opts...)
+	if err != nil {
+		action.err = errors.Wrap(err, "unable to merge options")
+		action.done = true
+		return action
+	}
+	action.Options = options
+
+	if validator, ok := data.(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			action.err = err
+			action.done = true
+			return action
+		}
+	}
+
+	b.actions = append(b.actions, action)
+	return action
+}
+
+// FetchTask queues a request to load the full details for a task
+func (b *Batch) FetchTask(t *Task, opts ...*Options) *BatchAction {
+	return b.Get(fmt.Sprintf("/tasks/%s", t.ID), nil, t, opts...)
+}
+
+// CreateTask queues a request to create a new task. The returned task is
+// populated when the batch is executed.
+func (b *Batch) CreateTask(task *CreateTaskRequest, opts ...*Options) (*Task, *BatchAction) {
+	result := &Task{}
+	return result, b.Post("/tasks", task, result, opts...)
+}
+
+// UpdateTask queues a request to apply new values to a task
+func (b *Batch) UpdateTask(t *Task, update *UpdateTaskRequest, opts ...*Options) *BatchAction {
+	return b.Put(fmt.Sprintf("/tasks/%s", t.ID), update, t, opts...)
+}
+
+// DeleteTask queues a request to delete a task
+func (b *Batch) DeleteTask(t *Task, opts ...*Options) *BatchAction {
+	return b.Delete(fmt.Sprintf("/tasks/%s", t.ID), opts...)
+}
+
+// CreateComment queues a request to add a comment story to a task. The
+// returned story is populated when the batch is executed.
+func (b *Batch) CreateComment(t *Task, story *StoryBase, opts ...*Options) (*Story, *BatchAction) {
+	result := &Story{}
+	return result, b.Post(fmt.Sprintf("/tasks/%s/stories", t.ID), story, result, opts...)
+}
+
+// FetchProject queues a request to load the full details for a project
+func (b *Batch) FetchProject(p *Project, opts ...*Options) *BatchAction {
+	return b.Get(fmt.Sprintf("/projects/%s", p.ID), nil, p, opts...)
+}
+
+// UpdateProject queues a request to apply new values to a project
+func (b *Batch) UpdateProject(p *Project, request *UpdateProjectRequest, opts ...*Options) *BatchAction {
+	return b.Put(fmt.Sprintf("/projects/%s", p.ID), request, p, opts...)
+}
+
+type batchRequest struct {
+	Actions []*BatchAction `json:"actions"`
+}
+
+type batchResponse struct {
+	StatusCode int               `json:"status_code"`
+	Headers    map[string]string `json:"headers"`
+	Body       *Response         `json:"body"`
+}
+
+// Execute sends all queued actions, MaxBatchActions at a time. It returns an
+// error if a batch request as a whole failed; the results of individual
+// actions are reported through BatchAction.Err.
+//
+// The Enable, Disable and FastAPI header options of the actions, and any
+// options passed to Execute, are applied to each batch request.
+func (b *Batch) Execute(ctx context.Context, opts ...*Options) error {
+	b.client.trace("Executing batch of %d actions", len(b.actions))
+
+	for start := 0; start < len(b.actions); start += MaxBatchActions {
+		end := start + MaxBatchActions
+		if end > len(b.actions) {
+			end = len(b.actions)
+		}
+
+		if err := b.execute(ctx, b.actions[start:end], opts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *Batch) execute(ctx context.Context, actions []*BatchAction, opts ...*Options) error {
+	// Header options cannot be set per action, so they apply to the batch
+	batchOptions := &Options{}
+	for _, options := range append(opts, actionOptions(actions)...) {
+		if options == nil {
+			continue
+		}
+		batchOptions.FastAPI = batchOptions.FastAPI || options.FastAPI
+		batchOptions.Enable = appendFeatures(batchOptions.Enable, options.Enable...)
+		batchOptions.Disable = appendFeatures(batchOptions.Disable, options.Disable...)
+	}
+
+	// The actions may include creates, so the batch is not sent again after
+	// a server error even if the client retries other POST requests
+	var responses []*batchResponse
+	if err := b.client.send(ctx, http.MethodPost, "/batch", replayRateLimited, &batchRequest{Actions: actions}, &responses, batchOptions); err != nil {
+		return errors.Wrap(err, "Batch request")
+	}
+	if len(responses) != len(actions) {
+		return errors.Errorf("Batch request returned %d responses for %d actions", len(responses), len(actions))
+	}
+
+	for i, action := range actions {
+		action.done = true
+		action.err = responses[i].decode(action.result)
+		action.StatusCode = responses[i].StatusCode
+	}
+	return nil
+}
+
+func (r *batchResponse) decode(result interface{}) error {
+	if r.StatusCode < 200 || r.StatusCode >= 300 {
+		asanaError := &Error{
+			StatusCode: r.StatusCode,
+			Type:       http.StatusText(r.StatusCode),
+			Message:    "Unknown error",
+		}
+		if r.Body != nil && len(r.Body.Errors) > 0 {
+			asanaError = r.Body.Errors[0].withType(r.StatusCode, http.StatusText(r.StatusCode))
+		}
+		if retryAfter, ok := r.Headers["Retry-After"]; ok {
+			asanaError.RetryAfter = parseRetryAfter(retryAfter)
+		}
+		return asanaError
+	}
+
+	if result == nil || r.Body == nil || r.Body.Data == nil {
+		return nil
+	}
+	if err := json.Unmarshal(r.Body.Data, result); err != nil {
+		return errors.Wrap(err, "Unable to parse batch response data")
+	}
+	return nil
+}
+
+func actionOptions(actions []*BatchAction) []*Options {
+	var result []*Options
+	for _, action := range actions {
+		result = append(result, action.Options)
+	}
+	return result
+}
+
+// appendFeatures adds features which are not already in the list
+func appendFeatures(list []Feature, features ...Feature) []Feature {
+	for _, feature := range features {
+		found := false
+		for _, f := range list {
+			found = found || f == feature
+		}
+		if !found {
+			list = append(list, feature)
+		}
+	}
+	return list
+}
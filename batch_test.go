@@ -0,0 +1,92 @@
+package asana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestBatch(t *testing.T) {
+	var requests []*batchRequest
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/batch" {
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+		}
+		if r.Header.Get("Asana-Enable") != "new_sections" {
+			t.Errorf("Expected action header options to be applied, saw %q", r.Header.Get("Asana-Enable"))
+		}
+
+		req := &struct {
+			Data *batchRequest `json:"data"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Fatal(err)
+		}
+		requests = append(requests, req.Data)
+
+		var responses []*batchResponse
+		for _, action := range req.Data.Actions {
+			if action.RelativePath == "/tasks/404" {
+				responses = append(responses, &batchResponse{
+					StatusCode: 404,
+					Body:       &Response{Errors: []*Error{{Message: "Not found"}}},
+				})
+				continue
+			}
+			responses = append(responses, &batchResponse{
+				StatusCode: 200,
+				Body:       &Response{Data: json.RawMessage(fmt.Sprintf(`{"name": %q}`, action.RelativePath))},
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": responses})
+	})
+
+	batch := client.NewBatch()
+	var tasks []*Task
+	for i := 0; i < 12; i++ {
+		task := &Task{ID: fmt.Sprint(i)}
+		tasks = append(tasks, task)
+		batch.UpdateTask(task, &UpdateTaskRequest{}, &Options{Fields: []string{"name"}, Enable: []Feature{NewSections}})
+	}
+	missing := batch.FetchTask(&Task{ID: "404"})
+
+	if err := batch.Execute(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(requests) != 2 || len(requests[0].Actions) != 10 || len(requests[1].Actions) != 3 {
+		t.Fatalf("Expected the batch to be split into chunks of 10, saw %d requests", len(requests))
+	}
+	if a := requests[0].Actions[0]; a.Method != "put" || a.Options == nil || len(a.Options.Fields) != 1 {
+		t.Errorf("Unexpected action %+v", a)
+	}
+
+	for _, task := range tasks {
+		if task.Name != "/tasks/"+task.ID {
+			t.Errorf("Expected task %s to be updated from its response, saw %q", task.ID, task.Name)
+		}
+	}
+	if !IsNotFoundError(missing.Err()) {
+		t.Errorf("Expected a not found error for the missing task, saw %v", missing.Err())
+	}
+}
+
+func TestBatchServerError(t *testing.T) {
+	calls := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	client.RetryPolicy.RetryPosts = true
+
+	batch := client.NewBatch()
+	batch.Post("/tasks", &CreateTaskRequest{TaskBase: TaskBase{Name: "Task"}, Workspace: "1"}, nil)
+	if err := batch.Execute(context.Background()); err == nil {
+		t.Fatal("Expected the server error to be returned")
+	}
+	if calls != 1 {
+		t.Errorf("Expected the batch not to be sent again after a server error, saw %d calls", calls)
+	}
+}
@@ -26,6 +26,9 @@ var options struct {
 	Attach     string `long:"attach" description:"Attach a file to a task"`
 	AddSection string `long:"add-section" description:"Add a new section to a project"`
 
+	Schema string `long:"schema" description:"Plan changes to bring a workspace in line with a YAML or JSON schema"`
+	Apply  bool   `long:"apply" description:"Apply the schema plan after confirmation"`
+
 	Stories bool `long:"stories" description:"List stories for a task"`
 	Clean   bool `long:"clean" description:"Clean all stories from a task"`
 
@@ -64,6 +67,11 @@ func main() {
 
 	ctx := context.TODO()
 
+	if options.Schema != "" {
+		check(syncSchema(ctx, client))
+		return
+	}
+
 	// Load a task object
 	if options.Task == nil {
 
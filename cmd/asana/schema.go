@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	asana "github.com/incident-io/asana-go"
+	"github.com/incident-io/asana-go/asanaschema"
+)
+
+func syncSchema(ctx context.Context, client *asana.Client) error {
+	if len(options.Workspace) != 1 {
+		return errors.New("--schema needs exactly one --workspace")
+	}
+
+	f, err := os.Open(options.Schema)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	schema, err := asanaschema.Parse(f)
+	if err != nil {
+		return err
+	}
+
+	plan, err := schema.Plan(ctx, client, &asana.Workspace{ID: options.Workspace[0]})
+	if err != nil {
+		return err
+	}
+	fmt.Print(plan)
+	if plan.Empty() || !options.Apply {
+		return nil
+	}
+
+	// Only an explicit yes applies the plan
+	fmt.Print("\nApply these changes? Only 'yes' will be accepted: ")
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(answer) != "yes" {
+		fmt.Println("Cancelled.")
+		return nil
+	}
+
+	if err := plan.Apply(ctx, client); err != nil {
+		return err
+	}
+	fmt.Printf("Applied %d changes.\n", len(plan.Changes))
+	return nil
+}
@@ -0,0 +1,261 @@
+package asana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// FieldValue is a value to write to a custom field. Create values with the
+// New*Value constructors and write them with the SetCustomField methods on
+// the task and project requests, which check that the value suits the field.
+type FieldValue struct {
+	subtype FieldType
+	value   interface{}
+}
+
+// NewTextValue creates a value for a text custom field
+func NewTextValue(text string) *FieldValue {
+	return &FieldValue{subtype: Text, value: text}
+}
+
+// NewNumberValue creates a value for a number custom field
+func NewNumberValue(number float64) *FieldValue {
+	return &FieldValue{subtype: Number, value: number}
+}
+
+// NewEnumValue creates a value for an enum custom field from the ID of one of
+// its enum options
+func NewEnumValue(enumOptionID string) *FieldValue {
+	return &FieldValue{subtype: Enum, value: enumOptionID}
+}
+
+// NewMultiEnumValue creates a value for a multi-enum custom field from the
+// IDs of its enum options
+func NewMultiEnumValue(enumOptionIDs ...string) *FieldValue {
+	return &FieldValue{subtype: MultiEnum, value: append([]string{}, enumOptionIDs...)}
+}
+
+// NewDateValue creates a value for a date custom field
+func NewDateValue(date Date) *FieldValue {
+	return &FieldValue{subtype: DateField, value: &CustomFieldDate{Date: &date}}
+}
+
+// NewDateTimeValue creates a value for a date custom field which includes a
+// time
+func NewDateTimeValue(t time.Time) *FieldValue {
+	return &FieldValue{subtype: DateField, value: &CustomFieldDate{DateTime: &t}}
+}
+
+// NewPeopleValue creates a value for a people custom field from user IDs
+func NewPeopleValue(userIDs ...string) *FieldValue {
+	return &FieldValue{subtype: People, value: append([]string{}, userIDs...)}
+}
+
+// NoValue creates a value which clears a custom field of any type
+func NoValue() *FieldValue {
+	return &FieldValue{}
+}
+
+// Type returns the type of custom field the value is for, or an empty string
+// for NoValue
+func (v *FieldValue) Type() FieldType {
+	return v.subtype
+}
+
+// MarshalJSON encodes the value as the API expects it in a custom_fields
+// request map
+func (v *FieldValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.value)
+}
+
+// Validate checks that the value can be written to the custom field. The
+// field's type, precision and enum options must have been loaded, as they
+// are on the custom fields of a fetched task or project.
+func (v *FieldValue) Validate(field *CustomField) error {
+	if field == nil || field.ID == "" {
+		return errors.New("A custom field ID is required")
+	}
+	if field.Enabled != nil && !*field.Enabled {
+		return errors.Errorf("Custom field %q is disabled", field.Name)
+	}
+	if v.subtype == "" {
+		return nil
+	}
+	if field.ResourceSubtype == "" {
+		return errors.Errorf("The type of custom field %s is unknown; fetch the field before setting values", field.ID)
+	}
+	if field.ResourceSubtype != v.subtype {
+		return errors.Errorf("Cannot set a %s value on %s custom field %q", v.subtype, field.ResourceSubtype, field.Name)
+	}
+
+	switch v.subtype {
+	case Number:
+		return checkPrecision(field, v.value.(float64))
+	case Enum:
+		return checkEnumOptions(field, []string{v.value.(string)})
+	case MultiEnum:
+		return checkEnumOptions(field, v.value.([]string))
+	case People:
+		if len(v.value.([]string)) == 0 {
+			return errors.Errorf("No users given for custom field %q; use NoValue to clear it", field.Name)
+		}
+	}
+	return nil
+}
+
+func checkPrecision(field *CustomField, number float64) error {
+	if field.Precision == nil {
+		return nil
+	}
+
+	// Percentages are stored as fractions, so 25.5% has a precision of 1 but
+	// is stored with three decimal places
+	precision := *field.Precision
+	if field.Format == Percentage {
+		precision += 2
+	}
+
+	scale := math.Pow(10, float64(precision))
+	if rounded := math.Round(number*scale) / scale; math.Abs(rounded-number) > 1e-9*math.Max(1, math.Abs(number)) {
+		return errors.Errorf("Value %v has more than %d decimal places allowed by custom field %q", number, *field.Precision, field.Name)
+	}
+	return nil
+}
+
+func checkEnumOptions(field *CustomField, optionIDs []string) error {
+	if len(field.EnumOptions) == 0 {
+		return errors.Errorf("The enum options of custom field %q are unknown; fetch the field before setting values", field.Name)
+	}
+
+	for _, id := range optionIDs {
+		var option *EnumValue
+		for _, o := range field.EnumOptions {
+			if o.ID == id {
+				option = o
+			}
+		}
+
+		if option == nil {
+			var names []string
+			for _, o := range field.EnumOptions {
+				if o.Enabled {
+					names = append(names, fmt.Sprintf("%q (%s)", o.Name, o.ID))
+				}
+			}
+			return errors.Errorf("%s is not an option of custom field %q, expected one of %s", id, field.Name, strings.Join(names, ", "))
+		}
+		if !option.Enabled {
+			return errors.Errorf("Option %q of custom field %q is disabled", option.Name, field.Name)
+		}
+	}
+	return nil
+}
+
+// setCustomField validates a value and adds it to a custom_fields request map
+func setCustomField(values *map[string]interface{}, field *CustomField, value *FieldValue) error {
+	if err := value.Validate(field); err != nil {
+		return err
+	}
+	if *values == nil {
+		*values = map[string]interface{}{}
+	}
+	(*values)[field.ID] = value
+	return nil
+}
+
+// SetCustomField validates a value for a custom field and adds it to the
+// request
+func (t *CreateTaskRequest) SetCustomField(field *CustomField, value *FieldValue) error {
+	return setCustomField(&t.CustomFields, field, value)
+}
+
+// SetCustomField validates a value for a custom field and adds it to the
+// request
+func (t *UpdateTaskRequest) SetCustomField(field *CustomField, value *FieldValue) error {
+	return setCustomField(&t.CustomFields, field, value)
+}
+
+// SetCustomField validates a value for a custom field and adds it to the
+// request
+func (p *CreateProjectRequest) SetCustomField(field *CustomField, value *FieldValue) error {
+	return setCustomField(&p.CustomFields, field, value)
+}
+
+// SetCustomField validates a value for a custom field and adds it to the
+// request
+func (p *UpdateProjectRequest) SetCustomField(field *CustomField, value *FieldValue) error {
+	return setCustomField(&p.CustomFields, field, value)
+}
+
+// SetCustomField validates and writes a single custom field value on this
+// task. The field is usually taken from the task's own custom fields:
+//
+//	severity := task.CustomFieldByName("Severity")
+//	err := task.SetCustomField(ctx, client, &severity.CustomField, asana.NewEnumValue(optionID))
+func (t *Task) SetCustomField(ctx context.Context, client *Client, field *CustomField, value *FieldValue) error {
+	update := &UpdateTaskRequest{}
+	if err := update.SetCustomField(field, value); err != nil {
+		return err
+	}
+	return t.Update(ctx, client, update)
+}
+
+func findCustomField(values []*CustomFieldValue, match func(*CustomFieldValue) bool) *CustomFieldValue {
+	for _, value := range values {
+		if value != nil && match(value) {
+			return value
+		}
+	}
+	return nil
+}
+
+// CustomFieldByID returns the value of a custom field on this task, or nil
+// if the field is not on the task or the task's custom fields were not
+// loaded
+func (t *Task) CustomFieldByID(id string) *CustomFieldValue {
+	return findCustomField(t.CustomFields, func(v *CustomFieldValue) bool {
+		return v.ID == id
+	})
+}
+
+// CustomFieldByName returns the value of the custom field with the given
+// name on this task, or nil if there is none
+func (t *Task) CustomFieldByName(name string) *CustomFieldValue {
+	return findCustomField(t.CustomFields, func(v *CustomFieldValue) bool {
+		return v.Name == name
+	})
+}
+
+// CustomFieldByID returns the value of a custom field on this project, or nil
+// if the field is not on the project or the project's custom fields were not
+// loaded
+func (p *Project) CustomFieldByID(id string) *CustomFieldValue {
+	return findCustomField(p.CustomFields, func(v *CustomFieldValue) bool {
+		return v.ID == id
+	})
+}
+
+// CustomFieldByName returns the value of the custom field with the given
+// name on this project, or nil if there is none
+func (p *Project) CustomFieldByName(name string) *CustomFieldValue {
+	return findCustomField(p.CustomFields, func(v *CustomFieldValue) bool {
+		return v.Name == name
+	})
+}
+
+// EnumOptionByName returns the enum option of this custom field with the
+// given name, or nil if there is none
+func (f *CustomField) EnumOptionByName(name string) *EnumValue {
+	for _, option := range f.EnumOptions {
+		if option.Name == name {
+			return option
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package asana
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFieldValueValidate(t *testing.T) {
+	precision := 1
+	disabled := false
+	number := &CustomField{ID: "1", CustomFieldBase: CustomFieldBase{Name: "Cost", ResourceSubtype: Number, Precision: &precision}}
+	percent := &CustomField{ID: "2", CustomFieldBase: CustomFieldBase{Name: "Done", ResourceSubtype: Number, Precision: &precision, Format: Percentage}}
+	enum := &CustomField{
+		ID:              "3",
+		CustomFieldBase: CustomFieldBase{Name: "Severity", ResourceSubtype: Enum},
+		EnumOptions: []*EnumValue{
+			{ID: "31", EnumValueBase: EnumValueBase{Name: "Major"}, Enabled: true},
+			{ID: "32", EnumValueBase: EnumValueBase{Name: "Minor"}, Enabled: false},
+		},
+	}
+	off := &CustomField{ID: "4", CustomFieldBase: CustomFieldBase{Name: "Old", ResourceSubtype: Text, Enabled: &disabled}}
+
+	tests := []struct {
+		field *CustomField
+		value *FieldValue
+		err   string
+	}{
+		{number, NewNumberValue(1.5), ""},
+		{number, NewNumberValue(1.55), "decimal places"},
+		{percent, NewNumberValue(0.255), ""},
+		{percent, NewNumberValue(0.2555), "decimal places"},
+		{number, NewTextValue("1.5"), "Cannot set a text value"},
+		{enum, NewEnumValue("31"), ""},
+		{enum, NewEnumValue("33"), `33 is not an option of custom field "Severity", expected one of "Major" (31)`},
+		{enum, NewEnumValue("32"), "is disabled"},
+		{enum, NoValue(), ""},
+		{off, NewTextValue("x"), "is disabled"},
+		{&CustomField{ID: "5"}, NewTextValue("x"), "fetch the field"},
+	}
+
+	for _, test := range tests {
+		err := test.value.Validate(test.field)
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("Expected %v on %q to be valid, saw %v", test.value.value, test.field.Name, err)
+		case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+			t.Errorf("Expected %v on %q to fail with %q, saw %v", test.value.value, test.field.Name, test.err, err)
+		}
+	}
+}
+
+func TestSetCustomFieldEncoding(t *testing.T) {
+	request := &UpdateTaskRequest{}
+	fields := map[string]*FieldValue{
+		"1": NewTextValue("text"),
+		"2": NewMultiEnumValue("21", "22"),
+		"3": NewDateValue(Date(time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC))),
+		"4": NewPeopleValue("41"),
+		"5": NoValue(),
+	}
+	for id, value := range fields {
+		field := &CustomField{ID: id, CustomFieldBase: CustomFieldBase{ResourceSubtype: value.Type()}}
+		if value.Type() == MultiEnum {
+			field.EnumOptions = []*EnumValue{{ID: "21", Enabled: true}, {ID: "22", Enabled: true}}
+		}
+		if err := request.SetCustomField(field, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	encoded, err := json.Marshal(request.CustomFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"1":"text","2":["21","22"],"3":{"date":"2021-03-01"},"4":["41"],"5":null}`
+	if string(encoded) != expected {
+		t.Errorf("Expected %s, saw %s", expected, encoded)
+	}
+}
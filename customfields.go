@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
 )
 
 type EnumValue struct {
@@ -26,9 +29,12 @@ type FieldType string
 
 // FieldTypes for CustomField.Type field
 const (
-	Text   FieldType = "text"
-	Enum   FieldType = "enum"
-	Number FieldType = "number"
+	Text      FieldType = "text"
+	Enum      FieldType = "enum"
+	MultiEnum FieldType = "multi_enum"
+	Number    FieldType = "number"
+	DateField FieldType = "date"
+	People    FieldType = "people"
 )
 
 type LabelPosition string
@@ -90,7 +96,7 @@ type CustomFieldBase struct {
 	Precision *int `json:"precision,omitempty"`
 
 	// The type of the custom field. Must be one of the given values:
-	// 'text', 'enum', 'number'
+	// 'text', 'enum', 'multi_enum', 'number', 'date', 'people'
 	ResourceSubtype FieldType `json:"resource_subtype"`
 }
 
@@ -132,6 +138,30 @@ type AddCustomFieldSettingRequest struct {
 func (p *Project) AddCustomFieldSetting(ctx context.Context, client *Client, request *AddCustomFieldSettingRequest) (*CustomFieldSetting, error) {
 	client.trace("Attach custom field %q to project %q", request.CustomField, p.ID)
 
+	return addCustomFieldSetting(ctx, client, fmt.Sprintf("/projects/%s/addCustomFieldSetting", p.ID), request)
+}
+
+func (p *Project) RemoveCustomFieldSetting(ctx context.Context, client *Client, customFieldID string) error {
+	client.trace("Remove custom field %q from project %q", customFieldID, p.ID)
+
+	return removeCustomFieldSetting(ctx, client, fmt.Sprintf("/projects/%s/removeCustomFieldSetting", p.ID), customFieldID)
+}
+
+// AddCustomFieldSetting attaches a custom field to this portfolio
+func (p *Portfolio) AddCustomFieldSetting(ctx context.Context, client *Client, request *AddCustomFieldSettingRequest) (*CustomFieldSetting, error) {
+	client.trace("Attach custom field %q to portfolio %q", request.CustomField, p.ID)
+
+	return addCustomFieldSetting(ctx, client, fmt.Sprintf("/portfolios/%s/addCustomFieldSetting", p.ID), request)
+}
+
+// RemoveCustomFieldSetting detaches a custom field from this portfolio
+func (p *Portfolio) RemoveCustomFieldSetting(ctx context.Context, client *Client, customFieldID string) error {
+	client.trace("Remove custom field %q from portfolio %q", customFieldID, p.ID)
+
+	return removeCustomFieldSetting(ctx, client, fmt.Sprintf("/portfolios/%s/removeCustomFieldSetting", p.ID), customFieldID)
+}
+
+func addCustomFieldSetting(ctx context.Context, client *Client, path string, request *AddCustomFieldSettingRequest) (*CustomFieldSetting, error) {
 	// Custom request encoding
 	m := map[string]interface{}{}
 	m["custom_field"] = request.CustomField
@@ -150,19 +180,17 @@ func (p *Project) AddCustomFieldSetting(ctx context.Context, client *Client, req
 	}
 
 	result := &CustomFieldSetting{}
-	err := client.post(ctx, fmt.Sprintf("/projects/%s/addCustomFieldSetting", p.ID), m, result)
+	err := client.post(ctx, path, m, result)
 	return result, err
 }
 
-func (p *Project) RemoveCustomFieldSetting(ctx context.Context, client *Client, customFieldID string) error {
-	client.trace("Remove custom field %q from project %q", customFieldID, p.ID)
-
+func removeCustomFieldSetting(ctx context.Context, client *Client, path string, customFieldID string) error {
 	// Custom request encoding
 	m := map[string]interface{}{
 		"custom_field": customFieldID,
 	}
 
-	err := client.post(ctx, fmt.Sprintf("/projects/%s/removeCustomFieldSetting", p.ID), m, &json.RawMessage{})
+	err := client.post(ctx, path, m, &json.RawMessage{})
 	return err
 }
 
@@ -206,6 +234,29 @@ type CustomFieldValue struct {
 	// Custom fields of type enum will return an enum_value property
 	// containing an object that represents the selection of the enum value.
 	EnumValue *EnumValue `json:"enum_value,omitempty"`
+
+	// Custom fields of type multi_enum will return a multi_enum_values
+	// property containing the selected enum values.
+	MultiEnumValues []*EnumValue `json:"multi_enum_values,omitempty"`
+
+	// Custom fields of type date will return a date_value property
+	// containing the date, and the time if one was given.
+	DateValue *CustomFieldDate `json:"date_value,omitempty"`
+
+	// Custom fields of type people will return a people_value property
+	// containing the selected users.
+	PeopleValue []*User `json:"people_value,omitempty"`
+
+	// Read-only. A string representation of the value, as displayed in the
+	// Asana UI.
+	DisplayValue *string `json:"display_value,omitempty"`
+}
+
+// CustomFieldDate is the value of a date custom field. DateTime is only set
+// if the value includes a time.
+type CustomFieldDate struct {
+	Date     *Date      `json:"date,omitempty"`
+	DateTime *time.Time `json:"date_time,omitempty"`
 }
 
 // Fetch loads the full details for this CustomField
@@ -229,24 +280,223 @@ func (w *Workspace) CustomFields(ctx context.Context, client *Client, options ..
 // AllCustomFields repeatedly pages through all available custom fields in a workspace
 func (w *Workspace) AllCustomFields(ctx context.Context, client *Client, options ...*Options) ([]*CustomField, error) {
 	var allCustomFields []*CustomField
-	nextPage := &NextPage{}
 
-	var customFields []*CustomField
-	var err error
+	it := w.IterateCustomFields(ctx, client, options...)
+	for it.Next() {
+		allCustomFields = append(allCustomFields, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return allCustomFields, nil
+}
+
+// CustomFieldIterator iterates over a list of custom fields
+type CustomFieldIterator struct {
+	*Iterator
+}
+
+// Value returns the current custom field
+func (it *CustomFieldIterator) Value() *CustomField {
+	v, _ := it.Iterator.Value().(*CustomField)
+	return v
+}
+
+// IterateCustomFields returns an iterator over the custom fields in this workspace
+func (w *Workspace) IterateCustomFields(ctx context.Context, client *Client, options ...*Options) *CustomFieldIterator {
+	return &CustomFieldIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return w.CustomFields(ctx, client, options...)
+	}, options...)}
+}
+
+// UpdateCustomFieldRequest holds the new values for a custom field. Fields
+// which are left empty are not changed. The type of a custom field cannot be
+// changed.
+type UpdateCustomFieldRequest struct {
+	Name                    string        `json:"name,omitempty"`
+	Description             *string       `json:"description,omitempty"`
+	Precision               *int          `json:"precision,omitempty"`
+	Format                  Format        `json:"format,omitempty"`
+	CurrencyCode            string        `json:"currency_code,omitempty"`
+	CustomLabel             string        `json:"custom_label,omitempty"`
+	CustomLabelPosition     LabelPosition `json:"custom_label_position,omitempty"`
+	Enabled                 *bool         `json:"enabled,omitempty"`
+	HasNotificationsEnabled *bool         `json:"has_notifications_enabled,omitempty"`
+}
+
+// Update applies new values to a custom field
+func (f *CustomField) Update(ctx context.Context, client *Client, request *UpdateCustomFieldRequest, opts ...*Options) error {
+	client.trace("Updating custom field %q", f.Name)
+
+	err := client.put(ctx, fmt.Sprintf("/custom_fields/%s", f.ID), request, f, opts...)
+	return err
+}
+
+// Delete removes a custom field. Its values are removed from all tasks and
+// projects.
+func (f *CustomField) Delete(ctx context.Context, client *Client) error {
+	client.info("Deleting custom field %q", f.Name)
+
+	return client.delete(ctx, fmt.Sprintf("/custom_fields/%s", f.ID))
+}
+
+// CreateEnumOptionRequest describes a new option for an enum or multi-enum
+// custom field
+type CreateEnumOptionRequest struct {
+	Name    string `json:"name"`
+	Color   string `json:"color,omitempty"`
+	Enabled *bool  `json:"enabled,omitempty"`
+
+	// An existing option to insert the new option before or after. The new
+	// option is added at the end of the list if neither is given.
+	InsertBefore string `json:"insert_before,omitempty"`
+	InsertAfter  string `json:"insert_after,omitempty"`
+}
+
+// CreateEnumOption adds an option to an enum or multi-enum custom field. A
+// field may have at most 500 options. The new option is also added to the
+// field's EnumOptions.
+func (f *CustomField) CreateEnumOption(ctx context.Context, client *Client, request *CreateEnumOptionRequest) (*EnumValue, error) {
+	client.info("Creating enum option %q for custom field %q", request.Name, f.Name)
+
+	result := &EnumValue{}
+	err := client.post(ctx, fmt.Sprintf("/custom_fields/%s/enum_options", f.ID), request, result)
+	if err != nil {
+		return nil, err
+	}
+
+	f.EnumOptions = insertEnumOption(f.EnumOptions, result, request.InsertBefore, request.InsertAfter)
+	return result, nil
+}
+
+// InsertEnumOptionRequest moves an enum option before or after another
+// option of the same custom field. Exactly one of BeforeEnumOption and
+// AfterEnumOption must be given.
+type InsertEnumOptionRequest struct {
+	EnumOption       string `json:"enum_option"`
+	BeforeEnumOption string `json:"before_enum_option,omitempty"`
+	AfterEnumOption  string `json:"after_enum_option,omitempty"`
+}
+
+// Validate checks that exactly one position is given
+func (r *InsertEnumOptionRequest) Validate() error {
+	if (r.BeforeEnumOption == "") == (r.AfterEnumOption == "") {
+		return errors.New("Exactly one of BeforeEnumOption and AfterEnumOption is required")
+	}
+	return nil
+}
+
+// InsertEnumOption moves an enum option to a new position in the field's
+// list of options, and reorders the field's EnumOptions to match
+func (f *CustomField) InsertEnumOption(ctx context.Context, client *Client, request *InsertEnumOptionRequest) error {
+	client.trace("Moving enum option %q of custom field %q", request.EnumOption, f.Name)
 
-	for nextPage != nil {
-		page := &Options{
-			Limit:  50,
-			Offset: nextPage.Offset,
+	err := client.post(ctx, fmt.Sprintf("/custom_fields/%s/enum_options/insert", f.ID), request, &json.RawMessage{})
+	if err != nil {
+		return err
+	}
+
+	for _, option := range f.EnumOptions {
+		if option.ID == request.EnumOption {
+			f.EnumOptions = insertEnumOption(f.EnumOptions, option, request.BeforeEnumOption, request.AfterEnumOption)
+			break
 		}
+	}
+	return nil
+}
 
-		allOptions := append([]*Options{page}, options...)
-		customFields, nextPage, err = w.CustomFields(ctx, client, allOptions...)
-		if err != nil {
-			return nil, err
+// insertEnumOption places an option before or after another option in a
+// list, or at the end, removing it from its previous position
+func insertEnumOption(options []*EnumValue, option *EnumValue, before, after string) []*EnumValue {
+	result := make([]*EnumValue, 0, len(options)+1)
+	for _, o := range options {
+		if o.ID != option.ID {
+			result = append(result, o)
 		}
+	}
 
-		allCustomFields = append(allCustomFields, customFields...)
+	position := len(result)
+	for i, o := range result {
+		if o.ID == before {
+			position = i
+		} else if o.ID == after {
+			position = i + 1
+		}
 	}
-	return allCustomFields, nil
+
+	result = append(result, nil)
+	copy(result[position+1:], result[position:])
+	result[position] = option
+	return result
+}
+
+// UpdateEnumOptionRequest holds the new values for an enum option. Fields
+// which are left empty are not changed.
+type UpdateEnumOptionRequest struct {
+	Name  string `json:"name,omitempty"`
+	Color string `json:"color,omitempty"`
+
+	// Disabled options remain on the tasks which have them, but cannot be
+	// selected for other tasks
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// Update renames, recolors, enables or disables an enum option
+func (e *EnumValue) Update(ctx context.Context, client *Client, request *UpdateEnumOptionRequest, opts ...*Options) error {
+	client.trace("Updating enum option %q", e.Name)
+
+	err := client.put(ctx, fmt.Sprintf("/enum_options/%s", e.ID), request, e, opts...)
+	return err
+}
+
+// ListCustomFieldSettings returns the custom field settings on this project.
+// Unlike the CustomFieldSettings field, which is only populated when the
+// project is fetched, this can page through any number of settings.
+func (p *Project) ListCustomFieldSettings(ctx context.Context, client *Client, options ...*Options) ([]*CustomFieldSetting, *NextPage, error) {
+	client.trace("Listing custom field settings in project %q", p.Name)
+	var result []*CustomFieldSetting
+
+	// Make the request
+	nextPage, err := client.Get(ctx, fmt.Sprintf("/projects/%s/custom_field_settings", p.ID), nil, &result, options...)
+	return result, nextPage, err
+}
+
+// ListCustomFieldSettings returns the custom field settings on this
+// portfolio. Unlike the CustomFieldSettings field, which is only populated
+// when the portfolio is fetched, this can page through any number of
+// settings.
+func (p *Portfolio) ListCustomFieldSettings(ctx context.Context, client *Client, options ...*Options) ([]*CustomFieldSetting, *NextPage, error) {
+	client.trace("Listing custom field settings in portfolio %s", p.ID)
+	var result []*CustomFieldSetting
+
+	// Make the request
+	nextPage, err := client.Get(ctx, fmt.Sprintf("/portfolios/%s/custom_field_settings", p.ID), nil, &result, options...)
+	return result, nextPage, err
+}
+
+// CustomFieldSettingIterator iterates over a list of custom field settings
+type CustomFieldSettingIterator struct {
+	*Iterator
+}
+
+// Value returns the current custom field setting
+func (it *CustomFieldSettingIterator) Value() *CustomFieldSetting {
+	v, _ := it.Iterator.Value().(*CustomFieldSetting)
+	return v
+}
+
+// IterateCustomFieldSettings returns an iterator over the custom field
+// settings on this project
+func (p *Project) IterateCustomFieldSettings(ctx context.Context, client *Client, options ...*Options) *CustomFieldSettingIterator {
+	return &CustomFieldSettingIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return p.ListCustomFieldSettings(ctx, client, options...)
+	}, options...)}
+}
+
+// IterateCustomFieldSettings returns an iterator over the custom field
+// settings on this portfolio
+func (p *Portfolio) IterateCustomFieldSettings(ctx context.Context, client *Client, options ...*Options) *CustomFieldSettingIterator {
+	return &CustomFieldSettingIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return p.ListCustomFieldSettings(ctx, client, options...)
+	}, options...)}
 }
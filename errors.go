@@ -23,17 +23,31 @@ func (r *Response) Error(resp *http.Response, requestID xid.ID) error {
 		}
 	}
 
-	retryHeader := resp.Header.Get("Retry-After")
-	if retryHeader != "" {
-		retryAfter, err := strconv.ParseInt(retryHeader, 10, 64)
-		if err != nil {
-			asanaError.RetryAfter = time.Duration(retryAfter) * time.Second
-		}
-	}
+	asanaError.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+	asanaError.Sync = r.Sync
 
 	return asanaError
 }
 
+// parseRetryAfter decodes a Retry-After header, which may either be a number
+// of seconds or an HTTP date
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.ParseInt(header, 10, 64); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(header); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
 // Error is an error message returned by the API
 type Error struct {
 	StatusCode int
@@ -43,6 +57,13 @@ type Error struct {
 	Help       string        `json:"help"`
 	RetryAfter time.Duration `json:"-"`
 	RequestID  string        `json:"-"`
+
+	// The number of times the request was sent before giving up
+	Attempts int `json:"-"`
+
+	// A fresh sync token returned by the events API along with a 412
+	// Precondition Failed response
+	Sync string `json:"-"`
 }
 
 func (err Error) Error() string {
@@ -94,8 +115,22 @@ func IsRateLimited(err error) bool {
 	return false
 }
 
-// IsRateLimited returns true if the error was a rate limit error
+// IsInvalidSyncToken checks if the provided error represents a 412 response
+// from the events API, meaning the sync token was missing or has expired
+func IsInvalidSyncToken(err error) bool {
+	if e, ok := IsAsanaError(err); ok {
+		return e.StatusCode == 412
+	}
+	return false
+}
+
+// IsPayloadTooLarge returns true if the request was too large for Asana to
+// accept, including attachments rejected before upload for being larger than
+// MaxAttachmentSize
 func IsPayloadTooLarge(err error) bool {
+	if _, ok := errors.Cause(err).(*AttachmentTooLargeError); ok {
+		return true
+	}
 	if e, ok := IsAsanaError(err); ok {
 		return e.StatusCode == 413
 	}
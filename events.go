@@ -1,8 +1,12 @@
 package asana
 
 import (
+	"context"
 	"encoding/json"
+	"sync"
 	"time"
+
+	"github.com/pkg/errors"
 )
 
 type EventData struct {
@@ -43,3 +47,191 @@ func (e Event) String() string {
 	}
 	return string(data)
 }
+
+// EventSync describes the position of an events request in the stream of
+// events for a resource
+type EventSync struct {
+	// The sync token to use in the next request for events on the resource
+	Token string
+
+	// True if more events are available immediately
+	HasMore bool
+}
+
+type eventsQuery struct {
+	Resource string `url:"resource"`
+	Sync     string `url:"sync,omitempty"`
+}
+
+// Events returns the events which have occurred on a resource since the
+// sync token was created.
+//
+// If the sync token is empty or has expired, the API responds with 412
+// Precondition Failed; IsInvalidSyncToken will return true for the error and
+// the returned EventSync will hold a fresh token to use for subsequent
+// requests. Any events between the expired token and the new one are lost.
+func (c *Client) Events(ctx context.Context, resourceID, syncToken string, opts ...*Options) ([]Event, *EventSync, error) {
+	c.trace("Listing events for resource %s", resourceID)
+
+	var result []Event
+
+	query := &eventsQuery{
+		Resource: resourceID,
+		Sync:     syncToken,
+	}
+
+	resp, err := c.get(ctx, "/events", query, &result, opts...)
+	if err != nil {
+		if e, ok := IsAsanaError(err); ok && e.Sync != "" {
+			return nil, &EventSync{Token: e.Sync}, err
+		}
+		return nil, nil, err
+	}
+
+	return result, &EventSync{Token: resp.Sync, HasMore: resp.HasMore}, nil
+}
+
+// SyncTokenStore persists the sync token for each resource watched by an
+// EventStream so that streams can resume where they left off
+type SyncTokenStore interface {
+	// LoadSyncToken returns the last saved token for the resource, or an
+	// empty string if there is none.
+	LoadSyncToken(ctx context.Context, resourceID string) (string, error)
+
+	// SaveSyncToken records the latest token for the resource
+	SaveSyncToken(ctx context.Context, resourceID, token string) error
+}
+
+// MemorySyncTokenStore is a SyncTokenStore which keeps tokens in memory
+type MemorySyncTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+// LoadSyncToken implements SyncTokenStore
+func (s *MemorySyncTokenStore) LoadSyncToken(ctx context.Context, resourceID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.tokens[resourceID], nil
+}
+
+// SaveSyncToken implements SyncTokenStore
+func (s *MemorySyncTokenStore) SaveSyncToken(ctx context.Context, resourceID, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tokens == nil {
+		s.tokens = map[string]string{}
+	}
+	s.tokens[resourceID] = token
+	return nil
+}
+
+// DefaultEventPollInterval is how long an EventStream waits between polls
+// when no more events are available
+const DefaultEventPollInterval = 10 * time.Second
+
+// EventStream repeatedly polls the events API for a resource and delivers
+// the events on a channel. It is an alternative to webhooks for environments
+// which cannot receive requests from Asana.
+type EventStream struct {
+	// Required: the resource to watch for events
+	Resource string
+
+	// Where sync tokens are persisted between polls. Defaults to an in-memory
+	// store, in which case a restarted stream will miss any events which
+	// occurred while it was stopped.
+	Store SyncTokenStore
+
+	// How long to wait between polls when no more events are available.
+	// Defaults to DefaultEventPollInterval.
+	PollInterval time.Duration
+
+	// OnResync, if set, is called when a saved sync token has expired and
+	// events may have been missed. Applications can use this to reload the
+	// full state of the resource. Returning an error stops the stream.
+	OnResync func(ctx context.Context) error
+
+	client *Client
+}
+
+// NewEventStream creates an EventStream for the given resource
+func (c *Client) NewEventStream(resourceID string, store SyncTokenStore) *EventStream {
+	return &EventStream{
+		Resource: resourceID,
+		Store:    store,
+		client:   c,
+	}
+}
+
+// Run polls for events and sends them on the provided channel until the
+// context is cancelled or an unrecoverable error occurs. Events are delivered
+// at least once: the sync token is saved after each batch of events has been
+// sent on the channel.
+func (s *EventStream) Run(ctx context.Context, events chan<- Event) error {
+	if s.Store == nil {
+		s.Store = &MemorySyncTokenStore{}
+	}
+	interval := s.PollInterval
+	if interval <= 0 {
+		interval = DefaultEventPollInterval
+	}
+
+	token, err := s.Store.LoadSyncToken(ctx, s.Resource)
+	if err != nil {
+		return errors.Wrap(err, "Load sync token")
+	}
+
+	for {
+		result, cursor, err := s.client.Events(ctx, s.Resource, token)
+		switch {
+		case err == nil:
+		case IsInvalidSyncToken(err) && cursor != nil:
+			s.client.info("Sync token for resource %s is invalid, resyncing", s.Resource)
+			expired := token != ""
+
+			token = cursor.Token
+			if err := s.Store.SaveSyncToken(ctx, s.Resource, token); err != nil {
+				return errors.Wrap(err, "Save sync token")
+			}
+
+			if expired && s.OnResync != nil {
+				if err := s.OnResync(ctx); err != nil {
+					return err
+				}
+			}
+			continue
+		case IsRecoverableError(err) || IsRateLimited(err):
+			s.client.info("Polling events for resource %s failed: %v", s.Resource, err)
+			if err := sleep(ctx, interval); err != nil {
+				return err
+			}
+			continue
+		default:
+			return err
+		}
+
+		for _, event := range result {
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if cursor.Token != "" && cursor.Token != token {
+			token = cursor.Token
+			if err := s.Store.SaveSyncToken(ctx, s.Resource, token); err != nil {
+				return errors.Wrap(err, "Save sync token")
+			}
+		}
+
+		if cursor.HasMore {
+			continue
+		}
+		if err := sleep(ctx, interval); err != nil {
+			return err
+		}
+	}
+}
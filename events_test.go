@@ -0,0 +1,67 @@
+package asana
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestEventStream(t *testing.T) {
+	var syncTokens []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/events" || r.URL.Query().Get("resource") != "123" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+
+		token := r.URL.Query().Get("sync")
+		syncTokens = append(syncTokens, token)
+		switch token {
+		case "", "expired":
+			w.WriteHeader(http.StatusPreconditionFailed)
+			w.Write([]byte(`{"errors": [{"message": "Sync token invalid or too old"}], "sync": "a"}`))
+		case "a":
+			w.Write([]byte(`{"data": [{"action": "added"}], "sync": "b", "has_more": true}`))
+		default:
+			w.Write([]byte(`{"data": [{"action": "changed"}], "sync": "c", "has_more": false}`))
+		}
+	})
+
+	store := &MemorySyncTokenStore{}
+	store.SaveSyncToken(context.Background(), "123", "expired")
+
+	resynced := false
+	stream := client.NewEventStream("123", store)
+	stream.PollInterval = time.Hour
+	stream.OnResync = func(ctx context.Context) error {
+		resynced = true
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan Event)
+	done := make(chan error)
+	go func() {
+		done <- stream.Run(ctx, events)
+	}()
+
+	for _, action := range []string{"added", "changed"} {
+		if event := <-events; event.Action != action {
+			t.Errorf("Expected %q event, saw %q", action, event.Action)
+		}
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Expected the stream to stop with the context, saw %v", err)
+	}
+
+	if !resynced {
+		t.Error("Expected an expired sync token to trigger a resync")
+	}
+	if token, _ := store.LoadSyncToken(context.Background(), "123"); token != "c" {
+		t.Errorf("Expected the latest sync token to be saved, saw %q", token)
+	}
+	if len(syncTokens) != 3 || syncTokens[1] != "a" || syncTokens[2] != "b" {
+		t.Errorf("Unexpected sync tokens %q", syncTokens)
+	}
+}
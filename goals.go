@@ -0,0 +1,612 @@
+package asana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// GoalStatus is the progress of a goal towards its target, or its outcome
+// once the time period has ended
+type GoalStatus string
+
+// Statuses of a goal
+const (
+	GoalOnTrack  GoalStatus = "green"
+	GoalAtRisk   GoalStatus = "yellow"
+	GoalOffTrack GoalStatus = "red"
+	GoalAchieved GoalStatus = "achieved"
+	GoalPartial  GoalStatus = "partial"
+	GoalMissed   GoalStatus = "missed"
+	GoalDropped  GoalStatus = "dropped"
+)
+
+// GoalBase contains the parts of Goal which can be set when it is created
+// or updated
+type GoalBase struct {
+	// The name of the goal.
+	Name string `json:"name,omitempty"`
+
+	// Free-form textual information associated with the goal.
+	Notes string `json:"notes,omitempty"`
+
+	// The notes of the goal with formatting as HTML.
+	HTMLNotes string `json:"html_notes,omitempty"`
+
+	// The day on which the goal is due.
+	DueOn *Date `json:"due_on,omitempty"`
+
+	// The day on which work on the goal begins.
+	StartOn *Date `json:"start_on,omitempty"`
+
+	// True if the goal is set for the whole workspace rather than a team.
+	IsWorkspaceLevel bool `json:"is_workspace_level,omitempty"`
+}
+
+// CreateGoalRequest represents a request to create a new goal
+type CreateGoalRequest struct {
+	GoalBase
+
+	// Required: The workspace to create the goal in.
+	Workspace string `json:"workspace"`
+
+	// The team the goal belongs to, unless it is workspace level.
+	Team string `json:"team,omitempty"`
+
+	// The time period the goal is set for.
+	TimePeriod string `json:"time_period,omitempty"`
+
+	// The user responsible for the goal.
+	Owner string `json:"owner,omitempty"`
+
+	// Users to add as followers of the goal.
+	Followers []string `json:"followers,omitempty"`
+}
+
+// Validate checks that the request has the required fields
+func (r *CreateGoalRequest) Validate() error {
+	if r.Name == "" {
+		return errors.New("Missing goal name")
+	}
+	if r.Workspace == "" {
+		return errors.New("A workspace is required for a goal")
+	}
+	return nil
+}
+
+// UpdateGoalRequest represents a request to update a goal
+type UpdateGoalRequest struct {
+	GoalBase
+
+	Team       string     `json:"team,omitempty"`
+	TimePeriod string     `json:"time_period,omitempty"`
+	Owner      string     `json:"owner,omitempty"`
+	Status     GoalStatus `json:"status,omitempty"`
+}
+
+// MetricUnit is the unit a goal's metric is measured in
+type MetricUnit string
+
+// Units of goal metrics
+const (
+	MetricNone       MetricUnit = "none"
+	MetricCurrency   MetricUnit = "currency"
+	MetricPercentage MetricUnit = "percentage"
+)
+
+// ProgressSource is where the current value of a goal's metric comes from
+type ProgressSource string
+
+// Sources of goal progress
+const (
+	ProgressManual                     ProgressSource = "manual"
+	ProgressSubgoals                   ProgressSource = "subgoal_progress"
+	ProgressProjectTaskCompletion      ProgressSource = "project_task_completion"
+	ProgressProjectMilestoneCompletion ProgressSource = "project_milestone_completion"
+	ProgressExternal                   ProgressSource = "external"
+)
+
+// GoalMetric is the number a goal measures progress with
+type GoalMetric struct {
+	// Read-only. Globally unique ID of the object
+	ID string `json:"gid,omitempty"`
+
+	// The number of decimal places shown for the metric's values.
+	Precision int `json:"precision"`
+
+	// The unit the metric is measured in.
+	Unit MetricUnit `json:"unit,omitempty"`
+
+	// The ISO 4217 currency code of the metric, when the unit is currency.
+	CurrencyCode string `json:"currency_code,omitempty"`
+
+	// The value of the metric when the goal was set.
+	InitialNumberValue float64 `json:"initial_number_value"`
+
+	// The value of the metric which achieves the goal.
+	TargetNumberValue float64 `json:"target_number_value"`
+
+	// Read-only. The current value of the metric.
+	CurrentNumberValue float64 `json:"current_number_value"`
+
+	// Read-only. The current value formatted for display.
+	CurrentDisplayValue string `json:"current_display_value,omitempty"`
+
+	// Where the current value of the metric comes from.
+	ProgressSource ProgressSource `json:"progress_source,omitempty"`
+}
+
+// Goal is an objective set for a workspace or team, optionally measured by a
+// metric. Projects, portfolios and other goals may support a goal.
+type Goal struct {
+	// Read-only. Globally unique ID of the object
+	ID string `json:"gid,omitempty"`
+
+	GoalBase
+
+	// The progress or outcome of the goal.
+	Status GoalStatus `json:"status,omitempty"`
+
+	// The user responsible for the goal.
+	Owner *User `json:"owner,omitempty"`
+
+	// Read-only. The workspace the goal is in.
+	Workspace *Workspace `json:"workspace,omitempty"`
+
+	// The team the goal belongs to, unless it is workspace level.
+	Team *Team `json:"team,omitempty"`
+
+	// The time period the goal is set for.
+	TimePeriod *TimePeriod `json:"time_period,omitempty"`
+
+	// Read-only. The metric progress is measured with, if any. Use
+	// SetMetric to change it.
+	Metric *GoalMetric `json:"metric,omitempty"`
+
+	// Read-only. Users following the goal. Use AddFollowers and
+	// RemoveFollowers to change them.
+	Followers []*User `json:"followers,omitempty"`
+
+	// Read-only. The most recent status update posted on the goal.
+	CurrentStatusUpdate *StatusUpdate `json:"current_status_update,omitempty"`
+
+	// Read-only. True if the goal is liked by the authorized user.
+	Liked bool `json:"liked,omitempty"`
+
+	// Read-only. The number of users who like the goal.
+	NumLikes int32 `json:"num_likes,omitempty"`
+}
+
+// CreateGoal adds a new goal to a workspace
+func (c *Client) CreateGoal(ctx context.Context, goal *CreateGoalRequest) (*Goal, error) {
+	c.info("Creating goal %q", goal.Name)
+
+	result := &Goal{}
+	err := c.post(ctx, "/goals", goal, result)
+	return result, err
+}
+
+// Fetch loads the full details for this Goal
+func (g *Goal) Fetch(ctx context.Context, client *Client, options ...*Options) error {
+	client.trace("Loading goal details for %q", g.Name)
+
+	_, err := client.Get(ctx, fmt.Sprintf("/goals/%s", g.ID), nil, g, options...)
+	return err
+}
+
+// Update changes the fields given in the request, updating this Goal with
+// the result
+func (g *Goal) Update(ctx context.Context, client *Client, request *UpdateGoalRequest, options ...*Options) error {
+	client.trace("Update goal %q", g.Name)
+
+	return client.put(ctx, fmt.Sprintf("/goals/%s", g.ID), request, g, options...)
+}
+
+// Delete removes this goal
+func (g *Goal) Delete(ctx context.Context, client *Client) error {
+	client.info("Deleting goal %q", g.ID)
+
+	return client.delete(ctx, fmt.Sprintf("/goals/%s", g.ID))
+}
+
+// SetMetricRequest defines the metric set by Goal.SetMetric
+type SetMetricRequest struct {
+	Precision          int            `json:"precision"`
+	Unit               MetricUnit     `json:"unit"`
+	CurrencyCode       string         `json:"currency_code,omitempty"`
+	InitialNumberValue float64        `json:"initial_number_value"`
+	TargetNumberValue  float64        `json:"target_number_value"`
+	ProgressSource     ProgressSource `json:"progress_source,omitempty"`
+}
+
+// Validate checks that the request is consistent
+func (r *SetMetricRequest) Validate() error {
+	if r.Unit == "" {
+		return errors.New("A unit is required for a goal metric")
+	}
+	if r.Unit == MetricCurrency && r.CurrencyCode == "" {
+		return errors.New("A currency code is required for a currency metric")
+	}
+	return nil
+}
+
+// SetMetric sets or replaces the metric this goal is measured with
+func (g *Goal) SetMetric(ctx context.Context, client *Client, request *SetMetricRequest) error {
+	client.trace("Setting metric on goal %q", g.ID)
+
+	return client.post(ctx, fmt.Sprintf("/goals/%s/setMetric", g.ID), request, g)
+}
+
+// UpdateMetricCurrentValue records the current value of this goal's metric,
+// which must have a manual progress source
+func (g *Goal) UpdateMetricCurrentValue(ctx context.Context, client *Client, value float64) error {
+	client.trace("Setting current metric value on goal %q to %v", g.ID, value)
+
+	m := map[string]interface{}{
+		"current_number_value": value,
+	}
+
+	return client.post(ctx, fmt.Sprintf("/goals/%s/setMetricCurrentValue", g.ID), m, g)
+}
+
+// AddFollowers adds users to the followers of this goal
+func (g *Goal) AddFollowers(ctx context.Context, client *Client, userIDs ...string) error {
+	return g.changeFollowers(ctx, client, "addFollowers", userIDs)
+}
+
+// RemoveFollowers removes users from the followers of this goal
+func (g *Goal) RemoveFollowers(ctx context.Context, client *Client, userIDs ...string) error {
+	return g.changeFollowers(ctx, client, "removeFollowers", userIDs)
+}
+
+func (g *Goal) changeFollowers(ctx context.Context, client *Client, action string, userIDs []string) error {
+	client.trace("%s %v on goal %q", action, userIDs, g.ID)
+
+	m := map[string]interface{}{
+		"followers": userIDs,
+	}
+
+	return client.post(ctx, fmt.Sprintf("/goals/%s/%s", g.ID, action), m, g)
+}
+
+// CreateStatusUpdate posts a status update on this goal
+func (g *Goal) CreateStatusUpdate(ctx context.Context, client *Client, update *StatusUpdateBase) (*StatusUpdate, error) {
+	return client.CreateStatusUpdate(ctx, &CreateStatusUpdateRequest{StatusUpdateBase: *update, Parent: g.ID})
+}
+
+// StatusUpdates returns the compact records for the status updates on this
+// goal, newest first
+func (g *Goal) StatusUpdates(ctx context.Context, client *Client, options ...*Options) ([]*StatusUpdate, *NextPage, error) {
+	return client.StatusUpdates(ctx, g.ID, options...)
+}
+
+// IterateStatusUpdates returns an iterator over the status updates on this
+// goal
+func (g *Goal) IterateStatusUpdates(ctx context.Context, client *Client, options ...*Options) *StatusUpdateIterator {
+	return &StatusUpdateIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return g.StatusUpdates(ctx, client, options...)
+	}, options...)}
+}
+
+// Relationships
+
+// GoalRelationshipType is the kind of resource supporting a goal
+type GoalRelationshipType string
+
+// Kinds of goal relationship
+const (
+	SupportingProject   GoalRelationshipType = "supporting_project"
+	SupportingPortfolio GoalRelationshipType = "supporting_portfolio"
+	Subgoal             GoalRelationshipType = "subgoal"
+)
+
+// GoalRelationshipResource is the project, portfolio or goal in a goal
+// relationship
+type GoalRelationshipResource struct {
+	ID           string `json:"gid"`
+	ResourceType string `json:"resource_type"`
+	Name         string `json:"name,omitempty"`
+}
+
+// GoalRelationship links a goal to a project, portfolio or subgoal which
+// contributes to it
+type GoalRelationship struct {
+	// Read-only. Globally unique ID of the object
+	ID string `json:"gid,omitempty"`
+
+	// Read-only. The kind of supporting resource.
+	ResourceSubtype GoalRelationshipType `json:"resource_subtype,omitempty"`
+
+	// Read-only. The goal being supported.
+	SupportedGoal *Goal `json:"supported_goal,omitempty"`
+
+	// Read-only. The project, portfolio or goal supporting the goal.
+	SupportingResource *GoalRelationshipResource `json:"supporting_resource,omitempty"`
+
+	// How much the supporting resource's progress counts towards the goal,
+	// between 0 and 1.
+	ContributionWeight float64 `json:"contribution_weight"`
+}
+
+// AddSupportingRelationshipRequest defines the relationship added by
+// Goal.AddSupportingRelationship
+type AddSupportingRelationshipRequest struct {
+	// Required: The project, portfolio or goal which supports the goal.
+	SupportingResource string
+
+	// Subgoals only: the position to insert at, given by another subgoal.
+	// At most one of these may be set.
+	InsertBefore string
+	InsertAfter  string
+
+	// How much the resource's progress counts towards the goal. Asana's
+	// default is used if this is nil.
+	ContributionWeight *float64
+}
+
+// Validate checks that the request is consistent
+func (r *AddSupportingRelationshipRequest) Validate() error {
+	if r.SupportingResource == "" {
+		return errors.New("A supporting resource is required")
+	}
+	if r.InsertBefore != "" && r.InsertAfter != "" {
+		return errors.New("Only one of InsertBefore and InsertAfter may be set")
+	}
+	return nil
+}
+
+// AddSupportingRelationship adds a project, portfolio or subgoal as
+// supporting this goal
+func (g *Goal) AddSupportingRelationship(ctx context.Context, client *Client, request *AddSupportingRelationshipRequest) (*GoalRelationship, error) {
+	client.info("Adding %q as supporting goal %q", request.SupportingResource, g.ID)
+
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Custom request encoding
+	m := map[string]interface{}{
+		"supporting_resource": request.SupportingResource,
+	}
+	if request.InsertBefore != "" {
+		m["insert_before"] = request.InsertBefore
+	}
+	if request.InsertAfter != "" {
+		m["insert_after"] = request.InsertAfter
+	}
+	if request.ContributionWeight != nil {
+		m["contribution_weight"] = *request.ContributionWeight
+	}
+
+	result := &GoalRelationship{}
+	err := client.post(ctx, fmt.Sprintf("/goals/%s/addSupportingRelationship", g.ID), m, result)
+	return result, err
+}
+
+// RemoveSupportingRelationship stops a project, portfolio or subgoal from
+// supporting this goal
+func (g *Goal) RemoveSupportingRelationship(ctx context.Context, client *Client, supportingResource string) error {
+	client.info("Removing %q from supporting goal %q", supportingResource, g.ID)
+
+	m := map[string]interface{}{
+		"supporting_resource": supportingResource,
+	}
+
+	return client.post(ctx, fmt.Sprintf("/goals/%s/removeSupportingRelationship", g.ID), m, &json.RawMessage{})
+}
+
+type goalRelationshipsQuery struct {
+	SupportedGoal   string `url:"supported_goal"`
+	ResourceSubtype string `url:"resource_subtype,omitempty"`
+}
+
+// Relationships returns the relationships of the resources supporting this
+// goal. If kind is not empty, only relationships of that kind are returned.
+func (g *Goal) Relationships(ctx context.Context, client *Client, kind GoalRelationshipType, options ...*Options) ([]*GoalRelationship, *NextPage, error) {
+	client.trace("Listing relationships of goal %q", g.ID)
+
+	var result []*GoalRelationship
+
+	// Make the request
+	query := &goalRelationshipsQuery{SupportedGoal: g.ID, ResourceSubtype: string(kind)}
+	nextPage, err := client.Get(ctx, "/goal_relationships", query, &result, options...)
+	return result, nextPage, err
+}
+
+// ParentGoals returns the goals which this goal supports
+func (g *Goal) ParentGoals(ctx context.Context, client *Client, options ...*Options) ([]*Goal, *NextPage, error) {
+	client.trace("Listing parent goals of goal %q", g.ID)
+
+	var result []*Goal
+
+	// Make the request
+	nextPage, err := client.Get(ctx, fmt.Sprintf("/goals/%s/parentGoals", g.ID), nil, &result, options...)
+	return result, nextPage, err
+}
+
+// IterateParentGoals returns an iterator over the goals which this goal
+// supports
+func (g *Goal) IterateParentGoals(ctx context.Context, client *Client, options ...*Options) *GoalIterator {
+	return &GoalIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return g.ParentGoals(ctx, client, options...)
+	}, options...)}
+}
+
+// GoalRelationshipIterator iterates over a list of goal relationships
+type GoalRelationshipIterator struct {
+	*Iterator
+}
+
+// Value returns the current goal relationship
+func (it *GoalRelationshipIterator) Value() *GoalRelationship {
+	v, _ := it.Iterator.Value().(*GoalRelationship)
+	return v
+}
+
+// IterateRelationships returns an iterator over the relationships of the
+// resources supporting this goal. If kind is not empty, only relationships
+// of that kind are returned.
+func (g *Goal) IterateRelationships(ctx context.Context, client *Client, kind GoalRelationshipType, options ...*Options) *GoalRelationshipIterator {
+	return &GoalRelationshipIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return g.Relationships(ctx, client, kind, options...)
+	}, options...)}
+}
+
+// Fetch loads the full details for this GoalRelationship
+func (r *GoalRelationship) Fetch(ctx context.Context, client *Client, options ...*Options) error {
+	client.trace("Loading details for goal relationship %q", r.ID)
+
+	_, err := client.Get(ctx, fmt.Sprintf("/goal_relationships/%s", r.ID), nil, r, options...)
+	return err
+}
+
+// SetContributionWeight changes how much the supporting resource's progress
+// counts towards the goal
+func (r *GoalRelationship) SetContributionWeight(ctx context.Context, client *Client, weight float64) error {
+	client.trace("Setting contribution weight of goal relationship %q to %v", r.ID, weight)
+
+	m := map[string]interface{}{
+		"contribution_weight": weight,
+	}
+
+	return client.put(ctx, fmt.Sprintf("/goal_relationships/%s", r.ID), m, r)
+}
+
+// Listing goals
+
+type goalsQuery struct {
+	Workspace   string `url:"workspace,omitempty"`
+	Team        string `url:"team,omitempty"`
+	Portfolio   string `url:"portfolio,omitempty"`
+	Project     string `url:"project,omitempty"`
+	TimePeriods string `url:"time_periods,omitempty"`
+}
+
+func (c *Client) goals(ctx context.Context, query *goalsQuery, options ...*Options) ([]*Goal, *NextPage, error) {
+	var result []*Goal
+
+	// Make the request
+	nextPage, err := c.Get(ctx, "/goals", query, &result, options...)
+	return result, nextPage, err
+}
+
+// Goals returns the compact goal records in this workspace
+func (w *Workspace) Goals(ctx context.Context, client *Client, options ...*Options) ([]*Goal, *NextPage, error) {
+	client.trace("Listing goals in %q", w.Name)
+
+	return client.goals(ctx, &goalsQuery{Workspace: w.ID}, options...)
+}
+
+// TimePeriodGoals returns the compact goal records in this workspace which
+// are set for the given time period
+func (w *Workspace) TimePeriodGoals(ctx context.Context, client *Client, period *TimePeriod, options ...*Options) ([]*Goal, *NextPage, error) {
+	client.trace("Listing goals for time period %q in %q", period.ID, w.Name)
+
+	return client.goals(ctx, &goalsQuery{Workspace: w.ID, TimePeriods: period.ID}, options...)
+}
+
+// Goals returns the compact goal records of this team
+func (t *Team) Goals(ctx context.Context, client *Client, options ...*Options) ([]*Goal, *NextPage, error) {
+	client.trace("Listing goals of team %q", t.Name)
+
+	return client.goals(ctx, &goalsQuery{Team: t.ID}, options...)
+}
+
+// Goals returns the compact records for the goals this portfolio supports
+func (p *Portfolio) Goals(ctx context.Context, client *Client, options ...*Options) ([]*Goal, *NextPage, error) {
+	client.trace("Listing goals supported by portfolio %q", p.ID)
+
+	return client.goals(ctx, &goalsQuery{Portfolio: p.ID}, options...)
+}
+
+// Goals returns the compact records for the goals this project supports
+func (p *Project) Goals(ctx context.Context, client *Client, options ...*Options) ([]*Goal, *NextPage, error) {
+	client.trace("Listing goals supported by project %q", p.Name)
+
+	return client.goals(ctx, &goalsQuery{Project: p.ID}, options...)
+}
+
+// GoalIterator iterates over a list of goals
+type GoalIterator struct {
+	*Iterator
+}
+
+// Value returns the current goal
+func (it *GoalIterator) Value() *Goal {
+	v, _ := it.Iterator.Value().(*Goal)
+	return v
+}
+
+func (c *Client) iterateGoals(ctx context.Context, query *goalsQuery, options ...*Options) *GoalIterator {
+	return &GoalIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return c.goals(ctx, query, options...)
+	}, options...)}
+}
+
+func allGoals(it *GoalIterator) ([]*Goal, error) {
+	var allGoals []*Goal
+
+	for it.Next() {
+		allGoals = append(allGoals, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return allGoals, nil
+}
+
+// IterateGoals returns an iterator over the goals in this workspace
+func (w *Workspace) IterateGoals(ctx context.Context, client *Client, options ...*Options) *GoalIterator {
+	return client.iterateGoals(ctx, &goalsQuery{Workspace: w.ID}, options...)
+}
+
+// AllGoals repeatedly pages through all available goals in a workspace
+func (w *Workspace) AllGoals(ctx context.Context, client *Client, options ...*Options) ([]*Goal, error) {
+	return allGoals(w.IterateGoals(ctx, client, options...))
+}
+
+// IterateTimePeriodGoals returns an iterator over the goals in this
+// workspace which are set for the given time period
+func (w *Workspace) IterateTimePeriodGoals(ctx context.Context, client *Client, period *TimePeriod, options ...*Options) *GoalIterator {
+	return client.iterateGoals(ctx, &goalsQuery{Workspace: w.ID, TimePeriods: period.ID}, options...)
+}
+
+// AllTimePeriodGoals repeatedly pages through all available goals in a
+// workspace which are set for the given time period
+func (w *Workspace) AllTimePeriodGoals(ctx context.Context, client *Client, period *TimePeriod, options ...*Options) ([]*Goal, error) {
+	return allGoals(w.IterateTimePeriodGoals(ctx, client, period, options...))
+}
+
+// IterateGoals returns an iterator over the goals of this team
+func (t *Team) IterateGoals(ctx context.Context, client *Client, options ...*Options) *GoalIterator {
+	return client.iterateGoals(ctx, &goalsQuery{Team: t.ID}, options...)
+}
+
+// AllGoals repeatedly pages through all available goals of a team
+func (t *Team) AllGoals(ctx context.Context, client *Client, options ...*Options) ([]*Goal, error) {
+	return allGoals(t.IterateGoals(ctx, client, options...))
+}
+
+// IterateGoals returns an iterator over the goals this portfolio supports
+func (p *Portfolio) IterateGoals(ctx context.Context, client *Client, options ...*Options) *GoalIterator {
+	return client.iterateGoals(ctx, &goalsQuery{Portfolio: p.ID}, options...)
+}
+
+// AllGoals repeatedly pages through all available goals this portfolio
+// supports
+func (p *Portfolio) AllGoals(ctx context.Context, client *Client, options ...*Options) ([]*Goal, error) {
+	return allGoals(p.IterateGoals(ctx, client, options...))
+}
+
+// IterateGoals returns an iterator over the goals this project supports
+func (p *Project) IterateGoals(ctx context.Context, client *Client, options ...*Options) *GoalIterator {
+	return client.iterateGoals(ctx, &goalsQuery{Project: p.ID}, options...)
+}
+
+// AllGoals repeatedly pages through all available goals this project
+// supports
+func (p *Project) AllGoals(ctx context.Context, client *Client, options ...*Options) ([]*Goal, error) {
+	return allGoals(p.IterateGoals(ctx, client, options...))
+}
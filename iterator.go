@@ -0,0 +1,150 @@
+package asana
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultPageSize is the number of items an Iterator requests per page
+const DefaultPageSize = 100
+
+// ListFunc makes a single paginated list request. It must return a slice of
+// results along with the next page, as the list methods in this package do.
+type ListFunc func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error)
+
+// Iterator pages through the results of a list request one item at a time,
+// only holding a single page of results in memory.
+//
+//	it := workspace.IterateProjects(ctx, client)
+//	for it.Next() {
+//	    project := it.Value()
+//	}
+//	if err := it.Err(); err != nil {
+//	    ...
+//	}
+//
+// The typed iterators returned by the Iterate* methods embed an Iterator and
+// return typed values.
+type Iterator struct {
+	// The number of items to request per page, between 1 and 100. Defaults to
+	// DefaultPageSize. Must be set before the first call to Next.
+	PageSize int
+
+	// The maximum number of items to return, or zero for no limit
+	MaxItems int
+
+	ctx     context.Context
+	list    ListFunc
+	options []*Options
+
+	page    reflect.Value
+	index   int
+	offset  string
+	next    *NextPage
+	count   int
+	current interface{}
+	err     error
+	started bool
+}
+
+// NewIterator creates an Iterator for the given list request. The options
+// are passed to every request after the iterator's own paging options.
+func NewIterator(ctx context.Context, list ListFunc, options ...*Options) *Iterator {
+	return &Iterator{
+		ctx:     ctx,
+		list:    list,
+		options: options,
+	}
+}
+
+// Resume starts the iteration from an offset previously returned by Offset.
+// It must be called before the first call to Next.
+func (it *Iterator) Resume(offset string) {
+	it.offset = offset
+}
+
+// Offset returns a token from which the iteration can be resumed later. Items
+// from the page containing the current item may be returned again after
+// resuming, but no items will be skipped.
+func (it *Iterator) Offset() string {
+	if it.started && it.index >= it.length() && it.next != nil {
+		return it.next.Offset
+	}
+	return it.offset
+}
+
+// Next advances to the next item, fetching a new page if needed. It returns
+// false when there are no more items or an error occurred.
+func (it *Iterator) Next() bool {
+	if it.err != nil || (it.MaxItems > 0 && it.count >= it.MaxItems) {
+		return false
+	}
+
+	for it.index >= it.length() {
+		if it.started && it.next == nil {
+			return false
+		}
+		if it.started {
+			it.offset = it.next.Offset
+		}
+		if err := it.fetch(); err != nil {
+			it.err = err
+			return false
+		}
+	}
+
+	it.current = it.page.Index(it.index).Interface()
+	it.index++
+	it.count++
+	return true
+}
+
+// Value returns the current item
+func (it *Iterator) Value() interface{} {
+	return it.current
+}
+
+// Err returns the error which stopped the iteration, if any
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+func (it *Iterator) length() int {
+	if !it.page.IsValid() {
+		return 0
+	}
+	return it.page.Len()
+}
+
+func (it *Iterator) fetch() error {
+	limit := it.PageSize
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if it.MaxItems > 0 && it.MaxItems-it.count < limit {
+		limit = it.MaxItems - it.count
+	}
+
+	page := &Options{
+		Limit:  limit,
+		Offset: it.offset,
+	}
+
+	result, next, err := it.list(it.ctx, append([]*Options{page}, it.options...)...)
+	if err != nil {
+		return err
+	}
+
+	value := reflect.ValueOf(result)
+	if value.Kind() != reflect.Slice {
+		return errors.Errorf("List request returned %T, expected a slice", result)
+	}
+
+	it.started = true
+	it.page = value
+	it.index = 0
+	it.next = next
+	return nil
+}
@@ -0,0 +1,88 @@
+package asana
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+// listNumbers pages through the numbers 0 to n-1 using the offset as the
+// index of the first item in the page
+func listNumbers(n int, requests *[]Options) ListFunc {
+	return func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		page := options[0]
+		*requests = append(*requests, *page)
+
+		start, _ := strconv.Atoi(page.Offset)
+		var result []*int
+		for i := start; i < n && i < start+page.Limit; i++ {
+			i := i
+			result = append(result, &i)
+		}
+
+		if start+page.Limit >= n {
+			return result, nil, nil
+		}
+		return result, &NextPage{Offset: strconv.Itoa(start + page.Limit)}, nil
+	}
+}
+
+func collect(t *testing.T, it *Iterator) []int {
+	var result []int
+	for it.Next() {
+		result = append(result, *it.Value().(*int))
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestIterator(t *testing.T) {
+	var requests []Options
+	it := NewIterator(context.Background(), listNumbers(25, &requests))
+	it.PageSize = 10
+
+	result := collect(t, it)
+	if len(result) != 25 || result[24] != 24 {
+		t.Errorf("Expected 25 items, saw %v", result)
+	}
+	if len(requests) != 3 || requests[0].Limit != 10 || requests[2].Offset != "20" {
+		t.Errorf("Unexpected requests %+v", requests)
+	}
+}
+
+func TestIteratorMaxItems(t *testing.T) {
+	var requests []Options
+	it := NewIterator(context.Background(), listNumbers(25, &requests))
+	it.PageSize = 10
+	it.MaxItems = 15
+
+	if result := collect(t, it); len(result) != 15 {
+		t.Errorf("Expected 15 items, saw %v", result)
+	}
+	if len(requests) != 2 || requests[1].Limit != 5 {
+		t.Errorf("Expected the last page to be limited, saw %+v", requests)
+	}
+}
+
+func TestIteratorResume(t *testing.T) {
+	var requests []Options
+	it := NewIterator(context.Background(), listNumbers(25, &requests))
+	it.PageSize = 10
+
+	for i := 0; i < 10; i++ {
+		it.Next()
+	}
+	offset := it.Offset()
+	if offset != "10" {
+		t.Errorf("Expected to resume from the next page, saw %q", offset)
+	}
+
+	resumed := NewIterator(context.Background(), listNumbers(25, &requests))
+	resumed.PageSize = 10
+	resumed.Resume(offset)
+	if result := collect(t, resumed); len(result) != 15 || result[0] != 10 {
+		t.Errorf("Expected to resume from item 10, saw %v", result)
+	}
+}
@@ -0,0 +1,101 @@
+package asana
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// JobStatus is the state of an asynchronous job
+type JobStatus string
+
+// Job states
+const (
+	JobNotStarted JobStatus = "not_started"
+	JobInProgress JobStatus = "in_progress"
+	JobSucceeded  JobStatus = "succeeded"
+	JobFailed     JobStatus = "failed"
+)
+
+// Job represents an asynchronous operation, such as duplicating a project.
+// The new object is created as soon as the job starts, but is only complete
+// once the job has succeeded.
+type Job struct {
+	// Read-only. Globally unique ID of the object
+	ID string `json:"gid,omitempty"`
+
+	// Read-only. The kind of operation, such as duplicate_project
+	ResourceSubtype string `json:"resource_subtype,omitempty"`
+
+	// Read-only. The current state of the job
+	Status JobStatus `json:"status,omitempty"`
+
+	// Read-only. The project being created by the job, if any
+	NewProject *Project `json:"new_project,omitempty"`
+
+	// Read-only. The task being created by the job, if any
+	NewTask *Task `json:"new_task,omitempty"`
+}
+
+// Fetch loads the current state of this job
+func (j *Job) Fetch(ctx context.Context, client *Client, options ...*Options) error {
+	client.trace("Loading details for job %q", j.ID)
+
+	_, err := client.Get(ctx, fmt.Sprintf("/jobs/%s", j.ID), nil, j, options...)
+	return err
+}
+
+// The longest Wait will go between polls of a job
+const maxJobPollInterval = time.Minute
+
+// Wait polls the job until it has finished and returns the project or task
+// it created; the other is nil. The first poll happens after pollInterval,
+// which grows by half after each poll, up to a minute. A failed job returns
+// a *JobFailedError.
+func (j *Job) Wait(ctx context.Context, client *Client, pollInterval time.Duration) (*Project, *Task, error) {
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+
+	for j.Status != JobSucceeded && j.Status != JobFailed {
+		client.trace("Waiting %s for job %q", pollInterval, j.ID)
+
+		if err := sleep(ctx, pollInterval); err != nil {
+			return nil, nil, err
+		}
+
+		if err := j.Fetch(ctx, client); err != nil {
+			return nil, nil, err
+		}
+
+		pollInterval += pollInterval / 2
+		if pollInterval > maxJobPollInterval {
+			pollInterval = maxJobPollInterval
+		}
+	}
+
+	if j.Status == JobFailed {
+		return nil, nil, &JobFailedError{Job: j}
+	}
+	return j.NewProject, j.NewTask, nil
+}
+
+// JobFailedError is returned by Job.Wait when the job fails. Asana does not
+// say why a job failed.
+type JobFailedError struct {
+	Job *Job
+}
+
+func (err *JobFailedError) Error() string {
+	return fmt.Sprintf("%s job %s failed", err.Job.ResourceSubtype, err.Job.ID)
+}
+
+// IsJobFailed returns the job which failed if err was caused by a failed job
+func IsJobFailed(err error) (*Job, bool) {
+	if e, ok := errors.Cause(err).(*JobFailedError); ok {
+		return e.Job, true
+	}
+	return nil, false
+}
@@ -0,0 +1,55 @@
+package asana
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestJobWait(t *testing.T) {
+	var polls []time.Time
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		polls = append(polls, time.Now())
+		switch r.URL.Path {
+		case "/jobs/1":
+			if len(polls) < 3 {
+				w.Write([]byte(`{"data": {"gid": "1", "status": "in_progress"}}`))
+				return
+			}
+			w.Write([]byte(`{"data": {"gid": "1", "status": "succeeded", "new_task": {"gid": "2", "name": "Copy"}}}`))
+		case "/jobs/3":
+			w.Write([]byte(`{"data": {"gid": "3", "resource_subtype": "duplicate_project", "status": "failed"}}`))
+		default:
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+	})
+
+	start := time.Now()
+	project, task, err := (&Job{ID: "1", Status: JobNotStarted}).Wait(context.Background(), client, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if project != nil || task == nil || task.ID != "2" {
+		t.Errorf("Expected the new task, saw %+v and %+v", project, task)
+	}
+	if len(polls) != 3 {
+		t.Fatalf("Expected three polls, saw %d", len(polls))
+	}
+
+	// 10ms, 15ms and 22.5ms
+	if first, last := polls[0].Sub(start), polls[2].Sub(polls[1]); first < 10*time.Millisecond || last < 22*time.Millisecond {
+		t.Errorf("Expected the poll interval to grow, saw %s then %s", first, last)
+	}
+
+	_, _, err = (&Job{ID: "3", Status: JobInProgress}).Wait(context.Background(), client, time.Millisecond)
+	if job, ok := IsJobFailed(err); !ok || job.ID != "3" {
+		t.Errorf("Expected a failed job error, saw %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := (&Job{ID: "1"}).Wait(ctx, client, time.Hour); err != context.Canceled {
+		t.Errorf("Expected the wait to be cancelled, saw %v", err)
+	}
+}
@@ -2,26 +2,298 @@ package asana
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
 )
 
+// PortfolioBase contains the parts of Portfolio which can be set when it is
+// created or updated
+type PortfolioBase struct {
+	// The name of the portfolio.
+	Name string `json:"name,omitempty"`
+
+	// Color of the portfolio. Must be either null or one of: dark-pink,
+	// dark-green, dark-blue, dark-red, dark-teal, dark-brown, dark-orange,
+	// dark-purple, dark-warm-gray, light-pink, light-green, light-blue,
+	// light-red, light-teal, light-yellow, light-orange, light-purple,
+	// light-warm-gray.
+	Color string `json:"color,omitempty"`
+
+	// True if the portfolio is public to its workspace members.
+	Public bool `json:"public,omitempty"`
+
+	// The day on which work for the portfolio begins.
+	StartOn *Date `json:"start_on,omitempty"`
+
+	// The day on which the portfolio is due.
+	DueOn *Date `json:"due_on,omitempty"`
+}
+
+// Portfolio is a collection of projects, and possibly other portfolios,
+// which can be tracked together
 type Portfolio struct {
 	// Read-only. Globally unique ID of the object
 	ID string `json:"gid,omitempty"`
+
+	PortfolioBase
+
+	// Read-only. The time at which this object was created.
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+
+	// Read-only. The user who created the portfolio.
+	CreatedBy *User `json:"created_by,omitempty"`
+
+	// The current owner of the portfolio.
+	Owner *User `json:"owner,omitempty"`
+
+	// Read-only. Array of users who are members of this portfolio. Use
+	// AddMembers and RemoveMembers to change them.
+	Members []*User `json:"members,omitempty"`
+
+	// Create-only. The workspace or organization this portfolio is in.
+	Workspace *Workspace `json:"workspace,omitempty"`
+
+	// Read-only. Array of custom field settings (in compact form). Use
+	// ListCustomFieldSettings to page through all of them.
+	CustomFieldSettings []*CustomFieldSetting `json:"custom_field_settings,omitempty"`
+
+	// Array of custom field values set on the portfolio for a custom field
+	// applied to a parent portfolio.
+	CustomFields []*CustomFieldValue `json:"custom_fields,omitempty"`
+
+	// Read-only. The most recent status update posted on the portfolio.
+	CurrentStatusUpdate *StatusUpdate `json:"current_status_update,omitempty"`
+
+	// Read-only. A URL to view the portfolio in Asana.
+	PermalinkURL string `json:"permalink_url,omitempty"`
+}
+
+// CreatePortfolioRequest represents a request to create a new portfolio
+type CreatePortfolioRequest struct {
+	PortfolioBase
+
+	// Required: The workspace to create the portfolio in.
+	Workspace string `json:"workspace"`
+
+	// Users to add as members of the portfolio, in addition to its creator.
+	Members []string `json:"members,omitempty"`
 }
 
-// Projects returns a list of projects in this workspace
+// Validate checks that the request has the required fields
+func (r *CreatePortfolioRequest) Validate() error {
+	if r.Name == "" {
+		return errors.New("Missing portfolio name")
+	}
+	if r.Workspace == "" {
+		return errors.New("A workspace is required for a portfolio")
+	}
+	return nil
+}
+
+// UpdatePortfolioRequest represents a request to update a portfolio
+type UpdatePortfolioRequest struct {
+	PortfolioBase
+
+	Owner string `json:"owner,omitempty"`
+}
+
+// CreatePortfolio adds a new portfolio to a workspace
+func (c *Client) CreatePortfolio(ctx context.Context, portfolio *CreatePortfolioRequest) (*Portfolio, error) {
+	c.info("Creating portfolio %q", portfolio.Name)
+
+	result := &Portfolio{}
+	err := c.post(ctx, "/portfolios", portfolio, result)
+	return result, err
+}
+
+// Fetch loads the full details for this Portfolio
+func (p *Portfolio) Fetch(ctx context.Context, client *Client, options ...*Options) error {
+	client.trace("Loading portfolio details for %q", p.Name)
+
+	_, err := client.Get(ctx, fmt.Sprintf("/portfolios/%s", p.ID), nil, p, options...)
+	return err
+}
+
+// Update changes the fields given in the request, updating this Portfolio
+// with the result
+func (p *Portfolio) Update(ctx context.Context, client *Client, request *UpdatePortfolioRequest, options ...*Options) error {
+	client.trace("Update portfolio %q", p.Name)
+
+	return client.put(ctx, fmt.Sprintf("/portfolios/%s", p.ID), request, p, options...)
+}
+
+// Delete removes this portfolio. The projects in it are not deleted.
+func (p *Portfolio) Delete(ctx context.Context, client *Client) error {
+	client.info("Deleting portfolio %q", p.ID)
+
+	return client.delete(ctx, fmt.Sprintf("/portfolios/%s", p.ID))
+}
+
+type portfoliosQuery struct {
+	Workspace string `url:"workspace"`
+	Owner     string `url:"owner"`
+}
+
+// Portfolios returns a list of the portfolios in this workspace owned by the
+// current user. Set Options.Owner to list another user's portfolios.
 func (w *Workspace) Portfolios(ctx context.Context, client *Client, options ...*Options) ([]*Portfolio, *NextPage, error) {
-	client.trace("Listing portfolios in %q", w.Name)
+	return w.OwnedPortfolios(ctx, client, "me", options...)
+}
+
+// OwnedPortfolios returns a list of the portfolios in this workspace owned
+// by the given user
+func (w *Workspace) OwnedPortfolios(ctx context.Context, client *Client, ownerID string, options ...*Options) ([]*Portfolio, *NextPage, error) {
+	client.trace("Listing portfolios owned by %q in %q", ownerID, w.Name)
 
 	var result []*Portfolio
 
-	o := &Options{
+	// Make the request. Options are encoded after the query, so they can
+	// override the owner.
+	query := &portfoliosQuery{
 		Workspace: w.ID,
-		Owner:     "me",
+		Owner:     ownerID,
 	}
+	nextPage, err := client.Get(ctx, "/portfolios", query, &result, options...)
+	return result, nextPage, err
+}
+
+// AllPortfolios repeatedly pages through all available portfolios in a
+// workspace owned by the current user
+func (w *Workspace) AllPortfolios(ctx context.Context, client *Client, options ...*Options) ([]*Portfolio, error) {
+	var allPortfolios []*Portfolio
+
+	it := w.IteratePortfolios(ctx, client, options...)
+	for it.Next() {
+		allPortfolios = append(allPortfolios, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return allPortfolios, nil
+}
+
+// PortfolioIterator iterates over a list of portfolios
+type PortfolioIterator struct {
+	*Iterator
+}
+
+// Value returns the current portfolio
+func (it *PortfolioIterator) Value() *Portfolio {
+	v, _ := it.Iterator.Value().(*Portfolio)
+	return v
+}
+
+// IteratePortfolios returns an iterator over the portfolios in this workspace
+func (w *Workspace) IteratePortfolios(ctx context.Context, client *Client, options ...*Options) *PortfolioIterator {
+	return &PortfolioIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return w.Portfolios(ctx, client, options...)
+	}, options...)}
+}
+
+// IterateOwnedPortfolios returns an iterator over the portfolios in this
+// workspace owned by the given user
+func (w *Workspace) IterateOwnedPortfolios(ctx context.Context, client *Client, ownerID string, options ...*Options) *PortfolioIterator {
+	return &PortfolioIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return w.OwnedPortfolios(ctx, client, ownerID, options...)
+	}, options...)}
+}
+
+// Items
+
+// Items returns the compact records for the projects in this portfolio, in
+// the order they are listed in Asana
+func (p *Portfolio) Items(ctx context.Context, client *Client, options ...*Options) ([]*Project, *NextPage, error) {
+	client.trace("Listing items in portfolio %q", p.Name)
+
+	var result []*Project
 
 	// Make the request
-	nextPage, err := client.Get(ctx, fmt.Sprintf("/portfolios"), nil, &result, append(options, o)...)
+	nextPage, err := client.Get(ctx, fmt.Sprintf("/portfolios/%s/items", p.ID), nil, &result, options...)
 	return result, nextPage, err
 }
+
+// AllItems repeatedly pages through all available items in a portfolio
+func (p *Portfolio) AllItems(ctx context.Context, client *Client, options ...*Options) ([]*Project, error) {
+	var allItems []*Project
+
+	it := p.IterateItems(ctx, client, options...)
+	for it.Next() {
+		allItems = append(allItems, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return allItems, nil
+}
+
+// IterateItems returns an iterator over the items in this portfolio
+func (p *Portfolio) IterateItems(ctx context.Context, client *Client, options ...*Options) *ProjectIterator {
+	return &ProjectIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return p.Items(ctx, client, options...)
+	}, options...)}
+}
+
+// AddPortfolioItemRequest adds an item to a portfolio, optionally at a
+// position given by another item. At most one of InsertBefore and
+// InsertAfter may be set.
+type AddPortfolioItemRequest struct {
+	Item         string `json:"item"`
+	InsertBefore string `json:"insert_before,omitempty"`
+	InsertAfter  string `json:"insert_after,omitempty"`
+}
+
+// Validate checks that the request is consistent
+func (r *AddPortfolioItemRequest) Validate() error {
+	if r.Item == "" {
+		return errors.New("An item is required")
+	}
+	if r.InsertBefore != "" && r.InsertAfter != "" {
+		return errors.New("Only one of InsertBefore and InsertAfter may be set")
+	}
+	return nil
+}
+
+// AddItem adds a project to this portfolio, or moves it if it is already
+// in the portfolio
+func (p *Portfolio) AddItem(ctx context.Context, client *Client, request *AddPortfolioItemRequest) error {
+	client.trace("Adding item %q to portfolio %q", request.Item, p.ID)
+
+	return client.post(ctx, fmt.Sprintf("/portfolios/%s/addItem", p.ID), request, &json.RawMessage{})
+}
+
+// RemoveItem removes a project from this portfolio
+func (p *Portfolio) RemoveItem(ctx context.Context, client *Client, itemID string) error {
+	client.trace("Removing item %q from portfolio %q", itemID, p.ID)
+
+	m := map[string]interface{}{
+		"item": itemID,
+	}
+
+	return client.post(ctx, fmt.Sprintf("/portfolios/%s/removeItem", p.ID), m, &json.RawMessage{})
+}
+
+// Members
+
+// AddMembers adds users to this portfolio as members
+func (p *Portfolio) AddMembers(ctx context.Context, client *Client, userIDs ...string) error {
+	return p.changeMembers(ctx, client, "addMembers", userIDs)
+}
+
+// RemoveMembers removes users from the members of this portfolio
+func (p *Portfolio) RemoveMembers(ctx context.Context, client *Client, userIDs ...string) error {
+	return p.changeMembers(ctx, client, "removeMembers", userIDs)
+}
+
+func (p *Portfolio) changeMembers(ctx context.Context, client *Client, action string, userIDs []string) error {
+	client.trace("%s %v on portfolio %q", action, userIDs, p.ID)
+
+	m := map[string]interface{}{
+		"members": strings.Join(userIDs, ","),
+	}
+
+	return client.post(ctx, fmt.Sprintf("/portfolios/%s/%s", p.ID, action), m, p)
+}
@@ -0,0 +1,182 @@
+package asana
+
+import (
+	"context"
+	"fmt"
+)
+
+// DateVariable is a date which must be given when instantiating a project
+// template, such as the project's start date
+type DateVariable struct {
+	// Read-only. Globally unique ID of the variable
+	ID string `json:"gid,omitempty"`
+
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// TemplateRole is a role in a project template which can be assigned to a
+// user when the template is instantiated. Tasks assigned to the role are
+// assigned to that user.
+type TemplateRole struct {
+	// Read-only. Globally unique ID of the role
+	ID string `json:"gid,omitempty"`
+
+	Name string `json:"name,omitempty"`
+}
+
+// ProjectTemplate is a saved project from which new projects can be
+// instantiated
+type ProjectTemplate struct {
+	// Read-only. Globally unique ID of the object
+	ID string `json:"gid,omitempty"`
+
+	// Read-only. The name of the template.
+	Name string `json:"name,omitempty"`
+
+	// The description of the template.
+	Description string `json:"description,omitempty"`
+
+	// The description of the template with formatting as HTML.
+	HTMLDescription string `json:"html_description,omitempty"`
+
+	// Color of the template, as for projects.
+	Color string `json:"color,omitempty"`
+
+	// True if the template is public to its team.
+	Public bool `json:"public,omitempty"`
+
+	// The current owner of the template, may be null.
+	Owner *User `json:"owner,omitempty"`
+
+	// The team the template is shared with.
+	Team *Team `json:"team,omitempty"`
+
+	// The dates which must be given when instantiating the template.
+	RequestedDates []*DateVariable `json:"requested_dates,omitempty"`
+
+	// The roles which may be assigned when instantiating the template.
+	RequestedRoles []*TemplateRole `json:"requested_roles,omitempty"`
+}
+
+// Fetch loads the full details for this ProjectTemplate
+func (t *ProjectTemplate) Fetch(ctx context.Context, client *Client, options ...*Options) error {
+	client.trace("Loading details for project template %q", t.ID)
+
+	_, err := client.Get(ctx, fmt.Sprintf("/project_templates/%s", t.ID), nil, t, options...)
+	return err
+}
+
+type projectTemplatesQuery struct {
+	Workspace string `url:"workspace,omitempty"`
+}
+
+// ProjectTemplates returns the compact records for the project templates in
+// this workspace
+func (w *Workspace) ProjectTemplates(ctx context.Context, client *Client, options ...*Options) ([]*ProjectTemplate, *NextPage, error) {
+	client.trace("Listing project templates in workspace %s...\n", w.ID)
+	var result []*ProjectTemplate
+
+	// Make the request
+	query := &projectTemplatesQuery{Workspace: w.ID}
+	nextPage, err := client.Get(ctx, "/project_templates", query, &result, options...)
+	return result, nextPage, err
+}
+
+// ProjectTemplates returns the compact records for the project templates
+// shared with this team
+func (t *Team) ProjectTemplates(ctx context.Context, client *Client, options ...*Options) ([]*ProjectTemplate, *NextPage, error) {
+	client.trace("Listing project templates in team %s...\n", t.ID)
+	var result []*ProjectTemplate
+
+	// Make the request
+	nextPage, err := client.Get(ctx, fmt.Sprintf("/teams/%s/project_templates", t.ID), nil, &result, options...)
+	return result, nextPage, err
+}
+
+// ProjectTemplateIterator iterates over a list of project templates
+type ProjectTemplateIterator struct {
+	*Iterator
+}
+
+// Value returns the current project template
+func (it *ProjectTemplateIterator) Value() *ProjectTemplate {
+	v, _ := it.Iterator.Value().(*ProjectTemplate)
+	return v
+}
+
+// IterateProjectTemplates returns an iterator over the project templates in
+// this workspace
+func (w *Workspace) IterateProjectTemplates(ctx context.Context, client *Client, options ...*Options) *ProjectTemplateIterator {
+	return &ProjectTemplateIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return w.ProjectTemplates(ctx, client, options...)
+	}, options...)}
+}
+
+// IterateProjectTemplates returns an iterator over the project templates
+// shared with this team
+func (t *Team) IterateProjectTemplates(ctx context.Context, client *Client, options ...*Options) *ProjectTemplateIterator {
+	return &ProjectTemplateIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return t.ProjectTemplates(ctx, client, options...)
+	}, options...)}
+}
+
+// AllProjectTemplates repeatedly pages through all available project
+// templates in a workspace
+func (w *Workspace) AllProjectTemplates(ctx context.Context, client *Client, options ...*Options) ([]*ProjectTemplate, error) {
+	allTemplates := []*ProjectTemplate{}
+
+	it := w.IterateProjectTemplates(ctx, client, options...)
+	for it.Next() {
+		allTemplates = append(allTemplates, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return allTemplates, nil
+}
+
+// RequestedDate gives the value of one of a template's requested dates
+type RequestedDate struct {
+	ID    string `json:"gid"`   // Required: The ID of the date variable.
+	Value *Date  `json:"value"` // Required: The date to use.
+}
+
+// RoleAssignment assigns a user to one of a template's roles
+type RoleAssignment struct {
+	ID    string `json:"gid"`   // Required: The ID of the role.
+	Value string `json:"value"` // Required: The ID of the user to assign.
+}
+
+// InstantiateProjectRequest defines the project created from a template
+type InstantiateProjectRequest struct {
+	// Required: The name of the new project.
+	Name string `json:"name"`
+
+	// The team for the new project, if it differs from the template's.
+	Team string `json:"team,omitempty"`
+
+	// Whether the new project is public to its team.
+	Public *bool `json:"public,omitempty"`
+
+	// When true, every requested date and role must be given.
+	IsStrict bool `json:"is_strict,omitempty"`
+
+	// Values for the template's requested dates, which the dates of the new
+	// project's tasks are relative to.
+	RequestedDates []*RequestedDate `json:"requested_dates,omitempty"`
+
+	// Users to assign to the template's roles.
+	RequestedRoles []*RoleAssignment `json:"requested_roles,omitempty"`
+}
+
+// InstantiateProject starts creating a project from this template. The
+// project is created asynchronously; the returned job refers to the new
+// project, which is complete once the job has succeeded.
+func (t *ProjectTemplate) InstantiateProject(ctx context.Context, client *Client, request *InstantiateProjectRequest) (*Job, error) {
+	client.info("Instantiating project %q from template %q", request.Name, t.ID)
+
+	result := &Job{}
+	err := client.post(ctx, fmt.Sprintf("/project_templates/%s/instantiateProject", t.ID), request, result)
+	return result, err
+}
@@ -3,12 +3,16 @@ package asana
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 )
 
 // ProjectStatus is a description of the project’s status containing a color
 // (must be either null or one of: green, yellow, red) and a short
 // description.
+//
+// Deprecated: Asana has replaced project statuses with status updates; see
+// StatusUpdate.
 type ProjectStatus struct {
 	Color  string `json:"color,omitempty"`
 	Text   string `json:"text,omitempty"`
@@ -40,6 +44,8 @@ type ProjectBase struct {
 
 	// A description of the project’s status containing a color (must be
 	// either null or one of: green, yellow, red) and a short description.
+	//
+	// Deprecated: Use Project.StatusUpdates instead.
 	CurrentStatus *ProjectStatus `json:"current_status,omitempty"`
 
 	// The layout (board or list view) of the project.
@@ -224,24 +230,13 @@ func (w *Workspace) FavoriteProjects(ctx context.Context, client *Client, option
 // AllProjects repeatedly pages through all available projects in a workspace
 func (w *Workspace) AllProjects(ctx context.Context, client *Client, options ...*Options) ([]*Project, error) {
 	var allProjects []*Project
-	nextPage := &NextPage{}
-
-	var projects []*Project
-	var err error
 
-	for nextPage != nil {
-		page := &Options{
-			Limit:  100,
-			Offset: nextPage.Offset,
-		}
-
-		allOptions := append([]*Options{page}, options...)
-		projects, nextPage, err = w.Projects(ctx, client, allOptions...)
-		if err != nil {
-			return nil, err
-		}
-
-		allProjects = append(allProjects, projects...)
+	it := w.IterateProjects(ctx, client, options...)
+	for it.Next() {
+		allProjects = append(allProjects, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
 	}
 	return allProjects, nil
 }
@@ -249,24 +244,13 @@ func (w *Workspace) AllProjects(ctx context.Context, client *Client, options ...
 // AllProjects repeatedly pages through all available projects in a workspace
 func (w *Workspace) AllFavoriteProjects(ctx context.Context, client *Client, options ...*Options) ([]*Project, error) {
 	var allProjects []*Project
-	nextPage := &NextPage{}
-
-	var projects []*Project
-	var err error
-
-	for nextPage != nil {
-		page := &Options{
-			Limit:  100,
-			Offset: nextPage.Offset,
-		}
 
-		allOptions := append([]*Options{page}, options...)
-		projects, nextPage, err = w.FavoriteProjects(ctx, client, allOptions...)
-		if err != nil {
-			return nil, err
-		}
-
-		allProjects = append(allProjects, projects...)
+	it := w.IterateFavoriteProjects(ctx, client, options...)
+	for it.Next() {
+		allProjects = append(allProjects, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
 	}
 	return allProjects, nil
 }
@@ -285,24 +269,13 @@ func (t *Team) Projects(ctx context.Context, client *Client, options ...*Options
 // AllProjects repeatedly pages through all available projects in a team
 func (t *Team) AllProjects(ctx context.Context, client *Client, options ...*Options) ([]*Project, error) {
 	var allProjects []*Project
-	nextPage := &NextPage{}
-
-	var projects []*Project
-	var err error
-
-	for nextPage != nil {
-		page := &Options{
-			Limit:  100,
-			Offset: nextPage.Offset,
-		}
 
-		allOptions := append([]*Options{page}, options...)
-		projects, nextPage, err = t.Projects(ctx, client, allOptions...)
-		if err != nil {
-			return nil, err
-		}
-
-		allProjects = append(allProjects, projects...)
+	it := t.IterateProjects(ctx, client, options...)
+	for it.Next() {
+		allProjects = append(allProjects, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
 	}
 	return allProjects, nil
 }
@@ -326,3 +299,197 @@ func (t *Team) CreateProject(ctx context.Context, c *Client, project *CreateProj
 	err := c.post(ctx, fmt.Sprintf("/teams/%s/projects", t.ID), project, result)
 	return result, err
 }
+
+// ProjectIterator iterates over a list of projects
+type ProjectIterator struct {
+	*Iterator
+}
+
+// Value returns the current project
+func (it *ProjectIterator) Value() *Project {
+	v, _ := it.Iterator.Value().(*Project)
+	return v
+}
+
+// IterateProjects returns an iterator over the projects in this workspace
+func (w *Workspace) IterateProjects(ctx context.Context, client *Client, options ...*Options) *ProjectIterator {
+	return &ProjectIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return w.Projects(ctx, client, options...)
+	}, options...)}
+}
+
+// IterateFavoriteProjects returns an iterator over the current user's favorite projects in this workspace
+func (w *Workspace) IterateFavoriteProjects(ctx context.Context, client *Client, options ...*Options) *ProjectIterator {
+	return &ProjectIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return w.FavoriteProjects(ctx, client, options...)
+	}, options...)}
+}
+
+// IterateProjects returns an iterator over the projects in this team
+func (t *Team) IterateProjects(ctx context.Context, client *Client, options ...*Options) *ProjectIterator {
+	return &ProjectIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return t.Projects(ctx, client, options...)
+	}, options...)}
+}
+
+// Delete removes this project. Tasks which are also in other projects are
+// not deleted.
+func (p *Project) Delete(ctx context.Context, client *Client) error {
+	client.info("Deleting project %q", p.Name)
+
+	return client.delete(ctx, fmt.Sprintf("/projects/%s", p.ID))
+}
+
+// Archive archives this project, hiding it from the UI by default
+func (p *Project) Archive(ctx context.Context, client *Client) error {
+	return p.setArchived(ctx, client, true)
+}
+
+// Unarchive restores an archived project. This cannot be done with Update,
+// which omits Archived when it is false.
+func (p *Project) Unarchive(ctx context.Context, client *Client) error {
+	return p.setArchived(ctx, client, false)
+}
+
+func (p *Project) setArchived(ctx context.Context, client *Client, archived bool) error {
+	client.trace("Setting archived to %v on project %q", archived, p.Name)
+
+	m := map[string]interface{}{
+		"archived": archived,
+	}
+
+	return client.put(ctx, fmt.Sprintf("/projects/%s", p.ID), m, p)
+}
+
+// ProjectInclude is an optional part of a project to copy when duplicating it
+type ProjectInclude string
+
+// Parts of a project which can be included in a duplicate. The project's
+// tasks, sections and custom fields are always copied.
+const (
+	ProjectIncludeMembers          ProjectInclude = "members"
+	ProjectIncludeNotes            ProjectInclude = "notes"
+	ProjectIncludeTaskNotes        ProjectInclude = "task_notes"
+	ProjectIncludeTaskAssignee     ProjectInclude = "task_assignee"
+	ProjectIncludeTaskSubtasks     ProjectInclude = "task_subtasks"
+	ProjectIncludeTaskAttachments  ProjectInclude = "task_attachments"
+	ProjectIncludeTaskDates        ProjectInclude = "task_dates"
+	ProjectIncludeTaskDependencies ProjectInclude = "task_dependencies"
+	ProjectIncludeTaskFollowers    ProjectInclude = "task_followers"
+	ProjectIncludeTaskTags         ProjectInclude = "task_tags"
+	ProjectIncludeTaskProjects     ProjectInclude = "task_projects"
+)
+
+// ScheduleDates shifts the dates of duplicated tasks so that the project
+// ends on DueOn or begins on StartOn. Only one of the two may be set.
+type ScheduleDates struct {
+	ShouldSkipWeekends bool  `json:"should_skip_weekends"`
+	DueOn              *Date `json:"due_on,omitempty"`
+	StartOn            *Date `json:"start_on,omitempty"`
+}
+
+// DuplicateProjectRequest defines the copy made by Project.Duplicate
+type DuplicateProjectRequest struct {
+	Name          string           // Required: The name of the new project.
+	Team          string           // The team for the new project, if it differs from the original.
+	Include       []ProjectInclude // Optional parts of the project to copy.
+	ScheduleDates *ScheduleDates   // Shifts task dates, which requires ProjectIncludeTaskDates.
+}
+
+// joinInclude encodes the parts of an object to copy in a duplicate request
+// as the comma separated list which the API expects
+func joinInclude(include []string) string {
+	return strings.Join(include, ",")
+}
+
+// Duplicate starts copying this project. The copy is made asynchronously;
+// the returned job refers to the new project, which is complete once the
+// job has succeeded.
+func (p *Project) Duplicate(ctx context.Context, client *Client, request *DuplicateProjectRequest) (*Job, error) {
+	client.info("Duplicating project %q as %q", p.ID, request.Name)
+
+	// Custom request encoding, as include is a comma separated list
+	m := map[string]interface{}{
+		"name": request.Name,
+	}
+	if request.Team != "" {
+		m["team"] = request.Team
+	}
+	if len(request.Include) > 0 {
+		include := make([]string, len(request.Include))
+		for i, part := range request.Include {
+			include[i] = string(part)
+		}
+		m["include"] = joinInclude(include)
+	}
+	if request.ScheduleDates != nil {
+		m["schedule_dates"] = request.ScheduleDates
+	}
+
+	result := &Job{}
+	err := client.post(ctx, fmt.Sprintf("/projects/%s/duplicate", p.ID), m, result)
+	return result, err
+}
+
+// AddMembers adds users to this project as members
+func (p *Project) AddMembers(ctx context.Context, client *Client, userIDs ...string) error {
+	return p.changeUsers(ctx, client, "addMembers", "members", userIDs)
+}
+
+// RemoveMembers removes users from the members of this project
+func (p *Project) RemoveMembers(ctx context.Context, client *Client, userIDs ...string) error {
+	return p.changeUsers(ctx, client, "removeMembers", "members", userIDs)
+}
+
+// AddFollowers adds users to the followers of this project, making them
+// members if they are not already
+func (p *Project) AddFollowers(ctx context.Context, client *Client, userIDs ...string) error {
+	return p.changeUsers(ctx, client, "addFollowers", "followers", userIDs)
+}
+
+// RemoveFollowers removes users from the followers of this project. They
+// remain members.
+func (p *Project) RemoveFollowers(ctx context.Context, client *Client, userIDs ...string) error {
+	return p.changeUsers(ctx, client, "removeFollowers", "followers", userIDs)
+}
+
+func (p *Project) changeUsers(ctx context.Context, client *Client, action, key string, userIDs []string) error {
+	client.trace("%s %v on project %q", action, userIDs, p.ID)
+
+	m := map[string]interface{}{
+		key: strings.Join(userIDs, ","),
+	}
+
+	return client.post(ctx, fmt.Sprintf("/projects/%s/%s", p.ID, action), m, p)
+}
+
+// ProjectTaskCounts holds the number of tasks and milestones in a project
+type ProjectTaskCounts struct {
+	NumTasks                int `json:"num_tasks"`
+	NumCompletedTasks       int `json:"num_completed_tasks"`
+	NumIncompleteTasks      int `json:"num_incomplete_tasks"`
+	NumMilestones           int `json:"num_milestones"`
+	NumCompletedMilestones  int `json:"num_completed_milestones"`
+	NumIncompleteMilestones int `json:"num_incomplete_milestones"`
+}
+
+// TaskCounts returns the number of tasks and milestones in this project.
+// Asana only returns the counts which are asked for with Options.Fields;
+// all of them are requested if no fields are given.
+func (p *Project) TaskCounts(ctx context.Context, client *Client, opts ...*Options) (*ProjectTaskCounts, error) {
+	client.trace("Counting tasks in project %q", p.ID)
+
+	fields := false
+	for _, o := range opts {
+		if o != nil && len(o.Fields) > 0 {
+			fields = true
+		}
+	}
+	if !fields {
+		opts = append(opts, Fields(ProjectTaskCounts{}))
+	}
+
+	result := &ProjectTaskCounts{}
+	_, err := client.Get(ctx, fmt.Sprintf("/projects/%s/task_counts", p.ID), nil, result, opts...)
+	return result, err
+}
@@ -0,0 +1,249 @@
+package asana
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimiterConfig describes the quotas Asana applies to an access token.
+// Zero values are replaced with the limits Asana documents for paid
+// workspaces.
+type RateLimiterConfig struct {
+	// The number of requests which may be made per minute
+	RequestsPerMinute int
+
+	// The number of requests which may be sent without waiting when the
+	// limiter has been idle. Defaults to one second's worth of requests.
+	Burst int
+
+	// The maximum number of concurrent GET requests
+	MaxConcurrentReads int
+
+	// The maximum number of concurrent POST, PUT and DELETE requests
+	MaxConcurrentWrites int
+
+	// OnWait, if set, is called every time a request had to wait for the
+	// limiter, with the HTTP method of the request and the time it waited.
+	OnWait func(method string, wait time.Duration)
+}
+
+// Default quotas for an access token in a paid workspace
+const (
+	DefaultRequestsPerMinute   = 1500
+	DefaultMaxConcurrentReads  = 50
+	DefaultMaxConcurrentWrites = 15
+)
+
+// The limiter slows down to no less than this fraction of the configured
+// rate after being rate limited, and recovers a little with each successful
+// request
+const (
+	minRateFactor      = 0.1
+	rateRecoveryFactor = 1.05
+)
+
+// RateLimiter holds requests back before they are sent so that they stay
+// within Asana's per-token request rate and concurrency quotas. When the API
+// responds with 429 Too Many Requests, the limiter pauses every request until
+// the Retry-After period has passed and halves its request rate, recovering
+// gradually as requests succeed.
+//
+// A RateLimiter is safe for concurrent use and may be shared by several
+// Clients using the same access token.
+type RateLimiter struct {
+	rate   float64 // requests per second
+	burst  float64
+	onWait func(string, time.Duration)
+
+	reads  chan struct{}
+	writes chan struct{}
+
+	mu          sync.Mutex
+	tokens      float64
+	last        time.Time
+	factor      float64
+	pausedUntil time.Time
+	stats       RateLimiterStats
+}
+
+// RateLimiterStats reports how a RateLimiter has been holding requests back
+type RateLimiterStats struct {
+	// The number of requests which have passed through the limiter
+	Requests int64
+
+	// The number of requests which had to wait before being sent
+	Delayed int64
+
+	// The number of 429 responses reported to the limiter
+	RateLimited int64
+
+	// The total and longest time requests spent waiting in the limiter
+	TotalWait time.Duration
+	MaxWait   time.Duration
+
+	// The number of requests currently in progress
+	ActiveReads  int
+	ActiveWrites int
+
+	// The fraction of the configured request rate currently in use
+	RateFactor float64
+}
+
+// NewRateLimiter creates a RateLimiter with the provided quotas
+func NewRateLimiter(config *RateLimiterConfig) *RateLimiter {
+	c := RateLimiterConfig{}
+	if config != nil {
+		c = *config
+	}
+	if c.RequestsPerMinute <= 0 {
+		c.RequestsPerMinute = DefaultRequestsPerMinute
+	}
+	if c.MaxConcurrentReads <= 0 {
+		c.MaxConcurrentReads = DefaultMaxConcurrentReads
+	}
+	if c.MaxConcurrentWrites <= 0 {
+		c.MaxConcurrentWrites = DefaultMaxConcurrentWrites
+	}
+
+	rate := float64(c.RequestsPerMinute) / 60
+	burst := float64(c.Burst)
+	if burst <= 0 {
+		burst = rate
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		rate:   rate,
+		burst:  burst,
+		onWait: c.OnWait,
+		reads:  make(chan struct{}, c.MaxConcurrentReads),
+		writes: make(chan struct{}, c.MaxConcurrentWrites),
+		tokens: burst,
+		last:   time.Now(),
+		factor: 1,
+	}
+}
+
+// Stats returns a snapshot of the limiter's metrics
+func (l *RateLimiter) Stats() RateLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := l.stats
+	stats.ActiveReads = len(l.reads)
+	stats.ActiveWrites = len(l.writes)
+	stats.RateFactor = l.factor
+	return stats
+}
+
+// acquire waits until a request with the given method may be sent. The
+// returned function must be called with the response status code (or zero if
+// no response was received) once the request has completed.
+func (l *RateLimiter) acquire(ctx context.Context, method string) (func(statusCode int, retryAfter time.Duration), error) {
+	if l == nil {
+		return func(int, time.Duration) {}, nil
+	}
+
+	start := time.Now()
+
+	// Wait for a concurrency slot
+	slots := l.writes
+	if method == http.MethodGet {
+		slots = l.reads
+	}
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	// Wait for a token
+	if err := l.take(ctx); err != nil {
+		<-slots
+		return nil, err
+	}
+
+	wait := time.Since(start)
+	l.mu.Lock()
+	l.stats.Requests++
+	if wait > time.Millisecond {
+		l.stats.Delayed++
+		l.stats.TotalWait += wait
+		if wait > l.stats.MaxWait {
+			l.stats.MaxWait = wait
+		}
+	}
+	l.mu.Unlock()
+
+	if l.onWait != nil && wait > time.Millisecond {
+		l.onWait(method, wait)
+	}
+
+	return func(statusCode int, retryAfter time.Duration) {
+		<-slots
+		l.observe(statusCode, retryAfter)
+	}, nil
+}
+
+// take removes a token from the bucket, waiting for one to become available
+func (l *RateLimiter) take(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		rate := l.rate * l.factor
+
+		l.tokens += now.Sub(l.last).Seconds() * rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.last = now
+
+		var wait time.Duration
+		if now.Before(l.pausedUntil) {
+			wait = l.pausedUntil.Sub(now)
+		} else if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		} else {
+			wait = time.Duration((1 - l.tokens) / rate * float64(time.Second))
+		}
+		l.mu.Unlock()
+
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// observe adapts the request rate to the outcome of a request
+func (l *RateLimiter) observe(statusCode int, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		l.stats.RateLimited++
+		l.factor /= 2
+		if l.factor < minRateFactor {
+			l.factor = minRateFactor
+		}
+		l.tokens = 0
+
+		if retryAfter <= 0 {
+			retryAfter = time.Second
+		}
+		if until := time.Now().Add(retryAfter); until.After(l.pausedUntil) {
+			l.pausedUntil = until
+		}
+	case statusCode >= 200 && statusCode < 300:
+		l.factor *= rateRecoveryFactor
+		if l.factor > 1 {
+			l.factor = 1
+		}
+	}
+}
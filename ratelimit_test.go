@@ -0,0 +1,95 @@
+package asana
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterConcurrency(t *testing.T) {
+	limiter := NewRateLimiter(&RateLimiterConfig{
+		RequestsPerMinute:   60000,
+		Burst:               100,
+		MaxConcurrentWrites: 2,
+	})
+
+	var mu sync.Mutex
+	active, peak := 0, 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		if active > peak {
+			peak = active
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+		w.Write([]byte(`{"data": {}}`))
+	})
+	client.RateLimiter = limiter
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := (&Task{ID: "1"}).Update(context.Background(), client, &UpdateTaskRequest{}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak > 2 {
+		t.Errorf("Expected at most 2 concurrent writes, saw %d", peak)
+	}
+	if stats := limiter.Stats(); stats.Requests != 8 || stats.ActiveWrites != 0 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestRateLimiterTokenBucket(t *testing.T) {
+	limiter := NewRateLimiter(&RateLimiterConfig{
+		RequestsPerMinute: 6000, // 100 per second
+		Burst:             1,
+	})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		done, err := limiter.acquire(context.Background(), http.MethodGet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		done(http.StatusOK, 0)
+	}
+
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("Expected requests to be spaced out, took %s", elapsed)
+	}
+}
+
+func TestRateLimiterSlowsDown(t *testing.T) {
+	limiter := NewRateLimiter(nil)
+
+	done, err := limiter.acquire(context.Background(), http.MethodGet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done(http.StatusTooManyRequests, time.Hour)
+
+	if stats := limiter.Stats(); stats.RateLimited != 1 || stats.RateFactor != 0.5 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.acquire(ctx, http.MethodGet); err == nil {
+		t.Error("Expected requests to be paused after a 429")
+	}
+}
@@ -0,0 +1,181 @@
+package asana
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/xid"
+)
+
+// RetryPolicy describes how the client retries requests which were rate
+// limited (429) or failed with a server error (5xx).
+//
+// Rate limited requests wait for the duration given in the Retry-After header
+// of the response. Server errors wait for an exponentially increasing,
+// jittered delay between MinBackoff and MaxBackoff.
+//
+// A POST which failed with a server error may still have been processed, so
+// sending it again could create a duplicate task, comment or other object.
+// Unless RetryPosts is set, POST requests are only retried when they were
+// rate limited, which Asana rejects without processing.
+type RetryPolicy struct {
+	// The maximum number of times a request is sent, including the first
+	// attempt. Values below 2 disable retries.
+	MaxAttempts int
+
+	// The delay before the first retry of a server error. The delay doubles
+	// with each subsequent attempt.
+	MinBackoff time.Duration
+
+	// The upper bound for the delay between retries of a server error.
+	MaxBackoff time.Duration
+
+	// The longest Retry-After the client is prepared to wait for. Rate
+	// limited requests asking for a longer wait fail immediately. Zero means
+	// no limit.
+	MaxRetryAfter time.Duration
+
+	// Retry POST requests after server errors as well as after being rate
+	// limited. Only set this if duplicate objects are acceptable.
+	RetryPosts bool
+}
+
+// replayMode says which failed attempts of a request may be sent again
+type replayMode int
+
+const (
+	// The request body cannot be sent again
+	replayNever replayMode = iota
+
+	// Only requests which were rate limited, and so not processed
+	replayRateLimited
+
+	// Idempotent requests, after rate limits and server errors
+	replayAlways
+)
+
+// replayMode returns how requests with the given method may be retried
+func (c *Client) replayMode(method string) replayMode {
+	if method == http.MethodPost && (c.RetryPolicy == nil || !c.RetryPolicy.RetryPosts) {
+		return replayRateLimited
+	}
+	return replayAlways
+}
+
+// DefaultRetryPolicy returns a RetryPolicy suitable for most applications
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:   5,
+		MinBackoff:    500 * time.Millisecond,
+		MaxBackoff:    30 * time.Second,
+		MaxRetryAfter: 2 * time.Minute,
+	}
+}
+
+// delay returns how long to wait before sending the request again after
+// the given attempt failed with err, and false if it should not be retried
+func (p *RetryPolicy) delay(attempt int, err *Error) (time.Duration, bool) {
+	if p == nil || attempt >= p.MaxAttempts {
+		return 0, false
+	}
+
+	switch {
+	case err.StatusCode == http.StatusTooManyRequests:
+		if err.RetryAfter > 0 {
+			if p.MaxRetryAfter > 0 && err.RetryAfter > p.MaxRetryAfter {
+				return 0, false
+			}
+			return err.RetryAfter, true
+		}
+		return p.backoff(attempt), true
+	case err.StatusCode >= 500 && err.StatusCode < 600:
+		return p.backoff(attempt), true
+	}
+	return 0, false
+}
+
+// backoff calculates a jittered exponential delay for the given attempt,
+// somewhere between half and all of the full exponential value
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MinBackoff
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+// execute sends the request built by newRequest and parses the response into
+// result, waiting for the client's RateLimiter before each attempt and
+// retrying according to the client's RetryPolicy. newRequest is called once
+// per attempt and must return a request with a fresh body. mode limits which
+// failures are retried.
+func (c *Client) execute(ctx context.Context, requestID xid.ID, mode replayMode, newRequest func() (*http.Request, error), result interface{}) (*Response, error) {
+	for attempt := 1; ; attempt++ {
+		request, err := newRequest()
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s Request error", requestID)
+		}
+
+		done, err := c.RateLimiter.acquire(ctx, request.Method)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s rate limiter", requestID)
+		}
+
+		resp, err := c.HTTPClient.Do(request)
+		if err != nil {
+			done(0, 0)
+			return nil, errors.Wrapf(err, "%s %s error", requestID, request.Method)
+		}
+
+		value, err := c.parseResponse(resp, result, requestID)
+		if err == nil {
+			done(resp.StatusCode, 0)
+			return value, nil
+		}
+
+		asanaError, ok := IsAsanaError(err)
+		if !ok {
+			done(resp.StatusCode, 0)
+			return nil, err
+		}
+		done(resp.StatusCode, asanaError.RetryAfter)
+		asanaError.Attempts = attempt
+
+		if mode == replayNever || (mode == replayRateLimited && asanaError.StatusCode != http.StatusTooManyRequests) {
+			return nil, err
+		}
+		delay, retry := c.RetryPolicy.delay(attempt, asanaError)
+		if !retry {
+			return nil, err
+		}
+
+		c.info("%s %s %s failed with %d, retrying in %s (attempt %d)", requestID, request.Method, request.URL.Path, asanaError.StatusCode, delay, attempt)
+		if err := sleep(ctx, delay); err != nil {
+			return nil, errors.Wrapf(err, "%s retry cancelled after %d attempts", requestID, attempt)
+		}
+	}
+}
+
+// sleep waits for the given duration or until the context is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
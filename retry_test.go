@@ -0,0 +1,159 @@
+package asana
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.Client())
+	client.BaseURL, _ = url.Parse(server.URL)
+	client.RetryPolicy = &RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  5 * time.Millisecond,
+	}
+	return client
+}
+
+func TestRetryServerError(t *testing.T) {
+	calls := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"data": {"gid": "1", "name": "Workspace"}}`))
+	})
+
+	w := &Workspace{ID: "1"}
+	if err := w.Fetch(context.Background(), client); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, saw %d", calls)
+	}
+	if w.Name != "Workspace" {
+		t.Errorf("Expected workspace to be loaded, saw %q", w.Name)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	calls := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"errors": [{"message": "Rate limited"}]}`))
+	})
+
+	err := (&Workspace{ID: "1"}).Fetch(context.Background(), client)
+	if !IsRateLimited(err) {
+		t.Fatalf("Expected a rate limit error, saw %v", err)
+	}
+	if e, _ := IsAsanaError(err); e.Attempts != 3 {
+		t.Errorf("Expected 3 attempts, saw %d", e.Attempts)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, saw %d", calls)
+	}
+}
+
+func TestRetryReplaysBody(t *testing.T) {
+	var bodies []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"data": {"gid": "2"}}`))
+	})
+
+	a, err := (&Task{ID: "1"}).CreateAttachment(context.Background(), client, &NewAttachment{
+		Reader:      ioutil.NopCloser(strings.NewReader("file contents")),
+		FileName:    "file.txt",
+		ContentType: "text/plain",
+	})
+	if err == nil || a != nil {
+		t.Fatal("Expected an upload from a non-seekable reader not to be retried")
+	}
+
+	bodies = nil
+	if _, err := (&Task{ID: "1"}).CreateAttachment(context.Background(), client, &NewAttachment{
+		Reader:      seekCloser{strings.NewReader("file contents")},
+		FileName:    "file.txt",
+		ContentType: "text/plain",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(bodies) != 2 || bodies[0] != bodies[1] || !strings.Contains(bodies[1], "file contents") {
+		t.Errorf("Expected the upload to be replayed, saw %q", bodies)
+	}
+}
+
+func TestRetryPostServerError(t *testing.T) {
+	calls := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"data": {"gid": "2"}}`))
+	})
+
+	// The first attempt may have created the task
+	_, err := client.CreateTask(context.Background(), &CreateTaskRequest{TaskBase: TaskBase{Name: "Task"}, Workspace: "1"})
+	if e, ok := IsAsanaError(err); !ok || e.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("Expected the server error to be returned, saw %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected a POST not to be retried after a server error, saw %d calls", calls)
+	}
+
+	calls = 0
+	client.RetryPolicy.RetryPosts = true
+	if _, err := client.CreateTask(context.Background(), &CreateTaskRequest{TaskBase: TaskBase{Name: "Task"}, Workspace: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected the POST to be retried when allowed, saw %d calls", calls)
+	}
+}
+
+func TestRetryCancelled(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	client.RetryPolicy.MinBackoff = time.Hour
+	client.RetryPolicy.MaxBackoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := (&Workspace{ID: "1"}).Fetch(ctx, client)
+	if err == nil || ctx.Err() == nil {
+		t.Fatalf("Expected the retry to be cancelled, saw %v", err)
+	}
+}
+
+type seekCloser struct {
+	*strings.Reader
+}
+
+func (seekCloser) Close() error { return nil }
@@ -2,8 +2,11 @@ package asana
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
+
+	"github.com/pkg/errors"
 )
 
 type SectionBase struct {
@@ -40,6 +43,14 @@ func (s *Section) Fetch(ctx context.Context, client *Client) error {
 	return err
 }
 
+// Update changes the name of this section
+func (s *Section) Update(ctx context.Context, client *Client, request *SectionBase, opts ...*Options) error {
+	client.trace("Update section %s %q", s.ID, s.Name)
+
+	err := client.put(ctx, fmt.Sprintf("/sections/%s", s.ID), request, s, opts...)
+	return err
+}
+
 func (s *Section) Delete(ctx context.Context, client *Client) error {
 	client.trace("Delete section %s %q", s.ID, s.Name)
 
@@ -83,6 +94,102 @@ type SectionInsertRequest struct {
 func (p *Project) InsertSection(ctx context.Context, client *Client, request *SectionInsertRequest) error {
 	client.info("Moving section %s", request.Section)
 
-	err := client.post(ctx, fmt.Sprintf("projects/%s/sections/insert", p.ID), request, nil)
+	err := client.post(ctx, fmt.Sprintf("/projects/%s/sections/insert", p.ID), request, &json.RawMessage{})
 	return err
 }
+
+// AddTaskToSectionRequest defines where Section.AddTask places a task. At
+// most one of InsertBefore and InsertAfter may be set, and the task they
+// name must already be in the section. If neither is set, the task is
+// inserted at the top of the section.
+type AddTaskToSectionRequest struct {
+	Task         string `json:"task"`
+	InsertBefore string `json:"insert_before,omitempty"`
+	InsertAfter  string `json:"insert_after,omitempty"`
+}
+
+// Validate checks that the request is consistent
+func (r *AddTaskToSectionRequest) Validate() error {
+	if r.Task == "" {
+		return errors.New("A task is required")
+	}
+	if r.InsertBefore != "" && r.InsertAfter != "" {
+		return errors.New("Only one of InsertBefore and InsertAfter may be set")
+	}
+	return nil
+}
+
+// AddTask moves a task into this section, removing it from any other
+// section of the project. The task is added to the project if it is not
+// already in it.
+func (s *Section) AddTask(ctx context.Context, client *Client, request *AddTaskToSectionRequest) error {
+	client.trace("Adding task %q to section %q", request.Task, s.ID)
+
+	err := client.post(ctx, fmt.Sprintf("/sections/%s/addTask", s.ID), request, &json.RawMessage{})
+	return err
+}
+
+// TaskPosition is where Project.MoveTask places a task within a section.
+// The zero value is the top of the section.
+type TaskPosition struct {
+	before, after string
+	bottom        bool
+}
+
+// Positions within a section
+var (
+	SectionTop    = TaskPosition{}
+	SectionBottom = TaskPosition{bottom: true}
+)
+
+// BeforeTask is the position immediately before a task in the section
+func BeforeTask(taskID string) TaskPosition {
+	return TaskPosition{before: taskID}
+}
+
+// AfterTask is the position immediately after a task in the section
+func AfterTask(taskID string) TaskPosition {
+	return TaskPosition{after: taskID}
+}
+
+// MoveTask places a task in a section of this project, adding it to the
+// project if necessary. This works the same whether the project is shown as
+// a list, where sections are headers, or as a board, where they are columns.
+func (p *Project) MoveTask(ctx context.Context, client *Client, task *Task, section *Section, position TaskPosition) error {
+	if section.Project != nil && section.Project.ID != p.ID {
+		return errors.Errorf("Section %s is not in project %s", section.ID, p.ID)
+	}
+
+	// Adding to the project places the task at the bottom of a section,
+	// where adding to the section can only place it at the top or next to
+	// another task
+	if position.bottom {
+		return task.AddProject(ctx, client, &AddProjectRequest{
+			Project: p.ID,
+			Section: section.ID,
+		})
+	}
+	return section.AddTask(ctx, client, &AddTaskToSectionRequest{
+		Task:         task.ID,
+		InsertBefore: position.before,
+		InsertAfter:  position.after,
+	})
+}
+
+// SectionIterator iterates over a list of sections
+type SectionIterator struct {
+	*Iterator
+}
+
+// Value returns the current section
+func (it *SectionIterator) Value() *Section {
+	v, _ := it.Iterator.Value().(*Section)
+	return v
+}
+
+// IterateSections returns an iterator over the sections in this project
+func (p *Project) IterateSections(ctx context.Context, client *Client, options ...*Options) *SectionIterator {
+	return &SectionIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return p.Sections(ctx, client, options...)
+	}, options...)}
+}
@@ -0,0 +1,191 @@
+package asana
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// StatusType is the overall state reported by a status update
+type StatusType string
+
+// Status types for status updates
+const (
+	StatusOnTrack  StatusType = "on_track"
+	StatusAtRisk   StatusType = "at_risk"
+	StatusOffTrack StatusType = "off_track"
+	StatusOnHold   StatusType = "on_hold"
+	StatusComplete StatusType = "complete"
+)
+
+// StatusUpdateBase contains the parts of StatusUpdate which can be set when
+// it is created
+type StatusUpdateBase struct {
+	// The title of the status update.
+	Title string `json:"title,omitempty"`
+
+	// The text content of the status update. One of Text or HTMLText is
+	// required.
+	Text string `json:"text,omitempty"`
+
+	// The text content of the status update with formatting as HTML.
+	HTMLText string `json:"html_text,omitempty"`
+
+	// Required: The overall state of the parent object.
+	StatusType StatusType `json:"status_type,omitempty"`
+}
+
+// StatusUpdateParent is the project, portfolio or goal a status update
+// reports on
+type StatusUpdateParent struct {
+	ID           string `json:"gid"`
+	ResourceType string `json:"resource_type"`
+	Name         string `json:"name,omitempty"`
+}
+
+// StatusUpdate is an update on the progress of a project, portfolio or goal.
+// Status updates replace the deprecated current_status field of projects.
+type StatusUpdate struct {
+	// Read-only. Globally unique ID of the object
+	ID string `json:"gid,omitempty"`
+
+	StatusUpdateBase
+
+	// Read-only. The kind of parent, such as project_status_update.
+	ResourceSubtype string `json:"resource_subtype,omitempty"`
+
+	// Read-only. The object the status update reports on.
+	Parent *StatusUpdateParent `json:"parent,omitempty"`
+
+	// Read-only. The user who wrote the status update.
+	Author *User `json:"author,omitempty"`
+
+	// Read-only. The user who created the status update.
+	CreatedBy *User `json:"created_by,omitempty"`
+
+	// Read-only. The time at which this object was created.
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+
+	// Read-only. The time at which this object was last modified.
+	ModifiedAt *time.Time `json:"modified_at,omitempty"`
+}
+
+// CreateStatusUpdateRequest represents a request to post a status update
+type CreateStatusUpdateRequest struct {
+	StatusUpdateBase
+
+	// Required: The project, portfolio or goal to post the update on.
+	Parent string `json:"parent"`
+}
+
+// Validate checks that the request has the required fields
+func (r *CreateStatusUpdateRequest) Validate() error {
+	if r.Parent == "" {
+		return errors.New("A parent is required for a status update")
+	}
+	if r.StatusType == "" {
+		return errors.New("A status type is required for a status update")
+	}
+	if r.Text == "" && r.HTMLText == "" {
+		return errors.New("Text or HTML text is required for a status update")
+	}
+	return nil
+}
+
+// CreateStatusUpdate posts a status update on a project, portfolio or goal
+func (c *Client) CreateStatusUpdate(ctx context.Context, request *CreateStatusUpdateRequest) (*StatusUpdate, error) {
+	c.info("Creating status update %q on %s", request.Title, request.Parent)
+
+	result := &StatusUpdate{}
+	err := c.post(ctx, "/status_updates", request, result)
+	return result, err
+}
+
+// Fetch loads the full details for this StatusUpdate
+func (s *StatusUpdate) Fetch(ctx context.Context, client *Client, options ...*Options) error {
+	client.trace("Loading details for status update %q", s.ID)
+
+	_, err := client.Get(ctx, fmt.Sprintf("/status_updates/%s", s.ID), nil, s, options...)
+	return err
+}
+
+// Delete removes this status update
+func (s *StatusUpdate) Delete(ctx context.Context, client *Client) error {
+	client.info("Deleting status update %q", s.ID)
+
+	return client.delete(ctx, fmt.Sprintf("/status_updates/%s", s.ID))
+}
+
+type statusUpdatesQuery struct {
+	Parent string `url:"parent"`
+}
+
+// StatusUpdates returns the compact records for the status updates on a
+// project, portfolio or goal, newest first
+func (c *Client) StatusUpdates(ctx context.Context, parentID string, options ...*Options) ([]*StatusUpdate, *NextPage, error) {
+	c.trace("Listing status updates on %s", parentID)
+	var result []*StatusUpdate
+
+	// Make the request
+	nextPage, err := c.Get(ctx, "/status_updates", &statusUpdatesQuery{Parent: parentID}, &result, options...)
+	return result, nextPage, err
+}
+
+// StatusUpdateIterator iterates over a list of status updates
+type StatusUpdateIterator struct {
+	*Iterator
+}
+
+// Value returns the current status update
+func (it *StatusUpdateIterator) Value() *StatusUpdate {
+	v, _ := it.Iterator.Value().(*StatusUpdate)
+	return v
+}
+
+// IterateStatusUpdates returns an iterator over the status updates on a
+// project, portfolio or goal
+func (c *Client) IterateStatusUpdates(ctx context.Context, parentID string, options ...*Options) *StatusUpdateIterator {
+	return &StatusUpdateIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return c.StatusUpdates(ctx, parentID, options...)
+	}, options...)}
+}
+
+// CreateStatusUpdate posts a status update on this project
+func (p *Project) CreateStatusUpdate(ctx context.Context, client *Client, update *StatusUpdateBase) (*StatusUpdate, error) {
+	return client.CreateStatusUpdate(ctx, &CreateStatusUpdateRequest{StatusUpdateBase: *update, Parent: p.ID})
+}
+
+// StatusUpdates returns the compact records for the status updates on this
+// project, newest first
+func (p *Project) StatusUpdates(ctx context.Context, client *Client, options ...*Options) ([]*StatusUpdate, *NextPage, error) {
+	return client.StatusUpdates(ctx, p.ID, options...)
+}
+
+// IterateStatusUpdates returns an iterator over the status updates on this
+// project
+func (p *Project) IterateStatusUpdates(ctx context.Context, client *Client, options ...*Options) *StatusUpdateIterator {
+	return &StatusUpdateIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return p.StatusUpdates(ctx, client, options...)
+	}, options...)}
+}
+
+// CreateStatusUpdate posts a status update on this portfolio
+func (p *Portfolio) CreateStatusUpdate(ctx context.Context, client *Client, update *StatusUpdateBase) (*StatusUpdate, error) {
+	return client.CreateStatusUpdate(ctx, &CreateStatusUpdateRequest{StatusUpdateBase: *update, Parent: p.ID})
+}
+
+// StatusUpdates returns the compact records for the status updates on this
+// portfolio, newest first
+func (p *Portfolio) StatusUpdates(ctx context.Context, client *Client, options ...*Options) ([]*StatusUpdate, *NextPage, error) {
+	return client.StatusUpdates(ctx, p.ID, options...)
+}
+
+// IterateStatusUpdates returns an iterator over the status updates on this
+// portfolio
+func (p *Portfolio) IterateStatusUpdates(ctx context.Context, client *Client, options ...*Options) *StatusUpdateIterator {
+	return &StatusUpdateIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return p.StatusUpdates(ctx, client, options...)
+	}, options...)}
+}
@@ -36,6 +36,8 @@ type Dates struct {
 	StartOn *Date      `json:"start_on,omitempty"`
 }
 
+// StorySubtypeFields is a union of the fields of every story subtype. See
+// TypedStories for stories decoded into a struct for each subtype.
 type StorySubtypeFields struct {
 	// Whether the text of the story has been edited after creation.
 	// Note: This field is only present on comment stories.
@@ -96,10 +98,10 @@ type StorySubtypeFields struct {
 
 	// Present for dependency_added, dependency_removed, dependency_marked_complete, dependency_marked_incomplete,
 	// dependency_due_date_changed
-	Dependency *Task `json:"duplicated_from,omitempty"`
+	Dependency *Task `json:"dependency,omitempty"`
 
 	// Present for dependent_added, dependent_removed
-	Dependent *Task `json:"duplicated_from,omitempty"`
+	Dependent *Task `json:"dependent,omitempty"`
 }
 
 // Story represents an activity associated with an object in the Asana
@@ -191,9 +193,73 @@ func (s *Story) UpdateStory(ctx context.Context, client *Client, story *StoryBas
 	return result, err
 }
 
+// Like adds the authorized user to the users who like this story, updating
+// this Story with the result. Only comments and some other stories can be
+// liked.
+func (s *Story) Like(ctx context.Context, client *Client) error {
+	return s.setLiked(ctx, client, true)
+}
+
+// Unlike removes the authorized user from the users who like this story
+func (s *Story) Unlike(ctx context.Context, client *Client) error {
+	return s.setLiked(ctx, client, false)
+}
+
+func (s *Story) setLiked(ctx context.Context, client *Client, liked bool) error {
+	client.trace("Setting liked to %v on story %s", liked, s.ID)
+
+	// Custom request encoding
+	m := map[string]interface{}{
+		"liked": liked,
+	}
+
+	return client.put(ctx, fmt.Sprintf("/stories/%s", s.ID), m, s)
+}
+
+// Pin pins this story to the top of its task. Only comments and attachment
+// stories can be pinned.
+func (s *Story) Pin(ctx context.Context, client *Client) error {
+	return s.setPinned(ctx, client, true)
+}
+
+// Unpin unpins this story. UpdateStory cannot do this, as a false IsPinned
+// is left out of the request.
+func (s *Story) Unpin(ctx context.Context, client *Client) error {
+	return s.setPinned(ctx, client, false)
+}
+
+func (s *Story) setPinned(ctx context.Context, client *Client, pinned bool) error {
+	client.trace("Setting is_pinned to %v on story %s", pinned, s.ID)
+
+	// Custom request encoding
+	m := map[string]interface{}{
+		"is_pinned": pinned,
+	}
+
+	return client.put(ctx, fmt.Sprintf("/stories/%s", s.ID), m, s)
+}
+
 func (s *Story) Delete(ctx context.Context, client *Client) error {
 	client.trace("Delete story %s %s", s.ID, s.ResourceSubtype)
 
 	err := client.delete(ctx, fmt.Sprintf("/stories/%s", s.ID))
 	return err
 }
+
+// StoryIterator iterates over a list of stories
+type StoryIterator struct {
+	*Iterator
+}
+
+// Value returns the current story
+func (it *StoryIterator) Value() *Story {
+	v, _ := it.Iterator.Value().(*Story)
+	return v
+}
+
+// IterateStories returns an iterator over the stories on this task
+func (t *Task) IterateStories(ctx context.Context, client *Client, options ...*Options) *StoryIterator {
+	return &StoryIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return t.Stories(ctx, client, options...)
+	}, options...)}
+}
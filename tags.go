@@ -72,24 +72,13 @@ func (w *Workspace) Tags(ctx context.Context, client *Client, options ...*Option
 // AllTags repeatedly pages through all available tags in a workspace
 func (w *Workspace) AllTags(ctx context.Context, client *Client, options ...*Options) ([]*Tag, error) {
 	allTags := []*Tag{}
-	nextPage := &NextPage{}
 
-	var tags []*Tag
-	var err error
-
-	for nextPage != nil {
-		page := &Options{
-			Limit:  50,
-			Offset: nextPage.Offset,
-		}
-
-		allOptions := append([]*Options{page}, options...)
-		tags, nextPage, err = w.Tags(ctx, client, allOptions...)
-		if err != nil {
-			return nil, err
-		}
-
-		allTags = append(allTags, tags...)
+	it := w.IterateTags(ctx, client, options...)
+	for it.Next() {
+		allTags = append(allTags, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
 	}
 	return allTags, nil
 }
@@ -107,3 +96,57 @@ func (w *Workspace) CreateTag(ctx context.Context, client *Client, tag *TagBase)
 
 	return result, nil
 }
+
+// Update applies new values to a Tag record
+func (t *Tag) Update(ctx context.Context, client *Client, tag *TagBase, options ...*Options) error {
+	client.trace("Updating tag %q", t.Name)
+
+	err := client.put(ctx, fmt.Sprintf("/tags/%s", t.ID), tag, t, options...)
+	return err
+}
+
+// Delete removes this tag from the workspace. Tasks which had the tag are
+// otherwise unchanged.
+func (t *Tag) Delete(ctx context.Context, client *Client) error {
+	client.info("Deleting tag %q", t.Name)
+
+	return client.delete(ctx, fmt.Sprintf("/tags/%s", t.ID))
+}
+
+// TaskTags returns the tags on this task. Unlike the Tags field, which is
+// only populated when the task is fetched, this can page through any number
+// of tags.
+func (t *Task) TaskTags(ctx context.Context, client *Client, options ...*Options) ([]*Tag, *NextPage, error) {
+	client.trace("Listing tags for task %q", t.Name)
+
+	var result []*Tag
+
+	// Make the request
+	nextPage, err := client.Get(ctx, fmt.Sprintf("/tasks/%s/tags", t.ID), nil, &result, options...)
+	return result, nextPage, err
+}
+
+// TagIterator iterates over a list of tags
+type TagIterator struct {
+	*Iterator
+}
+
+// Value returns the current tag
+func (it *TagIterator) Value() *Tag {
+	v, _ := it.Iterator.Value().(*Tag)
+	return v
+}
+
+// IterateTags returns an iterator over the tags in this workspace
+func (w *Workspace) IterateTags(ctx context.Context, client *Client, options ...*Options) *TagIterator {
+	return &TagIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return w.Tags(ctx, client, options...)
+	}, options...)}
+}
+
+// IterateTags returns an iterator over the tags on this task
+func (t *Task) IterateTags(ctx context.Context, client *Client, options ...*Options) *TagIterator {
+	return &TagIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return t.TaskTags(ctx, client, options...)
+	}, options...)}
+}
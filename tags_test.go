@@ -0,0 +1,59 @@
+package asana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestTaskAddRemoveTag(t *testing.T) {
+	var requests []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body := struct {
+			Data map[string]string `json:"data"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, fmt.Sprintf("%s %s %s", r.Method, r.URL.Path, body.Data["tag"]))
+		w.Write([]byte(`{"data": {}}`))
+	})
+
+	task := &Task{ID: "1"}
+	if err := task.AddTag(context.Background(), client, "10"); err != nil {
+		t.Fatal(err)
+	}
+	if err := task.RemoveTag(context.Background(), client, "10"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"POST /tasks/1/addTag 10", "POST /tasks/1/removeTag 10"}
+	if fmt.Sprint(requests) != fmt.Sprint(expected) {
+		t.Errorf("Expected %q, saw %q", expected, requests)
+	}
+}
+
+func TestTagIterateTasks(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tags/10/tasks" {
+			t.Errorf("Unexpected request for %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("offset") == "" {
+			w.Write([]byte(`{"data": [{"gid": "1"}, {"gid": "2"}], "next_page": {"offset": "next"}}`))
+			return
+		}
+		w.Write([]byte(`{"data": [{"gid": "3"}], "next_page": null}`))
+	})
+
+	var ids []string
+	it := (&Tag{ID: "10"}).IterateTasks(context.Background(), client)
+	for it.Next() {
+		ids = append(ids, it.Value().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(ids) != "[1 2 3]" {
+		t.Errorf("Expected tasks 1, 2 and 3, saw %v", ids)
+	}
+}
@@ -0,0 +1,415 @@
+package asana
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// FieldChange is a value which a field of a task held from a point in time
+// until the next change
+type FieldChange struct {
+	// When the value was set. For the value a task was created with, this is
+	// the time the task was created.
+	At time.Time
+
+	// The story which recorded the change, or nil for the value the task was
+	// created with
+	Story TypedStory
+
+	// The value of the field, or nil if the field was empty. See TaskHistory
+	// for the type of each field.
+	Value interface{}
+
+	// True if the stories do not say what the value was, in which case Value
+	// is nil
+	Unknown bool
+}
+
+// FieldHistory is the values of a field of a task in the order they were set
+type FieldHistory []*FieldChange
+
+// At returns the value of the field at time t, or nil if t is before the
+// first change
+func (fh FieldHistory) At(t time.Time) interface{} {
+	var value interface{}
+	for _, change := range fh {
+		if change.At.After(t) {
+			break
+		}
+		value = change.Value
+	}
+	return value
+}
+
+// Find returns the first change to a value which matches, or nil if the
+// field never held such a value
+func (fh FieldHistory) Find(match func(value interface{}) bool) *FieldChange {
+	for _, change := range fh {
+		if match(change.Value) {
+			return change
+		}
+	}
+	return nil
+}
+
+// Duration returns how long the field held values which match, up to until.
+// The last value is taken to be held until then, so pass the time the task
+// was completed to measure a finished task.
+func (fh FieldHistory) Duration(until time.Time, match func(value interface{}) bool) time.Duration {
+	var total time.Duration
+	for i, change := range fh {
+		end := until
+		if i+1 < len(fh) && fh[i+1].At.Before(until) {
+			end = fh[i+1].At
+		}
+		if match(change.Value) && end.After(change.At) {
+			total += end.Sub(change.At)
+		}
+	}
+	return total
+}
+
+// record adds a change to the history of a field. The first change also records the
+// previous value, from the time the task was created.
+func (fh FieldHistory) record(created time.Time, story TypedStory, previous, value interface{}) FieldHistory {
+	if len(fh) == 0 {
+		fh = append(fh, &FieldChange{At: created, Value: orNil(previous)})
+	}
+	return append(fh, &FieldChange{At: storyTime(story), Story: story, Value: orNil(value)})
+}
+
+// TaskHistory is the state of a task over time, rebuilt by replaying the
+// system stories on the task. Each field is a FieldHistory starting when the
+// task was created, holding values of these types:
+//
+//	Name          string
+//	Assignee      *User
+//	Completed     bool
+//	Dates         *Dates
+//	Sections      *Section, by project ID
+//	CustomFields  *CustomFieldValue, by custom field ID
+//
+// A section with no ID means that the task was in the project, but none of
+// the stories say which section it was in.
+//
+// Assignment stories don't record the previous assignee. Asana records an
+// assigned story when a task is created with an assignee, so the task is
+// taken to have been unassigned until its first assigned story. If the first
+// story unassigns the task instead, such as when older stories have been
+// trimmed, the assignee before it is recorded as Unknown. Fields with no
+// stories held their current value throughout.
+type TaskHistory struct {
+	Task *Task
+
+	// When the task was created
+	Created time.Time
+
+	// The stories on the task, oldest first
+	Stories []TypedStory
+
+	Name         FieldHistory
+	Assignee     FieldHistory
+	Completed    FieldHistory
+	Dates        FieldHistory
+	Sections     map[string]FieldHistory
+	CustomFields map[string]FieldHistory
+}
+
+// historyOptions requests the story fields which a TaskHistory is built from
+var historyOptions = &Options{Fields: []string{
+	"created_at", "created_by.name", "resource_subtype", "text",
+	"assignee.name",
+	"old_name", "new_name",
+	"old_dates", "new_dates",
+	"project.name", "old_section.name", "new_section.name",
+	"custom_field.name", "custom_field.resource_subtype",
+	"old_text_value", "new_text_value",
+	"old_number_value", "new_number_value",
+	"old_enum_value.name", "new_enum_value.name",
+	"old_multi_enum_values.name", "new_multi_enum_values.name",
+	"old_date_value", "new_date_value",
+	"old_people_value.name", "new_people_value.name",
+}}
+
+// History loads the task and all of its stories, and replays the stories
+// into a TaskHistory
+func (t *Task) History(ctx context.Context, client *Client) (*TaskHistory, error) {
+	client.trace("Loading history for %q", t.ID)
+
+	if err := t.Fetch(ctx, client); err != nil {
+		return nil, err
+	}
+	stories, err := t.AllTypedStories(ctx, client, historyOptions)
+	if err != nil {
+		return nil, err
+	}
+	return NewTaskHistory(t, stories), nil
+}
+
+// NewTaskHistory replays stories into the history of a task. The task should
+// be fully loaded, as its current state fills in fields which have no
+// stories.
+func NewTaskHistory(task *Task, stories []TypedStory) *TaskHistory {
+	h := &TaskHistory{
+		Task:         task,
+		Stories:      append([]TypedStory(nil), stories...),
+		Sections:     map[string]FieldHistory{},
+		CustomFields: map[string]FieldHistory{},
+	}
+	sort.SliceStable(h.Stories, func(i, j int) bool {
+		return storyTime(h.Stories[i]).Before(storyTime(h.Stories[j]))
+	})
+	if task.CreatedAt != nil {
+		h.Created = *task.CreatedAt
+	} else if len(h.Stories) > 0 {
+		h.Created = storyTime(h.Stories[0])
+	}
+
+	// Changes adding the task to a project, until a later story says which
+	// section it was added to
+	added := map[string]*FieldChange{}
+
+	for _, story := range h.Stories {
+		switch s := story.(type) {
+		case *NameChangedStory:
+			h.Name = h.Name.record(h.Created, s, s.OldName, s.NewName)
+		case *AssignedStory:
+			h.Assignee = h.Assignee.record(h.Created, s, nil, s.Assignee)
+		case *UnassignedStory:
+			if h.Assignee == nil {
+				h.Assignee = FieldHistory{{At: h.Created, Unknown: true}}
+			}
+			h.Assignee = h.Assignee.record(h.Created, s, nil, nil)
+		case *MarkedCompleteStory:
+			h.Completed = h.Completed.record(h.Created, s, false, true)
+		case *MarkedIncompleteStory:
+			h.Completed = h.Completed.record(h.Created, s, true, false)
+		case *DueDateChangedStory:
+			h.Dates = h.Dates.record(h.Created, s, datesOrNil(s.OldDates), datesOrNil(s.NewDates))
+
+		case *AddedToProjectStory:
+			if s.Project == nil {
+				continue
+			}
+			h.Sections[s.Project.ID] = h.Sections[s.Project.ID].record(h.Created, s, nil, &Section{})
+			timeline := h.Sections[s.Project.ID]
+			added[s.Project.ID] = timeline[len(timeline)-1]
+		case *RemovedFromProjectStory:
+			if s.Project == nil {
+				continue
+			}
+			h.Sections[s.Project.ID] = h.Sections[s.Project.ID].record(h.Created, s, &Section{}, nil)
+			delete(added, s.Project.ID)
+		case *SectionChangedStory:
+			projectID := h.sectionProject(s)
+			if projectID == "" {
+				continue
+			}
+			if change := added[projectID]; change != nil {
+				change.Value = orNil(s.OldSection)
+				delete(added, projectID)
+			}
+			h.Sections[projectID] = h.Sections[projectID].record(h.Created, s, s.OldSection, s.NewSection)
+
+		default:
+			if field, previous, value := customFieldChange(story); field != nil {
+				h.CustomFields[field.ID] = h.CustomFields[field.ID].record(h.Created, s, previous, value)
+			}
+		}
+	}
+
+	// Fill in what the stories leave out from the current state of the task
+	for projectID, change := range added {
+		if section := currentSection(task, projectID); section != nil {
+			change.Value = section
+		}
+	}
+	if h.Name == nil {
+		h.Name = FieldHistory{{At: h.Created, Value: task.Name}}
+	}
+	if h.Assignee == nil {
+		h.Assignee = FieldHistory{{At: h.Created, Value: orNil(task.Assignee)}}
+	}
+	if h.Completed == nil {
+		h.Completed = FieldHistory{{At: h.Created, Value: task.Completed != nil && *task.Completed}}
+	}
+	if h.Dates == nil {
+		h.Dates = FieldHistory{{At: h.Created, Value: datesOrNil(&Dates{DueOn: task.DueOn, DueAt: task.DueAt, StartOn: task.StartOn})}}
+	}
+	for _, membership := range task.Memberships {
+		if membership.Project != nil && h.Sections[membership.Project.ID] == nil {
+			h.Sections[membership.Project.ID] = FieldHistory{{At: h.Created, Value: orNil(membership.Section)}}
+		}
+	}
+	for _, value := range task.CustomFields {
+		if h.CustomFields[value.ID] == nil {
+			h.CustomFields[value.ID] = FieldHistory{{At: h.Created, Value: value}}
+		}
+	}
+	return h
+}
+
+// NameAt returns the name of the task at time t
+func (h *TaskHistory) NameAt(t time.Time) string {
+	v, _ := h.Name.At(t).(string)
+	return v
+}
+
+// AssigneeAt returns who the task was assigned to at time t, or nil if it
+// was unassigned or the assignee is unknown
+func (h *TaskHistory) AssigneeAt(t time.Time) *User {
+	v, _ := h.Assignee.At(t).(*User)
+	return v
+}
+
+// IsCompleteAt reports whether the task was complete at time t
+func (h *TaskHistory) IsCompleteAt(t time.Time) bool {
+	v, _ := h.Completed.At(t).(bool)
+	return v
+}
+
+// DatesAt returns the start and due dates of the task at time t, or nil if
+// it had none
+func (h *TaskHistory) DatesAt(t time.Time) *Dates {
+	v, _ := h.Dates.At(t).(*Dates)
+	return v
+}
+
+// SectionAt returns the section of a project which the task was in at time
+// t, or nil if it was not in the project
+func (h *TaskHistory) SectionAt(projectID string, t time.Time) *Section {
+	v, _ := h.Sections[projectID].At(t).(*Section)
+	return v
+}
+
+// CustomFieldAt returns the value of a custom field at time t, or nil if the
+// field was not on the task
+func (h *TaskHistory) CustomFieldAt(fieldID string, t time.Time) *CustomFieldValue {
+	v, _ := h.CustomFields[fieldID].At(t).(*CustomFieldValue)
+	return v
+}
+
+// TimeInSection returns how long the task spent in a section of a project, up
+// to until
+func (h *TaskHistory) TimeInSection(projectID, sectionID string, until time.Time) time.Duration {
+	return h.Sections[projectID].Duration(until, func(value interface{}) bool {
+		section, _ := value.(*Section)
+		return section != nil && section.ID == sectionID
+	})
+}
+
+// TimeInSections returns how long the task spent in each section of a
+// project, by section ID, up to until
+func (h *TaskHistory) TimeInSections(projectID string, until time.Time) map[string]time.Duration {
+	result := map[string]time.Duration{}
+	for _, change := range h.Sections[projectID] {
+		if section, _ := change.Value.(*Section); section != nil {
+			if _, ok := result[section.ID]; !ok {
+				result[section.ID] = h.TimeInSection(projectID, section.ID, until)
+			}
+		}
+	}
+	return result
+}
+
+// sectionProject finds the project of a section_changed story which does not
+// include it
+func (h *TaskHistory) sectionProject(s *SectionChangedStory) string {
+	if s.Project != nil {
+		return s.Project.ID
+	}
+	if s.OldSection != nil {
+		for projectID, timeline := range h.Sections {
+			if section, _ := timeline[len(timeline)-1].Value.(*Section); section != nil && section.ID == s.OldSection.ID {
+				return projectID
+			}
+		}
+	}
+	for _, membership := range h.Task.Memberships {
+		if membership.Project != nil && membership.Section != nil && s.NewSection != nil && membership.Section.ID == s.NewSection.ID {
+			return membership.Project.ID
+		}
+	}
+	return ""
+}
+
+func currentSection(task *Task, projectID string) *Section {
+	for _, membership := range task.Memberships {
+		if membership.Project != nil && membership.Project.ID == projectID {
+			return membership.Section
+		}
+	}
+	return nil
+}
+
+// customFieldChange returns the values before and after a custom field
+// story, or a nil field if the story is not about a custom field
+func customFieldChange(story TypedStory) (field *CustomField, previous, value *CustomFieldValue) {
+	values := func(field *CustomField) (*CustomFieldValue, *CustomFieldValue) {
+		return &CustomFieldValue{CustomField: *field}, &CustomFieldValue{CustomField: *field}
+	}
+
+	switch s := story.(type) {
+	case *TextCustomFieldChangedStory:
+		if field = s.CustomField; field != nil {
+			previous, value = values(field)
+			if s.OldTextValue != "" {
+				previous.TextValue = &s.OldTextValue
+			}
+			if s.NewTextValue != "" {
+				value.TextValue = &s.NewTextValue
+			}
+		}
+	case *NumberCustomFieldChangedStory:
+		if field = s.CustomField; field != nil {
+			previous, value = values(field)
+			previous.NumberValue, value.NumberValue = s.OldNumberValue, s.NewNumberValue
+		}
+	case *EnumCustomFieldChangedStory:
+		if field = s.CustomField; field != nil {
+			previous, value = values(field)
+			previous.EnumValue, value.EnumValue = s.OldEnumValue, s.NewEnumValue
+		}
+	case *MultiEnumCustomFieldChangedStory:
+		if field = s.CustomField; field != nil {
+			previous, value = values(field)
+			previous.MultiEnumValues, value.MultiEnumValues = s.OldMultiEnumValues, s.NewMultiEnumValues
+		}
+	case *DateCustomFieldChangedStory:
+		if field = s.CustomField; field != nil {
+			previous, value = values(field)
+			previous.DateValue, value.DateValue = s.OldDateValue, s.NewDateValue
+		}
+	case *PeopleCustomFieldChangedStory:
+		if field = s.CustomField; field != nil {
+			previous, value = values(field)
+			previous.PeopleValue, value.PeopleValue = s.OldPeopleValue, s.NewPeopleValue
+		}
+	}
+	return field, previous, value
+}
+
+func storyTime(story TypedStory) time.Time {
+	if meta := story.Meta(); meta.CreatedAt != nil {
+		return *meta.CreatedAt
+	}
+	return time.Time{}
+}
+
+func datesOrNil(dates *Dates) *Dates {
+	if dates == nil || (dates.DueOn == nil && dates.DueAt == nil && dates.StartOn == nil) {
+		return nil
+	}
+	return dates
+}
+
+// orNil turns nil pointers into untyped nils, so that empty values compare
+// equal to nil
+func orNil(value interface{}) interface{} {
+	if v := reflect.ValueOf(value); value != nil && v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,230 @@
+package asana
+
+import (
+	"testing"
+	"time"
+)
+
+var historyStart = time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func minutes(n int) time.Time {
+	return historyStart.Add(time.Duration(n) * time.Minute)
+}
+
+// fixtureStory decodes a story from testdata/stories, moved to the given time
+func fixtureStory(t *testing.T, name string, at time.Time) TypedStory {
+	story, err := DecodeStory(readStoryFixture(t, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	story.Meta().CreatedAt = &at
+	return story
+}
+
+func storyAt(subtype string, at time.Time) StoryMeta {
+	return StoryMeta{ResourceSubtype: subtype, CreatedAt: &at}
+}
+
+func sectionID(section *Section) string {
+	if section == nil {
+		return "<none>"
+	}
+	return section.ID
+}
+
+func TestTaskHistoryFields(t *testing.T) {
+	created := historyStart
+	task := &Task{ID: "21", TaskBase: TaskBase{Name: "INC-42: API latency"}, CreatedAt: &created}
+
+	history := NewTaskHistory(task, []TypedStory{
+		// Out of order, as the history sorts stories by time
+		fixtureStory(t, "enum_custom_field_changed", minutes(4)),
+		fixtureStory(t, "assigned", minutes(1)),
+		fixtureStory(t, "due_date_changed", minutes(2)),
+		&MarkedIncompleteStory{StoryMeta: storyAt("marked_incomplete", minutes(3))},
+	})
+
+	if assignee := history.AssigneeAt(minutes(0)); assignee != nil {
+		t.Errorf("Expected the task to be unassigned before the first assignment, saw %+v", assignee)
+	}
+	if assignee := history.AssigneeAt(minutes(1)); assignee == nil || assignee.Name != "Grace Hopper" {
+		t.Errorf("Expected the task to be assigned, saw %+v", assignee)
+	}
+
+	// Being marked incomplete means it was complete until then
+	if !history.IsCompleteAt(minutes(0)) || history.IsCompleteAt(minutes(3)) {
+		t.Error("Expected the task to be complete until it was marked incomplete")
+	}
+
+	if dates := history.DatesAt(minutes(0)); dates == nil || time.Time(*dates.DueOn).Format("2006-01-02") != "2021-06-02" || dates.StartOn != nil {
+		t.Errorf("Expected the old dates before the change, saw %+v", dates)
+	}
+	if dates := history.DatesAt(minutes(2)); dates == nil || time.Time(*dates.DueOn).Format("2006-01-02") != "2021-06-04" || dates.StartOn == nil {
+		t.Errorf("Expected the new dates after the change, saw %+v", dates)
+	}
+
+	if value := history.CustomFieldAt("41", minutes(0)); value == nil || value.EnumValue == nil || value.EnumValue.Name != "SEV2" {
+		t.Errorf("Expected the old severity before the change, saw %+v", value)
+	}
+	if value := history.CustomFieldAt("41", minutes(4)); value == nil || value.EnumValue == nil || value.EnumValue.Name != "SEV1" {
+		t.Errorf("Expected the new severity after the change, saw %+v", value)
+	}
+
+	// Fields without stories keep their current value
+	if name := history.NameAt(minutes(0)); name != task.Name {
+		t.Errorf("Expected the current name throughout, saw %q", name)
+	}
+	if history.AssigneeAt(created.Add(-time.Minute)) != nil || history.NameAt(created.Add(-time.Minute)) != "" {
+		t.Error("Expected no values before the task was created")
+	}
+}
+
+func TestTaskHistoryCreatedAssigned(t *testing.T) {
+	created := historyStart
+	task := &Task{ID: "21", CreatedAt: &created, Assignee: &User{ID: "13"}}
+
+	// The assignment recorded when the task was created
+	history := NewTaskHistory(task, []TypedStory{
+		fixtureStory(t, "assigned", created),
+		&AssignedStory{StoryMeta: storyAt("assigned", minutes(5)), Assignee: &User{ID: "13"}},
+	})
+	if assignee := history.AssigneeAt(created); assignee == nil || assignee.ID != "12" {
+		t.Errorf("Expected the task to be assigned from creation, saw %+v", assignee)
+	}
+	acknowledged := history.Assignee.Find(func(value interface{}) bool { return value != nil })
+	if acknowledged == nil || !acknowledged.At.Equal(created) {
+		t.Errorf("Expected the task to be acknowledged when created, saw %+v", acknowledged)
+	}
+
+	// Without stories the current assignee applies throughout
+	history = NewTaskHistory(task, nil)
+	if assignee := history.AssigneeAt(created); assignee == nil || assignee.ID != "13" {
+		t.Errorf("Expected the current assignee throughout, saw %+v", assignee)
+	}
+}
+
+func TestTaskHistoryUnknownAssignee(t *testing.T) {
+	created := historyStart
+	task := &Task{ID: "21", TaskBase: TaskBase{Name: "INC-42: API latency"}, CreatedAt: &created}
+
+	// Unassigning first means the stories miss the original assignee, as
+	// when the story of the assignment has been trimmed
+	history := NewTaskHistory(task, []TypedStory{
+		&UnassignedStory{StoryMeta: storyAt("unassigned", minutes(2))},
+		&MarkedCompleteStory{StoryMeta: storyAt("marked_complete", minutes(3))},
+		fixtureStory(t, "assigned", minutes(4)),
+	})
+
+	if first := history.Assignee[0]; !first.Unknown || first.Value != nil || !first.At.Equal(created) {
+		t.Errorf("Expected the assignee to be unknown until unassigned, saw %+v", first)
+	}
+	for _, change := range history.Assignee[1:] {
+		if change.Unknown {
+			t.Errorf("Expected the assignee to be known after %s, saw %+v", change.At.Format(time.Kitchen), change)
+		}
+	}
+	if history.AssigneeAt(minutes(1)) != nil || history.AssigneeAt(minutes(2)) != nil {
+		t.Error("Expected no assignee before the task was assigned again")
+	}
+	if assignee := history.AssigneeAt(minutes(4)); assignee == nil || assignee.ID != "12" {
+		t.Errorf("Expected the task to be assigned again, saw %+v", assignee)
+	}
+
+	// The rest of the history is unaffected
+	if history.IsCompleteAt(minutes(2)) || !history.IsCompleteAt(minutes(3)) {
+		t.Error("Expected the task to be complete after being marked complete")
+	}
+	if name := history.NameAt(minutes(0)); name != task.Name {
+		t.Errorf("Expected the current name throughout, saw %q", name)
+	}
+}
+
+func TestTaskHistorySectionFromNextStory(t *testing.T) {
+	created := historyStart
+	task := &Task{
+		ID:          "21",
+		CreatedAt:   &created,
+		Memberships: []*Membership{{Project: &Project{ID: "30"}, Section: &Section{ID: "32"}}},
+	}
+
+	// The section_changed fixture has no project, so it is matched to the
+	// project by the task's current section
+	history := NewTaskHistory(task, []TypedStory{
+		&AddedToProjectStory{StoryMeta: storyAt("added_to_project", minutes(1)), Project: &Project{ID: "30"}},
+		fixtureStory(t, "section_changed", minutes(3)),
+	})
+
+	for _, expected := range []struct {
+		at      time.Time
+		section string
+	}{
+		{minutes(0), "<none>"},
+		{minutes(1), "31"}, // from the old section of the next story
+		{minutes(3), "32"},
+	} {
+		if section := history.SectionAt("30", expected.at); sectionID(section) != expected.section {
+			t.Errorf("Expected section %s at %s, saw %s", expected.section, expected.at.Format(time.Kitchen), sectionID(section))
+		}
+	}
+
+	durations := history.TimeInSections("30", minutes(10))
+	if durations["31"] != 2*time.Minute || durations["32"] != 7*time.Minute || len(durations) != 2 {
+		t.Errorf("Unexpected time in sections %v", durations)
+	}
+}
+
+func TestTaskHistoryRemovedAndReadded(t *testing.T) {
+	created := historyStart
+	task := &Task{
+		ID:          "21",
+		CreatedAt:   &created,
+		Memberships: []*Membership{{Project: &Project{ID: "30"}, Section: &Section{ID: "33"}}},
+	}
+
+	history := NewTaskHistory(task, []TypedStory{
+		&AddedToProjectStory{StoryMeta: storyAt("added_to_project", minutes(1)), Project: &Project{ID: "30"}},
+		&SectionChangedStory{
+			StoryMeta:  storyAt("section_changed", minutes(3)),
+			Project:    &Project{ID: "30"},
+			OldSection: &Section{ID: "31"},
+			NewSection: &Section{ID: "32"},
+		},
+		// Matched to the project by the section the task was last in
+		&SectionChangedStory{
+			StoryMeta:  storyAt("section_changed", minutes(4)),
+			OldSection: &Section{ID: "32"},
+			NewSection: &Section{ID: "34"},
+		},
+		&RemovedFromProjectStory{StoryMeta: storyAt("removed_from_project", minutes(5)), Project: &Project{ID: "30"}},
+		&AddedToProjectStory{StoryMeta: storyAt("added_to_project", minutes(7)), Project: &Project{ID: "30"}},
+	})
+
+	for _, expected := range []struct {
+		at      time.Time
+		section string
+	}{
+		{minutes(0), "<none>"},
+		{minutes(2), "31"},
+		{minutes(3), "32"},
+		{minutes(4), "34"},
+		{minutes(6), "<none>"},
+		{minutes(8), "33"}, // from the task's current section
+	} {
+		if section := history.SectionAt("30", expected.at); sectionID(section) != expected.section {
+			t.Errorf("Expected section %s at %s, saw %s", expected.section, expected.at.Format(time.Kitchen), sectionID(section))
+		}
+	}
+	if d := history.TimeInSection("30", "33", minutes(10)); d != 3*time.Minute {
+		t.Errorf("Expected 3 minutes in the section after being added again, saw %s", d)
+	}
+
+	// Without a later story or a current section, the section is unknown
+	task.Memberships = nil
+	history = NewTaskHistory(task, []TypedStory{
+		&AddedToProjectStory{StoryMeta: storyAt("added_to_project", minutes(1)), Project: &Project{ID: "30"}},
+		&RemovedFromProjectStory{StoryMeta: storyAt("removed_from_project", minutes(5)), Project: &Project{ID: "30"}},
+	})
+	if section := history.SectionAt("30", minutes(2)); section == nil || section.ID != "" {
+		t.Errorf("Expected a section with no ID while in the project, saw %+v", section)
+	}
+}
@@ -0,0 +1,484 @@
+package asana
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SearchMatch describes how a search parameter which takes several IDs
+// matches tasks
+type SearchMatch string
+
+const (
+	// MatchAny matches tasks with any of the given values
+	MatchAny SearchMatch = "any"
+
+	// MatchAll matches tasks with all of the given values
+	MatchAll SearchMatch = "all"
+
+	// MatchNot matches tasks with none of the given values
+	MatchNot SearchMatch = "not"
+)
+
+// TextMatch describes how a text custom field predicate matches values
+type TextMatch string
+
+const (
+	TextEquals     TextMatch = "value"
+	TextContains   TextMatch = "contains"
+	TextStartsWith TextMatch = "starts_with"
+	TextEndsWith   TextMatch = "ends_with"
+)
+
+// SearchSort is a field search results can be sorted by
+type SearchSort string
+
+const (
+	SortByDueDate     SearchSort = "due_date"
+	SortByCreatedAt   SearchSort = "created_at"
+	SortByCompletedAt SearchSort = "completed_at"
+	SortByLikes       SearchSort = "likes"
+	SortByModifiedAt  SearchSort = "modified_at"
+)
+
+// DateRange restricts a date field in a search. Fields which are nil are
+// ignored.
+type DateRange struct {
+	On     *Date
+	Before *Date
+	After  *Date
+}
+
+// TimeRange restricts a timestamp field in a search. Fields which are nil are
+// ignored.
+type TimeRange struct {
+	Before *time.Time
+	After  *time.Time
+}
+
+// TaskSearch builds a query for the workspace task search endpoint. Methods
+// may be chained:
+//
+//	search := asana.NewTaskSearch().
+//		Text("outage").
+//		Projects(asana.MatchAny, "123", "456").
+//		Completed(false).
+//		CustomFieldEnum("789", "1011")
+//
+// The first invalid parameter is reported by Validate, and by any request
+// made with the search.
+type TaskSearch struct {
+	params    url.Values
+	sortBy    SearchSort
+	ascending bool
+	err       error
+}
+
+// NewTaskSearch creates an empty task search, which matches every task in
+// the workspace
+func NewTaskSearch() *TaskSearch {
+	return &TaskSearch{params: url.Values{}}
+}
+
+// Validate reports the first invalid parameter added to the search
+func (q *TaskSearch) Validate() error {
+	return q.err
+}
+
+// Values returns the query parameters for the search
+func (q *TaskSearch) Values() url.Values {
+	result := url.Values{}
+	for key, values := range q.params {
+		result[key] = append([]string(nil), values...)
+	}
+	if q.sortBy != "" {
+		result.Set("sort_by", string(q.sortBy))
+		result.Set("sort_ascending", strconv.FormatBool(q.ascending))
+	}
+	return result
+}
+
+func (q *TaskSearch) fail(format string, args ...interface{}) *TaskSearch {
+	if q.err == nil {
+		q.err = errors.Errorf(format, args...)
+	}
+	return q
+}
+
+// Text matches tasks whose name or description contains the text
+func (q *TaskSearch) Text(text string) *TaskSearch {
+	q.params.Set("text", text)
+	return q
+}
+
+// ResourceSubtype matches tasks of the given subtype, such as "milestone"
+func (q *TaskSearch) ResourceSubtype(subtype string) *TaskSearch {
+	q.params.Set("resource_subtype", subtype)
+	return q
+}
+
+// ids adds a parameter such as projects.any with a comma separated list of
+// IDs, checking that the match is supported for the parameter
+func (q *TaskSearch) ids(param string, match SearchMatch, allowed []SearchMatch, ids []string) *TaskSearch {
+	supported := false
+	for _, m := range allowed {
+		supported = supported || m == match
+	}
+	if !supported {
+		return q.fail("Search parameter %s does not support %q matching", param, match)
+	}
+	if len(ids) == 0 {
+		return q.fail("Search parameter %s.%s needs at least one ID", param, match)
+	}
+
+	q.params.Set(fmt.Sprintf("%s.%s", param, match), strings.Join(ids, ","))
+	return q
+}
+
+var (
+	anyAllNot = []SearchMatch{MatchAny, MatchAll, MatchNot}
+	anyNot    = []SearchMatch{MatchAny, MatchNot}
+)
+
+// Projects matches tasks by the projects they are in
+func (q *TaskSearch) Projects(match SearchMatch, projectIDs ...string) *TaskSearch {
+	return q.ids("projects", match, anyAllNot, projectIDs)
+}
+
+// Sections matches tasks by the sections they are in
+func (q *TaskSearch) Sections(match SearchMatch, sectionIDs ...string) *TaskSearch {
+	return q.ids("sections", match, anyAllNot, sectionIDs)
+}
+
+// Tags matches tasks by their tags
+func (q *TaskSearch) Tags(match SearchMatch, tagIDs ...string) *TaskSearch {
+	return q.ids("tags", match, anyAllNot, tagIDs)
+}
+
+// Assignee matches tasks assigned, or not assigned, to any of the users. Only
+// MatchAny and MatchNot are supported.
+func (q *TaskSearch) Assignee(match SearchMatch, userIDs ...string) *TaskSearch {
+	return q.ids("assignee", match, anyNot, userIDs)
+}
+
+// CreatedBy matches tasks created, or not created, by any of the users. Only
+// MatchAny and MatchNot are supported.
+func (q *TaskSearch) CreatedBy(match SearchMatch, userIDs ...string) *TaskSearch {
+	return q.ids("created_by", match, anyNot, userIDs)
+}
+
+// Followers matches tasks followed, or not followed, by any of the users.
+// Only MatchAny and MatchNot are supported.
+func (q *TaskSearch) Followers(match SearchMatch, userIDs ...string) *TaskSearch {
+	return q.ids("followers", match, anyNot, userIDs)
+}
+
+// Teams matches tasks in projects belonging to any of the teams
+func (q *TaskSearch) Teams(teamIDs ...string) *TaskSearch {
+	return q.ids("teams", MatchAny, []SearchMatch{MatchAny}, teamIDs)
+}
+
+// Portfolios matches tasks in projects belonging to any of the portfolios
+func (q *TaskSearch) Portfolios(portfolioIDs ...string) *TaskSearch {
+	return q.ids("portfolios", MatchAny, []SearchMatch{MatchAny}, portfolioIDs)
+}
+
+func (q *TaskSearch) dates(param string, r DateRange) *TaskSearch {
+	if r.On != nil && (r.Before != nil || r.After != nil) {
+		return q.fail("Search parameter %s cannot combine an exact date with a range", param)
+	}
+
+	format := func(d *Date) string {
+		return time.Time(*d).Format(dateLayout)
+	}
+	if r.On != nil {
+		q.params.Set(param, format(r.On))
+	}
+	if r.Before != nil {
+		q.params.Set(param+".before", format(r.Before))
+	}
+	if r.After != nil {
+		q.params.Set(param+".after", format(r.After))
+	}
+	return q
+}
+
+func (q *TaskSearch) times(param string, r TimeRange) *TaskSearch {
+	if r.Before != nil {
+		q.params.Set(param+".before", r.Before.UTC().Format(time.RFC3339Nano))
+	}
+	if r.After != nil {
+		q.params.Set(param+".after", r.After.UTC().Format(time.RFC3339Nano))
+	}
+	return q
+}
+
+// DueOn restricts the due date of matching tasks
+func (q *TaskSearch) DueOn(r DateRange) *TaskSearch {
+	return q.dates("due_on", r)
+}
+
+// DueAt restricts the due time of matching tasks
+func (q *TaskSearch) DueAt(r TimeRange) *TaskSearch {
+	return q.times("due_at", r)
+}
+
+// StartOn restricts the start date of matching tasks
+func (q *TaskSearch) StartOn(r DateRange) *TaskSearch {
+	return q.dates("start_on", r)
+}
+
+// CreatedOn restricts the date matching tasks were created
+func (q *TaskSearch) CreatedOn(r DateRange) *TaskSearch {
+	return q.dates("created_on", r)
+}
+
+// CreatedAt restricts the time matching tasks were created
+func (q *TaskSearch) CreatedAt(r TimeRange) *TaskSearch {
+	return q.times("created_at", r)
+}
+
+// ModifiedOn restricts the date matching tasks were last modified
+func (q *TaskSearch) ModifiedOn(r DateRange) *TaskSearch {
+	return q.dates("modified_on", r)
+}
+
+// ModifiedAt restricts the time matching tasks were last modified
+func (q *TaskSearch) ModifiedAt(r TimeRange) *TaskSearch {
+	return q.times("modified_at", r)
+}
+
+// CompletedOn restricts the date matching tasks were completed
+func (q *TaskSearch) CompletedOn(r DateRange) *TaskSearch {
+	return q.dates("completed_on", r)
+}
+
+// CompletedAt restricts the time matching tasks were completed
+func (q *TaskSearch) CompletedAt(r TimeRange) *TaskSearch {
+	return q.times("completed_at", r)
+}
+
+func (q *TaskSearch) flag(param string, value bool) *TaskSearch {
+	q.params.Set(param, strconv.FormatBool(value))
+	return q
+}
+
+// Completed matches complete or incomplete tasks
+func (q *TaskSearch) Completed(completed bool) *TaskSearch {
+	return q.flag("completed", completed)
+}
+
+// IsBlocked matches tasks which do or do not have incomplete dependencies
+func (q *TaskSearch) IsBlocked(blocked bool) *TaskSearch {
+	return q.flag("is_blocked", blocked)
+}
+
+// IsBlocking matches tasks which do or do not have incomplete dependents
+func (q *TaskSearch) IsBlocking(blocking bool) *TaskSearch {
+	return q.flag("is_blocking", blocking)
+}
+
+// HasAttachment matches tasks which do or do not have attachments
+func (q *TaskSearch) HasAttachment(hasAttachment bool) *TaskSearch {
+	return q.flag("has_attachment", hasAttachment)
+}
+
+// IsSubtask matches tasks which are or are not subtasks
+func (q *TaskSearch) IsSubtask(subtask bool) *TaskSearch {
+	return q.flag("is_subtask", subtask)
+}
+
+func customFieldParam(customFieldID, predicate string) string {
+	return fmt.Sprintf("custom_fields.%s.%s", customFieldID, predicate)
+}
+
+// CustomFieldIsSet matches tasks which do or do not have a value for the
+// custom field
+func (q *TaskSearch) CustomFieldIsSet(customFieldID string, set bool) *TaskSearch {
+	return q.flag(customFieldParam(customFieldID, "is_set"), set)
+}
+
+// CustomFieldText matches tasks by the value of a text custom field
+func (q *TaskSearch) CustomFieldText(customFieldID string, match TextMatch, value string) *TaskSearch {
+	switch match {
+	case TextEquals, TextContains, TextStartsWith, TextEndsWith:
+	default:
+		return q.fail("Unknown text match %q for custom field %s", match, customFieldID)
+	}
+
+	q.params.Set(customFieldParam(customFieldID, string(match)), value)
+	return q
+}
+
+// CustomFieldNumber matches tasks where a number custom field has exactly
+// the given value
+func (q *TaskSearch) CustomFieldNumber(customFieldID string, value float64) *TaskSearch {
+	q.params.Set(customFieldParam(customFieldID, "value"), strconv.FormatFloat(value, 'f', -1, 64))
+	return q
+}
+
+// CustomFieldNumberRange matches tasks where a number custom field is greater
+// than and/or less than the given bounds. Bounds which are nil are ignored.
+func (q *TaskSearch) CustomFieldNumberRange(customFieldID string, greaterThan, lessThan *float64) *TaskSearch {
+	if greaterThan == nil && lessThan == nil {
+		return q.fail("Number range for custom field %s needs at least one bound", customFieldID)
+	}
+	if greaterThan != nil {
+		q.params.Set(customFieldParam(customFieldID, "greater_than"), strconv.FormatFloat(*greaterThan, 'f', -1, 64))
+	}
+	if lessThan != nil {
+		q.params.Set(customFieldParam(customFieldID, "less_than"), strconv.FormatFloat(*lessThan, 'f', -1, 64))
+	}
+	return q
+}
+
+// CustomFieldEnum matches tasks where an enum or multi-enum custom field has
+// any of the given enum options
+func (q *TaskSearch) CustomFieldEnum(customFieldID string, enumOptionIDs ...string) *TaskSearch {
+	if len(enumOptionIDs) == 0 {
+		return q.fail("Enum match for custom field %s needs at least one option", customFieldID)
+	}
+
+	q.params.Set(customFieldParam(customFieldID, "value"), strings.Join(enumOptionIDs, ","))
+	return q
+}
+
+// SortBy orders the results. Searches are sorted by creation time, newest
+// first, unless another order is given.
+//
+// Only searches sorted by creation time can be paginated.
+func (q *TaskSearch) SortBy(sort SearchSort, ascending bool) *TaskSearch {
+	q.sortBy = sort
+	q.ascending = ascending
+	return q
+}
+
+// searchQuery encodes the parameters of a search for Client.Get
+type searchQuery struct {
+	Params queryParams `url:"params"`
+}
+
+type queryParams url.Values
+
+// EncodeValues implements query.Encoder, adding the parameters as they are
+// rather than under a key
+func (p queryParams) EncodeValues(key string, v *url.Values) error {
+	for k, values := range p {
+		for _, value := range values {
+			v.Add(k, value)
+		}
+	}
+	return nil
+}
+
+// SearchTasks returns a page of the tasks in this workspace which match the
+// search. Search is only available in premium workspaces, and the results
+// may lag behind recent changes to tasks.
+//
+// The search endpoint does not return a next_page. Instead, searches sorted
+// by creation time are paginated by restricting the next request to tasks
+// created before (or after, if sorting in ascending order) the last result.
+// The returned NextPage holds this cursor and may be passed back as
+// Options.Offset, as an Iterator does. Tasks created in the same millisecond
+// as the last result on a page may be skipped.
+func (w *Workspace) SearchTasks(ctx context.Context, client *Client, search *TaskSearch, opts ...*Options) ([]*Task, *NextPage, error) {
+	client.trace("Searching tasks in workspace %s", w.ID)
+
+	if err := search.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	params := search.Values()
+	if params.Get("sort_by") == "" {
+		params.Set("sort_by", string(SortByCreatedAt))
+		params.Set("sort_ascending", "false")
+	}
+	paginated := params.Get("sort_by") == string(SortByCreatedAt)
+	ascending := params.Get("sort_ascending") == "true"
+
+	// The offset is a created_at cursor, which is not sent as an offset
+	var cursor string
+	limit := client.DefaultOptions.Limit
+	fields := []string{"name", "resource_subtype"}
+	if len(client.DefaultOptions.Fields) > 0 {
+		fields = client.DefaultOptions.Fields
+	}
+	options := make([]*Options, 0, len(opts)+1)
+	for _, o := range opts {
+		if o == nil {
+			continue
+		}
+		copied := *o
+		if copied.Offset != "" {
+			cursor = copied.Offset
+			copied.Offset = ""
+		}
+		if copied.Limit > 0 {
+			limit = copied.Limit
+		}
+		if len(copied.Fields) > 0 {
+			fields = copied.Fields
+		}
+		options = append(options, &copied)
+	}
+
+	if cursor != "" {
+		if !paginated {
+			return nil, nil, errors.New("Only searches sorted by created_at can be paginated")
+		}
+		if ascending {
+			params.Set("created_at.after", cursor)
+		} else {
+			params.Set("created_at.before", cursor)
+		}
+	}
+
+	// Paginating needs the creation time of the last result and a fixed page
+	// size to know whether there may be more results
+	if paginated {
+		if limit <= 0 {
+			limit = DefaultPageSize
+		}
+		options = append(options, &Options{
+			Limit:  limit,
+			Fields: appendField(fields, "created_at"),
+		})
+	}
+
+	var result []*Task
+	_, err := client.Get(ctx, fmt.Sprintf("/workspaces/%s/tasks/search", w.ID), &searchQuery{Params: queryParams(params)}, &result, options...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var nextPage *NextPage
+	if paginated && len(result) == limit && result[len(result)-1].CreatedAt != nil {
+		nextPage = &NextPage{
+			Offset: result[len(result)-1].CreatedAt.UTC().Format(time.RFC3339Nano),
+		}
+	}
+	return result, nextPage, nil
+}
+
+func appendField(fields []string, field string) []string {
+	for _, f := range fields {
+		if f == field {
+			return fields
+		}
+	}
+	return append(append([]string(nil), fields...), field)
+}
+
+// IterateSearchTasks returns an iterator over the tasks in this workspace
+// which match the search
+func (w *Workspace) IterateSearchTasks(ctx context.Context, client *Client, search *TaskSearch, options ...*Options) *TaskIterator {
+	return &TaskIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return w.SearchTasks(ctx, client, search, options...)
+	}, options...)}
+}
@@ -0,0 +1,95 @@
+package asana
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTaskSearchValues(t *testing.T) {
+	due := Date(time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC))
+	min := 2.5
+
+	search := NewTaskSearch().
+		Text("outage").
+		Projects(MatchAll, "1", "2").
+		Tags(MatchNot, "3").
+		DueOn(DateRange{Before: &due}).
+		IsBlocked(true).
+		CustomFieldText("10", TextContains, "db").
+		CustomFieldNumberRange("11", &min, nil).
+		CustomFieldEnum("12", "13", "14").
+		SortBy(SortByDueDate, true)
+	if err := search.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"text":                          "outage",
+		"projects.all":                  "1,2",
+		"tags.not":                      "3",
+		"due_on.before":                 "2021-03-01",
+		"is_blocked":                    "true",
+		"custom_fields.10.contains":     "db",
+		"custom_fields.11.greater_than": "2.5",
+		"custom_fields.12.value":        "13,14",
+		"sort_by":                       "due_date",
+		"sort_ascending":                "true",
+	}
+	values := search.Values()
+	for key, value := range expected {
+		if values.Get(key) != value {
+			t.Errorf("Expected %s=%q, saw %q", key, value, values.Get(key))
+		}
+	}
+	if len(values) != len(expected) {
+		t.Errorf("Unexpected parameters %v", values)
+	}
+
+	if err := NewTaskSearch().Assignee(MatchAll, "1").Validate(); err == nil {
+		t.Error("Expected assignee.all to be rejected")
+	}
+}
+
+func TestSearchTasksPagination(t *testing.T) {
+	var cursors []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/workspaces/1/tasks/search" || q.Get("sort_by") != "created_at" || q.Get("limit") != "2" || q.Get("offset") != "" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		if q.Get("opt_fields") != "name,created_at" {
+			t.Errorf("Expected created_at to be requested, saw %q", q.Get("opt_fields"))
+		}
+
+		before := q.Get("created_at.before")
+		cursors = append(cursors, before)
+		switch before {
+		case "":
+			w.Write([]byte(`{"data": [{"gid": "4", "created_at": "2021-01-04T00:00:00Z"}, {"gid": "3", "created_at": "2021-01-03T00:00:00Z"}]}`))
+		case "2021-01-03T00:00:00Z":
+			w.Write([]byte(`{"data": [{"gid": "2", "created_at": "2021-01-02T00:00:00Z"}]}`))
+		default:
+			t.Errorf("Unexpected cursor %q", before)
+		}
+	})
+
+	it := (&Workspace{ID: "1"}).IterateSearchTasks(context.Background(), client, NewTaskSearch().Text("outage"), &Options{Fields: []string{"name"}})
+	it.PageSize = 2
+
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Value().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(ids) != "[4 3 2]" {
+		t.Errorf("Expected tasks 4, 3 and 2, saw %v", ids)
+	}
+	if len(cursors) != 2 {
+		t.Errorf("Expected two requests, saw %d", len(cursors))
+	}
+}
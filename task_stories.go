@@ -33,24 +33,31 @@ func (t *Task) TaskStories(ctx context.Context, client *Client, options ...*Opti
 // AllTaskStories repeatedly pages through all available stories for a task
 func (t *Task) AllTaskStories(ctx context.Context, client *Client, options ...*Options) ([]*TaskStory, error) {
 	var allTaskStories []*TaskStory
-	nextPage := &NextPage{}
 
-	var taskStories []*TaskStory
-	var err error
+	it := t.IterateTaskStories(ctx, client, options...)
+	for it.Next() {
+		allTaskStories = append(allTaskStories, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return allTaskStories, nil
+}
 
-	for nextPage != nil {
-		page := &Options{
-			Limit:  100,
-			Offset: nextPage.Offset,
-		}
+// TaskStoryIterator iterates over a list of task stories
+type TaskStoryIterator struct {
+	*Iterator
+}
 
-		allOptions := append([]*Options{page}, options...)
-		taskStories, nextPage, err = t.TaskStories(ctx, client, allOptions...)
-		if err != nil {
-			return nil, err
-		}
+// Value returns the current task story
+func (it *TaskStoryIterator) Value() *TaskStory {
+	v, _ := it.Iterator.Value().(*TaskStory)
+	return v
+}
 
-		allTaskStories = append(allTaskStories, taskStories...)
-	}
-	return allTaskStories, nil
+// IterateTaskStories returns an iterator over the compact records for the stories on this task
+func (t *Task) IterateTaskStories(ctx context.Context, client *Client, options ...*Options) *TaskStoryIterator {
+	return &TaskStoryIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return t.TaskStories(ctx, client, options...)
+	}, options...)}
 }
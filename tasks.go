@@ -176,8 +176,10 @@ type CreateMembership struct {
 type UpdateTaskRequest struct {
 	TaskBase
 
-	Assignee  string   `json:"assignee,omitempty"`  // User to which this task is assigned, or null if the task is unassigned.
-	Followers []string `json:"followers,omitempty"` // Array of users following this task.
+	Assignee        string                 `json:"assignee,omitempty"`         // User to which this task is assigned, or null if the task is unassigned.
+	AssigneeSection string                 `json:"assignee_section,omitempty"` // Section of the assignee's My Tasks to move the task to.
+	Followers       []string               `json:"followers,omitempty"`        // Array of users following this task.
+	CustomFields    map[string]interface{} `json:"custom_fields,omitempty"`    // Custom field values keyed by custom field ID. See SetCustomField.
 }
 
 // Task is the basic object around which many operations in Asana are
@@ -247,6 +249,11 @@ type Task struct {
 	// field can only be set if the assignee is non-null.
 	AssigneeStatus string `json:"assignee_status,omitempty"`
 
+	// The section of the assignee's My Tasks which holds the task, or null
+	// if the task is unassigned. Use UpdateTaskRequest.AssigneeSection or
+	// TaskList.MoveTask to move it.
+	AssigneeSection *Section `json:"assignee_section,omitempty"`
+
 	// Read-only. The time at which this task was completed, or null if the
 	// task is incomplete.
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
@@ -370,6 +377,30 @@ func (t *Task) RemoveProject(ctx context.Context, client *Client, projectID stri
 	return err
 }
 
+// AddTag adds a tag to this task
+func (t *Task) AddTag(ctx context.Context, client *Client, tagID string) error {
+	client.trace("Adding tag %q to task %q", tagID, t.ID)
+
+	m := map[string]interface{}{
+		"tag": tagID,
+	}
+
+	err := client.post(ctx, fmt.Sprintf("/tasks/%s/addTag", t.ID), m, &json.RawMessage{})
+	return err
+}
+
+// RemoveTag removes a tag from this task
+func (t *Task) RemoveTag(ctx context.Context, client *Client, tagID string) error {
+	client.trace("Removing tag %q from task %q", tagID, t.ID)
+
+	m := map[string]interface{}{
+		"tag": tagID,
+	}
+
+	err := client.post(ctx, fmt.Sprintf("/tasks/%s/removeTag", t.ID), m, &json.RawMessage{})
+	return err
+}
+
 // SetParentRequest changes the parent of a task. Each task may only be a subtask of a single parent, or no parent task at all.
 // When using insert_before and insert_after, at most one of those two options can be specified, and they must already be subtasks of the parent.
 type SetParentRequest struct {
@@ -431,6 +462,53 @@ func (t *Task) AddDependents(ctx context.Context, client *Client, request *AddDe
 	return err
 }
 
+// TaskInclude is an optional part of a task to copy when duplicating it
+type TaskInclude string
+
+// Parts of a task which can be included in a duplicate
+const (
+	TaskIncludeAssignee     TaskInclude = "assignee"
+	TaskIncludeAttachments  TaskInclude = "attachments"
+	TaskIncludeDates        TaskInclude = "dates"
+	TaskIncludeDependencies TaskInclude = "dependencies"
+	TaskIncludeFollowers    TaskInclude = "followers"
+	TaskIncludeNotes        TaskInclude = "notes"
+	TaskIncludeParent       TaskInclude = "parent"
+	TaskIncludeProjects     TaskInclude = "projects"
+	TaskIncludeSubtasks     TaskInclude = "subtasks"
+	TaskIncludeTags         TaskInclude = "tags"
+)
+
+// DuplicateTaskRequest defines the copy made by Task.Duplicate
+type DuplicateTaskRequest struct {
+	Name    string        // Required: The name of the new task.
+	Include []TaskInclude // Optional parts of the task to copy.
+}
+
+// Duplicate starts copying this task. The copy is made asynchronously; the
+// returned job refers to the new task, which is complete once the job has
+// succeeded. Without TaskIncludeProjects or TaskIncludeParent the copy is
+// only in the workspace.
+func (t *Task) Duplicate(ctx context.Context, client *Client, request *DuplicateTaskRequest) (*Job, error) {
+	client.info("Duplicating task %q as %q", t.ID, request.Name)
+
+	// Custom request encoding, as include is a comma separated list
+	m := map[string]interface{}{
+		"name": request.Name,
+	}
+	if len(request.Include) > 0 {
+		include := make([]string, len(request.Include))
+		for i, part := range request.Include {
+			include[i] = string(part)
+		}
+		m["include"] = joinInclude(include)
+	}
+
+	result := &Job{}
+	err := client.post(ctx, fmt.Sprintf("/tasks/%s/duplicate", t.ID), m, result)
+	return result, err
+}
+
 // Tasks returns a list of tasks in this project
 func (p *Project) Tasks(ctx context.Context, client *Client, opts ...*Options) ([]*Task, *NextPage, error) {
 	client.trace("Listing tasks in %q", p.Name)
@@ -451,6 +529,16 @@ func (s *Section) Tasks(ctx context.Context, client *Client, opts ...*Options) (
 	return result, nextPage, err
 }
 
+// Tasks returns a list of tasks with this tag
+func (t *Tag) Tasks(ctx context.Context, client *Client, opts ...*Options) ([]*Task, *NextPage, error) {
+	client.trace("Listing tasks with tag %q", t.Name)
+	var result []*Task
+
+	// Make the request
+	nextPage, err := client.Get(ctx, fmt.Sprintf("/tags/%s/tasks", t.ID), nil, &result, opts...)
+	return result, nextPage, err
+}
+
 // Subtasks returns a list of tasks in this project
 func (t *Task) Subtasks(ctx context.Context, client *Client, opts ...*Options) ([]*Task, *NextPage, error) {
 	client.trace("Listing subtasks for %q", t.Name)
@@ -491,3 +579,49 @@ func (c *Client) QueryTasks(ctx context.Context, query *TaskQuery, opts ...*Opti
 	nextPage, err := c.Get(ctx, "/tasks", query, &result, opts...)
 	return result, nextPage, err
 }
+
+// TaskIterator iterates over a list of tasks
+type TaskIterator struct {
+	*Iterator
+}
+
+// Value returns the current task
+func (it *TaskIterator) Value() *Task {
+	v, _ := it.Iterator.Value().(*Task)
+	return v
+}
+
+// IterateTasks returns an iterator over the tasks in this project
+func (p *Project) IterateTasks(ctx context.Context, client *Client, options ...*Options) *TaskIterator {
+	return &TaskIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return p.Tasks(ctx, client, options...)
+	}, options...)}
+}
+
+// IterateTasks returns an iterator over the tasks in this section. Board view only.
+func (s *Section) IterateTasks(ctx context.Context, client *Client, options ...*Options) *TaskIterator {
+	return &TaskIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return s.Tasks(ctx, client, options...)
+	}, options...)}
+}
+
+// IterateTasks returns an iterator over the tasks with this tag
+func (t *Tag) IterateTasks(ctx context.Context, client *Client, options ...*Options) *TaskIterator {
+	return &TaskIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return t.Tasks(ctx, client, options...)
+	}, options...)}
+}
+
+// IterateSubtasks returns an iterator over the subtasks of this task
+func (t *Task) IterateSubtasks(ctx context.Context, client *Client, options ...*Options) *TaskIterator {
+	return &TaskIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return t.Subtasks(ctx, client, options...)
+	}, options...)}
+}
+
+// IterateTasks returns an iterator over the tasks matching a query
+func (c *Client) IterateTasks(ctx context.Context, query *TaskQuery, options ...*Options) *TaskIterator {
+	return &TaskIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return c.QueryTasks(ctx, query, options...)
+	}, options...)}
+}
@@ -46,24 +46,31 @@ func (w *Workspace) Teams(ctx context.Context, client *Client, options ...*Optio
 // AllTeams repeatedly pages through all available teams in a workspace
 func (w *Workspace) AllTeams(ctx context.Context, client *Client, options ...*Options) ([]*Team, error) {
 	var allTeams []*Team
-	nextPage := &NextPage{}
 
-	var teams []*Team
-	var err error
+	it := w.IterateTeams(ctx, client, options...)
+	for it.Next() {
+		allTeams = append(allTeams, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return allTeams, nil
+}
 
-	for nextPage != nil {
-		page := &Options{
-			Limit:  100,
-			Offset: nextPage.Offset,
-		}
+// TeamIterator iterates over a list of teams
+type TeamIterator struct {
+	*Iterator
+}
 
-		allOptions := append([]*Options{page}, options...)
-		teams, nextPage, err = w.Teams(ctx, client, allOptions...)
-		if err != nil {
-			return nil, err
-		}
+// Value returns the current team
+func (it *TeamIterator) Value() *Team {
+	v, _ := it.Iterator.Value().(*Team)
+	return v
+}
 
-		allTeams = append(allTeams, teams...)
-	}
-	return allTeams, nil
+// IterateTeams returns an iterator over the teams in this organization
+func (w *Workspace) IterateTeams(ctx context.Context, client *Client, options ...*Options) *TeamIterator {
+	return &TeamIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return w.Teams(ctx, client, options...)
+	}, options...)}
 }
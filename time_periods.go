@@ -0,0 +1,99 @@
+package asana
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TimePeriod is a fiscal year, half or quarter which goals can be set for
+type TimePeriod struct {
+	// Read-only. Globally unique ID of the object
+	ID string `json:"gid,omitempty"`
+
+	// Read-only. A string representing the cadence code and the fiscal
+	// year, such as "FY22" or "Q1 FY22".
+	DisplayName string `json:"display_name,omitempty"`
+
+	// Read-only. The cadence and index of the time period: FY, H1, H2, Q1,
+	// Q2, Q3 or Q4.
+	Period string `json:"period,omitempty"`
+
+	// Read-only. The first day of the time period.
+	StartOn *Date `json:"start_on,omitempty"`
+
+	// Read-only. The last day of the time period.
+	EndOn *Date `json:"end_on,omitempty"`
+
+	// Read-only. The time period which contains this one, such as the
+	// fiscal year of a quarter.
+	Parent *TimePeriod `json:"parent,omitempty"`
+}
+
+// Fetch loads the full details for this TimePeriod
+func (t *TimePeriod) Fetch(ctx context.Context, client *Client, options ...*Options) error {
+	client.trace("Loading details for time period %q", t.ID)
+
+	_, err := client.Get(ctx, fmt.Sprintf("/time_periods/%s", t.ID), nil, t, options...)
+	return err
+}
+
+type timePeriodsQuery struct {
+	Workspace string `url:"workspace"`
+	StartOn   string `url:"start_on,omitempty"`
+	EndOn     string `url:"end_on,omitempty"`
+}
+
+// TimePeriods returns the time periods in this workspace. If startOn or
+// endOn are given, only time periods starting on or after startOn and ending
+// on or before endOn are returned.
+func (w *Workspace) TimePeriods(ctx context.Context, client *Client, startOn, endOn *Date, options ...*Options) ([]*TimePeriod, *NextPage, error) {
+	client.trace("Listing time periods in %q", w.Name)
+
+	var result []*TimePeriod
+
+	// Make the request
+	query := &timePeriodsQuery{Workspace: w.ID}
+	if startOn != nil {
+		query.StartOn = time.Time(*startOn).Format(dateLayout)
+	}
+	if endOn != nil {
+		query.EndOn = time.Time(*endOn).Format(dateLayout)
+	}
+	nextPage, err := client.Get(ctx, "/time_periods", query, &result, options...)
+	return result, nextPage, err
+}
+
+// AllTimePeriods repeatedly pages through all available time periods in a
+// workspace
+func (w *Workspace) AllTimePeriods(ctx context.Context, client *Client, startOn, endOn *Date, options ...*Options) ([]*TimePeriod, error) {
+	var allTimePeriods []*TimePeriod
+
+	it := w.IterateTimePeriods(ctx, client, startOn, endOn, options...)
+	for it.Next() {
+		allTimePeriods = append(allTimePeriods, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return allTimePeriods, nil
+}
+
+// TimePeriodIterator iterates over a list of time periods
+type TimePeriodIterator struct {
+	*Iterator
+}
+
+// Value returns the current time period
+func (it *TimePeriodIterator) Value() *TimePeriod {
+	v, _ := it.Iterator.Value().(*TimePeriod)
+	return v
+}
+
+// IterateTimePeriods returns an iterator over the time periods in this
+// workspace
+func (w *Workspace) IterateTimePeriods(ctx context.Context, client *Client, startOn, endOn *Date, options ...*Options) *TimePeriodIterator {
+	return &TimePeriodIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return w.TimePeriods(ctx, client, startOn, endOn, options...)
+	}, options...)}
+}
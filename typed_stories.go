@@ -0,0 +1,362 @@
+package asana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// StoryMeta contains the fields shared by stories of every subtype
+type StoryMeta struct {
+	// Read-only. Globally unique ID of the object
+	ID string `json:"gid,omitempty"`
+
+	// Read-only. The type of story, which decides the struct it is decoded
+	// into.
+	ResourceSubtype string `json:"resource_subtype,omitempty"`
+
+	// Read-only. The time at which this object was created.
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+
+	// Read-only. The user who created the story.
+	CreatedBy *User `json:"created_by,omitempty"`
+
+	// Read-only. The object this story is associated with.
+	Target *Task `json:"target,omitempty"`
+
+	// Read-only. The component of the Asana product the user used to trigger
+	// the story.
+	Source string `json:"source,omitempty"`
+
+	// Read-only. Human-readable text for the story. This is not stable and
+	// should only be shown to people.
+	Text string `json:"text,omitempty"`
+}
+
+// Meta returns the fields shared by all stories
+func (m *StoryMeta) Meta() *StoryMeta {
+	return m
+}
+
+// TypedStory is a story decoded into the struct for its resource_subtype,
+// such as *AssignedStory or *SectionChangedStory. Stories with a subtype
+// which is not known are returned as *UnknownStory.
+type TypedStory interface {
+	Meta() *StoryMeta
+}
+
+// CommentStory is a comment_added story
+type CommentStory struct {
+	StoryMeta
+
+	// HTML formatted text of the comment. Only returned if requested with
+	// Options.Fields.
+	HTMLText string `json:"html_text,omitempty"`
+
+	IsPinned bool  `json:"is_pinned,omitempty"`
+	IsEdited bool  `json:"is_edited,omitempty"`
+	Liked    bool  `json:"liked,omitempty"`
+	NumLikes int32 `json:"num_likes,omitempty"`
+}
+
+// AssignedStory is an assigned story
+type AssignedStory struct {
+	StoryMeta
+
+	Assignee *User `json:"assignee,omitempty"`
+}
+
+// UnassignedStory is an unassigned story
+type UnassignedStory struct {
+	StoryMeta
+}
+
+// MarkedCompleteStory is a marked_complete story
+type MarkedCompleteStory struct {
+	StoryMeta
+}
+
+// MarkedIncompleteStory is a marked_incomplete story
+type MarkedIncompleteStory struct {
+	StoryMeta
+}
+
+// NameChangedStory is a name_changed story
+type NameChangedStory struct {
+	StoryMeta
+
+	OldName string `json:"old_name,omitempty"`
+	NewName string `json:"new_name,omitempty"`
+}
+
+// DueDateChangedStory is a due_date_changed story
+type DueDateChangedStory struct {
+	StoryMeta
+
+	OldDates *Dates `json:"old_dates,omitempty"`
+	NewDates *Dates `json:"new_dates,omitempty"`
+}
+
+// SectionChangedStory is a section_changed story, recorded when a task
+// moves between sections of a project
+type SectionChangedStory struct {
+	StoryMeta
+
+	// The project the sections are in. Not always present.
+	Project *Project `json:"project,omitempty"`
+
+	OldSection *Section `json:"old_section,omitempty"`
+	NewSection *Section `json:"new_section,omitempty"`
+}
+
+// AddedToProjectStory is an added_to_project story
+type AddedToProjectStory struct {
+	StoryMeta
+
+	Project *Project `json:"project,omitempty"`
+}
+
+// RemovedFromProjectStory is a removed_from_project story
+type RemovedFromProjectStory struct {
+	StoryMeta
+
+	Project *Project `json:"project,omitempty"`
+}
+
+// AddedToTagStory is an added_to_tag story
+type AddedToTagStory struct {
+	StoryMeta
+
+	Tag *Tag `json:"tag,omitempty"`
+}
+
+// RemovedFromTagStory is a removed_from_tag story
+type RemovedFromTagStory struct {
+	StoryMeta
+
+	Tag *Tag `json:"tag,omitempty"`
+}
+
+// FollowerAddedStory is a follower_added story
+type FollowerAddedStory struct {
+	StoryMeta
+
+	Follower *User `json:"follower,omitempty"`
+}
+
+// DependencyStory is a dependency_added, dependency_removed,
+// dependency_marked_complete, dependency_marked_incomplete or
+// dependency_due_date_changed story
+type DependencyStory struct {
+	StoryMeta
+
+	Dependency *Task `json:"dependency,omitempty"`
+
+	// Present for dependency_due_date_changed
+	NewDates *Dates `json:"new_dates,omitempty"`
+}
+
+// DependentStory is a dependent_added or dependent_removed story
+type DependentStory struct {
+	StoryMeta
+
+	Dependent *Task `json:"dependent,omitempty"`
+}
+
+// DuplicatedStory is a duplicated story, on a task created by duplicating
+// another
+type DuplicatedStory struct {
+	StoryMeta
+
+	DuplicatedFrom *Task `json:"duplicated_from,omitempty"`
+}
+
+// LikedStory is a comment_liked, completion_liked or attachment_liked story
+type LikedStory struct {
+	StoryMeta
+
+	// Present for comment_liked and completion_liked
+	Story *Story `json:"story,omitempty"`
+
+	// Present for attachment_liked
+	Attachment *Attachment `json:"attachment,omitempty"`
+}
+
+// TextCustomFieldChangedStory is a text_custom_field_changed story
+type TextCustomFieldChangedStory struct {
+	StoryMeta
+
+	CustomField  *CustomField `json:"custom_field,omitempty"`
+	OldTextValue string       `json:"old_text_value,omitempty"`
+	NewTextValue string       `json:"new_text_value,omitempty"`
+}
+
+// NumberCustomFieldChangedStory is a number_custom_field_changed story. A
+// nil value means the field was empty.
+type NumberCustomFieldChangedStory struct {
+	StoryMeta
+
+	CustomField    *CustomField `json:"custom_field,omitempty"`
+	OldNumberValue *float64     `json:"old_number_value,omitempty"`
+	NewNumberValue *float64     `json:"new_number_value,omitempty"`
+}
+
+// EnumCustomFieldChangedStory is an enum_custom_field_changed story. A nil
+// value means no option was selected.
+type EnumCustomFieldChangedStory struct {
+	StoryMeta
+
+	CustomField  *CustomField `json:"custom_field,omitempty"`
+	OldEnumValue *EnumValue   `json:"old_enum_value,omitempty"`
+	NewEnumValue *EnumValue   `json:"new_enum_value,omitempty"`
+}
+
+// MultiEnumCustomFieldChangedStory is a multi_enum_custom_field_changed story
+type MultiEnumCustomFieldChangedStory struct {
+	StoryMeta
+
+	CustomField        *CustomField `json:"custom_field,omitempty"`
+	OldMultiEnumValues []*EnumValue `json:"old_multi_enum_values,omitempty"`
+	NewMultiEnumValues []*EnumValue `json:"new_multi_enum_values,omitempty"`
+}
+
+// DateCustomFieldChangedStory is a date_custom_field_changed story
+type DateCustomFieldChangedStory struct {
+	StoryMeta
+
+	CustomField  *CustomField     `json:"custom_field,omitempty"`
+	OldDateValue *CustomFieldDate `json:"old_date_value,omitempty"`
+	NewDateValue *CustomFieldDate `json:"new_date_value,omitempty"`
+}
+
+// PeopleCustomFieldChangedStory is a people_custom_field_changed story
+type PeopleCustomFieldChangedStory struct {
+	StoryMeta
+
+	CustomField    *CustomField `json:"custom_field,omitempty"`
+	OldPeopleValue []*User      `json:"old_people_value,omitempty"`
+	NewPeopleValue []*User      `json:"new_people_value,omitempty"`
+}
+
+// UnknownStory is a story with a subtype which DecodeStory does not know.
+// The full story is kept so that it can be decoded by the caller.
+type UnknownStory struct {
+	StoryMeta
+
+	Raw json.RawMessage `json:"-"`
+}
+
+// storyTypes creates an empty struct for each known story subtype
+var storyTypes = map[string]func() TypedStory{
+	"comment_added":                   func() TypedStory { return &CommentStory{} },
+	"assigned":                        func() TypedStory { return &AssignedStory{} },
+	"unassigned":                      func() TypedStory { return &UnassignedStory{} },
+	"marked_complete":                 func() TypedStory { return &MarkedCompleteStory{} },
+	"marked_incomplete":               func() TypedStory { return &MarkedIncompleteStory{} },
+	"name_changed":                    func() TypedStory { return &NameChangedStory{} },
+	"due_date_changed":                func() TypedStory { return &DueDateChangedStory{} },
+	"section_changed":                 func() TypedStory { return &SectionChangedStory{} },
+	"added_to_project":                func() TypedStory { return &AddedToProjectStory{} },
+	"removed_from_project":            func() TypedStory { return &RemovedFromProjectStory{} },
+	"added_to_tag":                    func() TypedStory { return &AddedToTagStory{} },
+	"removed_from_tag":                func() TypedStory { return &RemovedFromTagStory{} },
+	"follower_added":                  func() TypedStory { return &FollowerAddedStory{} },
+	"dependency_added":                func() TypedStory { return &DependencyStory{} },
+	"dependency_removed":              func() TypedStory { return &DependencyStory{} },
+	"dependency_marked_complete":      func() TypedStory { return &DependencyStory{} },
+	"dependency_marked_incomplete":    func() TypedStory { return &DependencyStory{} },
+	"dependency_due_date_changed":     func() TypedStory { return &DependencyStory{} },
+	"dependent_added":                 func() TypedStory { return &DependentStory{} },
+	"dependent_removed":               func() TypedStory { return &DependentStory{} },
+	"duplicated":                      func() TypedStory { return &DuplicatedStory{} },
+	"comment_liked":                   func() TypedStory { return &LikedStory{} },
+	"completion_liked":                func() TypedStory { return &LikedStory{} },
+	"attachment_liked":                func() TypedStory { return &LikedStory{} },
+	"text_custom_field_changed":       func() TypedStory { return &TextCustomFieldChangedStory{} },
+	"number_custom_field_changed":     func() TypedStory { return &NumberCustomFieldChangedStory{} },
+	"enum_custom_field_changed":       func() TypedStory { return &EnumCustomFieldChangedStory{} },
+	"multi_enum_custom_field_changed": func() TypedStory { return &MultiEnumCustomFieldChangedStory{} },
+	"date_custom_field_changed":       func() TypedStory { return &DateCustomFieldChangedStory{} },
+	"people_custom_field_changed":     func() TypedStory { return &PeopleCustomFieldChangedStory{} },
+}
+
+// DecodeStory decodes a story from the JSON returned by the API into the
+// struct for its resource_subtype
+func DecodeStory(data []byte) (TypedStory, error) {
+	meta := StoryMeta{}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, errors.Wrap(err, "Decode story")
+	}
+
+	newStory, ok := storyTypes[meta.ResourceSubtype]
+	if !ok {
+		raw := make(json.RawMessage, len(data))
+		copy(raw, data)
+		return &UnknownStory{StoryMeta: meta, Raw: raw}, nil
+	}
+
+	story := newStory()
+	if err := json.Unmarshal(data, story); err != nil {
+		return nil, errors.Wrapf(err, "Decode %s story %s", meta.ResourceSubtype, meta.ID)
+	}
+	return story, nil
+}
+
+// TypedStories lists the stories on a task, decoded into the struct for each
+// story's subtype
+func (t *Task) TypedStories(ctx context.Context, client *Client, opts ...*Options) ([]TypedStory, *NextPage, error) {
+	client.trace("Listing typed stories for %q", t.Name)
+
+	var raw []json.RawMessage
+
+	// Make the request
+	nextPage, err := client.Get(ctx, fmt.Sprintf("/tasks/%s/stories", t.ID), nil, &raw, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := make([]TypedStory, 0, len(raw))
+	for _, data := range raw {
+		story, err := DecodeStory(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		result = append(result, story)
+	}
+	return result, nextPage, nil
+}
+
+// AllTypedStories repeatedly pages through all available stories on a task
+func (t *Task) AllTypedStories(ctx context.Context, client *Client, options ...*Options) ([]TypedStory, error) {
+	var allStories []TypedStory
+
+	it := t.IterateTypedStories(ctx, client, options...)
+	for it.Next() {
+		allStories = append(allStories, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return allStories, nil
+}
+
+// TypedStoryIterator iterates over a list of typed stories
+type TypedStoryIterator struct {
+	*Iterator
+}
+
+// Value returns the current story
+func (it *TypedStoryIterator) Value() TypedStory {
+	v, _ := it.Iterator.Value().(TypedStory)
+	return v
+}
+
+// IterateTypedStories returns an iterator over the typed stories on this task
+func (t *Task) IterateTypedStories(ctx context.Context, client *Client, options ...*Options) *TypedStoryIterator {
+	return &TypedStoryIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return t.TypedStories(ctx, client, options...)
+	}, options...)}
+}
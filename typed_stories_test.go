@@ -0,0 +1,107 @@
+package asana
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readStoryFixture(t *testing.T, name string) []byte {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "stories", name+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestDecodeStory(t *testing.T) {
+	types := map[string]string{
+		"comment_added":               "*asana.CommentStory",
+		"assigned":                    "*asana.AssignedStory",
+		"due_date_changed":            "*asana.DueDateChangedStory",
+		"section_changed":             "*asana.SectionChangedStory",
+		"enum_custom_field_changed":   "*asana.EnumCustomFieldChangedStory",
+		"number_custom_field_changed": "*asana.NumberCustomFieldChangedStory",
+		"dependency_added":            "*asana.DependencyStory",
+		"dependent_added":             "*asana.DependentStory",
+		"duplicated":                  "*asana.DuplicatedStory",
+		"comment_liked":               "*asana.LikedStory",
+		"unknown":                     "*asana.UnknownStory",
+	}
+
+	stories := map[string]TypedStory{}
+	for name, expected := range types {
+		story, err := DecodeStory(readStoryFixture(t, name))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if actual := fmt.Sprintf("%T", story); actual != expected {
+			t.Errorf("Expected %s to decode as %s, saw %s", name, expected, actual)
+		}
+		if story.Meta().ID == "" || story.Meta().CreatedAt == nil || story.Meta().CreatedBy == nil {
+			t.Errorf("Expected %s to have the common fields, saw %+v", name, story.Meta())
+		}
+		stories[name] = story
+	}
+
+	if s, ok := stories["comment_added"].(*CommentStory); !ok || !s.IsPinned || s.NumLikes != 2 || !strings.Contains(s.HTMLText, "<strong>") {
+		t.Errorf("Unexpected comment %+v", stories["comment_added"])
+	}
+	if s, ok := stories["assigned"].(*AssignedStory); !ok || s.Assignee.Name != "Grace Hopper" {
+		t.Errorf("Unexpected assignment %+v", stories["assigned"])
+	}
+	if s, ok := stories["due_date_changed"].(*DueDateChangedStory); !ok || s.OldDates.DueOn == nil || s.NewDates.StartOn == nil {
+		t.Errorf("Unexpected due date change %+v", stories["due_date_changed"])
+	}
+	if s, ok := stories["section_changed"].(*SectionChangedStory); !ok || s.OldSection.Name != "Triage" || s.NewSection.Name != "Investigating" {
+		t.Errorf("Unexpected section change %+v", stories["section_changed"])
+	}
+	if s, ok := stories["enum_custom_field_changed"].(*EnumCustomFieldChangedStory); !ok || s.CustomField.Name != "Severity" || s.OldEnumValue.Name != "SEV2" || s.NewEnumValue.Name != "SEV1" {
+		t.Errorf("Unexpected enum change %+v", stories["enum_custom_field_changed"])
+	}
+	if s, ok := stories["number_custom_field_changed"].(*NumberCustomFieldChangedStory); !ok || s.OldNumberValue == nil || *s.OldNumberValue != 0 || s.NewNumberValue != nil {
+		t.Errorf("Expected a zero value to be told apart from a cleared one, saw %+v", stories["number_custom_field_changed"])
+	}
+	if s, ok := stories["dependency_added"].(*DependencyStory); !ok || s.Dependency.ID != "22" {
+		t.Errorf("Unexpected dependency %+v", stories["dependency_added"])
+	}
+	if s, ok := stories["dependent_added"].(*DependentStory); !ok || s.Dependent.ID != "23" {
+		t.Errorf("Unexpected dependent %+v", stories["dependent_added"])
+	}
+	if s, ok := stories["comment_liked"].(*LikedStory); !ok || s.Story.ID != "1001" {
+		t.Errorf("Unexpected like %+v", stories["comment_liked"])
+	}
+
+	unknown := stories["unknown"].(*UnknownStory)
+	var fields map[string]interface{}
+	if err := json.Unmarshal(unknown.Raw, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if unknown.ResourceSubtype != "approval_status_changed" || fields["new_approval_status"] != "approved" {
+		t.Errorf("Expected the unknown story to be kept, saw %+v", unknown)
+	}
+}
+
+func TestStoryDependencyFields(t *testing.T) {
+	for name, gid := range map[string]string{"dependency_added": "22", "dependent_added": "23", "duplicated": "24"} {
+		story := &Story{}
+		if err := json.Unmarshal(readStoryFixture(t, name), story); err != nil {
+			t.Fatal(err)
+		}
+		var task *Task
+		switch name {
+		case "dependency_added":
+			task = story.Dependency
+		case "dependent_added":
+			task = story.Dependent
+		case "duplicated":
+			task = story.DuplicatedFrom
+		}
+		if task == nil || task.ID != gid {
+			t.Errorf("Expected %s to refer to task %s, saw %+v", name, gid, task)
+		}
+	}
+}
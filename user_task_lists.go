@@ -0,0 +1,101 @@
+package asana
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// Fetch loads the full details for this TaskList
+func (l *TaskList) Fetch(ctx context.Context, client *Client, options ...*Options) error {
+	client.trace("Loading details for user task list %q", l.ID)
+
+	_, err := client.Get(ctx, fmt.Sprintf("/user_task_lists/%s", l.ID), nil, l, options...)
+	return err
+}
+
+type taskListTasksQuery struct {
+	CompletedSince string `url:"completed_since,omitempty"`
+}
+
+// Tasks returns the compact records for the tasks in this task list, in the
+// order they are shown in My Tasks. If completedSince is not empty, only
+// tasks which are incomplete or were completed since then are returned; it
+// may be "now" or an ISO 8601 date or time.
+func (l *TaskList) Tasks(ctx context.Context, client *Client, completedSince string, opts ...*Options) ([]*Task, *NextPage, error) {
+	client.trace("Listing tasks in user task list %q", l.ID)
+	var result []*Task
+
+	// Make the request
+	query := &taskListTasksQuery{CompletedSince: completedSince}
+	nextPage, err := client.Get(ctx, fmt.Sprintf("/user_task_lists/%s/tasks", l.ID), query, &result, opts...)
+	return result, nextPage, err
+}
+
+// IterateTasks returns an iterator over the tasks in this task list
+func (l *TaskList) IterateTasks(ctx context.Context, client *Client, completedSince string, options ...*Options) *TaskIterator {
+	return &TaskIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return l.Tasks(ctx, client, completedSince, options...)
+	}, options...)}
+}
+
+// AllTasks repeatedly pages through all available tasks in a task list
+func (l *TaskList) AllTasks(ctx context.Context, client *Client, completedSince string, options ...*Options) ([]*Task, error) {
+	var allTasks []*Task
+
+	it := l.IterateTasks(ctx, client, completedSince, options...)
+	for it.Next() {
+		allTasks = append(allTasks, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return allTasks, nil
+}
+
+var assigneeSectionOptions = &Options{Fields: []string{"assignee_section.name"}}
+
+// Sections returns the sections of this task list which hold tasks, in the
+// order they are shown in My Tasks. The API has no way to list the sections
+// of a task list, so they are read from the assignee_section of its tasks,
+// and empty sections are missing. If completedSince is not empty, only
+// sections holding tasks which are incomplete or were completed since then
+// are returned.
+func (l *TaskList) Sections(ctx context.Context, client *Client, completedSince string) ([]*Section, error) {
+	client.trace("Listing sections in user task list %q", l.ID)
+
+	tasks, err := l.AllTasks(ctx, client, completedSince, assigneeSectionOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*Section
+	seen := map[string]bool{}
+	for _, task := range tasks {
+		if section := task.AssigneeSection; section != nil && !seen[section.ID] {
+			seen[section.ID] = true
+			result = append(result, section)
+		}
+	}
+	return result, nil
+}
+
+// MoveTask places a task at the top of a section of this task list, or next
+// to another task in the section. The task must be assigned to the owner of
+// the list. The API cannot add a task to the bottom of a My Tasks section, so
+// SectionBottom is rejected.
+func (l *TaskList) MoveTask(ctx context.Context, client *Client, task *Task, section *Section, position TaskPosition) error {
+	if section.Project != nil && section.Project.ID != l.ID {
+		return errors.Errorf("Section %s is not in user task list %s", section.ID, l.ID)
+	}
+	if position.bottom {
+		return errors.Errorf("Tasks cannot be moved to the bottom of section %s in user task list %s", section.ID, l.ID)
+	}
+
+	return section.AddTask(ctx, client, &AddTaskToSectionRequest{
+		Task:         task.ID,
+		InsertBefore: position.before,
+		InsertAfter:  position.after,
+	})
+}
@@ -51,10 +51,18 @@ func (u *User) Fetch(ctx context.Context, client *Client, options ...*Options) e
 	return err
 }
 
+// TaskList is a user's My Tasks list in a workspace. It holds the tasks
+// assigned to the user, arranged in sections.
 type TaskList struct {
 	ID           string `json:"gid"`
 	ResourceType string `json:"resource_type"`
 	Name         string `json:"name"`
+
+	// Read-only. The user the task list belongs to.
+	Owner *User `json:"owner,omitempty"`
+
+	// Read-only. The workspace the task list is in.
+	Workspace *Workspace `json:"workspace,omitempty"`
 }
 
 // GetTaskList fetches the task list for this User on a worpkspace
@@ -86,24 +94,31 @@ func (w *Workspace) Users(ctx context.Context, client *Client, options ...*Optio
 // AllUsers repeatedly pages through all available users in a workspace
 func (w *Workspace) AllUsers(ctx context.Context, client *Client, options ...*Options) ([]*User, error) {
 	var allUsers []*User
-	nextPage := &NextPage{}
 
-	var users []*User
-	var err error
+	it := w.IterateUsers(ctx, client, options...)
+	for it.Next() {
+		allUsers = append(allUsers, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return allUsers, nil
+}
 
-	for nextPage != nil {
-		page := &Options{
-			Limit:  50,
-			Offset: nextPage.Offset,
-		}
+// UserIterator iterates over a list of users
+type UserIterator struct {
+	*Iterator
+}
 
-		allOptions := append([]*Options{page}, options...)
-		users, nextPage, err = w.Users(ctx, client, allOptions...)
-		if err != nil {
-			return nil, err
-		}
+// Value returns the current user
+func (it *UserIterator) Value() *User {
+	v, _ := it.Iterator.Value().(*User)
+	return v
+}
 
-		allUsers = append(allUsers, users...)
-	}
-	return allUsers, nil
+// IterateUsers returns an iterator over the users in this workspace
+func (w *Workspace) IterateUsers(ctx context.Context, client *Client, options ...*Options) *UserIterator {
+	return &UserIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return w.Users(ctx, client, options...)
+	}, options...)}
 }
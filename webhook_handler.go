@@ -0,0 +1,240 @@
+package asana
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sync"
+)
+
+// DefaultMaxWebhookBodySize is the largest webhook payload a WebhookHandler
+// will read
+const DefaultMaxWebhookBodySize = 1 << 20
+
+// WebhookSecretStore persists the secrets Asana sends during the webhook
+// handshake, keyed by webhook. The key is derived from the incoming request,
+// see WebhookHandler.Key.
+type WebhookSecretStore interface {
+	// LoadWebhookSecret returns the secret for the webhook, or an empty
+	// string if there is none.
+	LoadWebhookSecret(ctx context.Context, key string) (string, error)
+
+	// SaveWebhookSecret records the secret for the webhook
+	SaveWebhookSecret(ctx context.Context, key, secret string) error
+}
+
+// MemoryWebhookSecretStore is a WebhookSecretStore which keeps secrets in
+// memory. Webhooks will need to be recreated if the process restarts.
+type MemoryWebhookSecretStore struct {
+	mu      sync.Mutex
+	secrets map[string]string
+}
+
+// LoadWebhookSecret implements WebhookSecretStore
+func (s *MemoryWebhookSecretStore) LoadWebhookSecret(ctx context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.secrets[key], nil
+}
+
+// SaveWebhookSecret implements WebhookSecretStore
+func (s *MemoryWebhookSecretStore) SaveWebhookSecret(ctx context.Context, key, secret string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.secrets == nil {
+		s.secrets = map[string]string{}
+	}
+	s.secrets[key] = secret
+	return nil
+}
+
+// WebhookEventHandler processes a single event delivered to a webhook.
+// Returning an error causes the delivery to be rejected so that Asana
+// retries it later.
+type WebhookEventHandler func(ctx context.Context, event Event) error
+
+// WebhookHandler is an http.Handler which receives webhooks from Asana. It
+// completes the X-Hook-Secret handshake, verifies the X-Hook-Signature of
+// every delivery and dispatches the events to the handlers registered for
+// their resource type and action.
+type WebhookHandler struct {
+	// Required: where the handshake secrets are stored
+	Secrets WebhookSecretStore
+
+	// Key identifies the webhook a request belongs to. The webhook ID is not
+	// known when the handshake arrives, so by default the request path is
+	// used and each webhook should be created with a distinct target URL.
+	//
+	// The handshake itself is not signed, so target URLs should contain an
+	// unguessable component to stop others claiming a webhook before Asana's
+	// handshake arrives.
+	Key func(r *http.Request) string
+
+	// AllowHandshake decides whether a handshake may replace the secret
+	// already stored for a webhook, for example after the webhook has been
+	// recreated with the same target URL. By default only the first
+	// handshake for a key is accepted, since anyone who can reach the URL
+	// could otherwise swap in their own secret and forge deliveries.
+	AllowHandshake func(r *http.Request) bool
+
+	// The largest payload which will be accepted. Defaults to
+	// DefaultMaxWebhookBodySize.
+	MaxBodySize int64
+
+	// Debug logs the reasons requests were rejected
+	Debug bool
+
+	mu       sync.RWMutex
+	handlers map[webhookRoute][]WebhookEventHandler
+}
+
+type webhookRoute struct {
+	ResourceType string
+	Action       string
+}
+
+// NewWebhookHandler creates a WebhookHandler which stores secrets in the
+// provided store
+func NewWebhookHandler(secrets WebhookSecretStore) *WebhookHandler {
+	return &WebhookHandler{
+		Secrets: secrets,
+	}
+}
+
+// Handle registers fn to be called for events on resources of the given
+// type with the given action, for example "task" and "changed". An empty
+// resource type or action matches any value.
+func (h *WebhookHandler) Handle(resourceType, action string, fn WebhookEventHandler) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.handlers == nil {
+		h.handlers = map[webhookRoute][]WebhookEventHandler{}
+	}
+	route := webhookRoute{ResourceType: resourceType, Action: action}
+	h.handlers[route] = append(h.handlers[route], fn)
+}
+
+// ServeHTTP implements http.Handler
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		h.reject(w, http.StatusMethodNotAllowed, "unexpected method %s", r.Method)
+		return
+	}
+
+	ctx := r.Context()
+	key := r.URL.Path
+	if h.Key != nil {
+		key = h.Key(r)
+	}
+
+	// Handshake
+	if secret := r.Header.Get(hSecret); secret != "" {
+		existing, err := h.Secrets.LoadWebhookSecret(ctx, key)
+		if err != nil {
+			h.reject(w, http.StatusInternalServerError, "load secret for %s: %v", key, err)
+			return
+		}
+		if existing != "" && (h.AllowHandshake == nil || !h.AllowHandshake(r)) {
+			h.reject(w, http.StatusForbidden, "%s already has a secret", key)
+			return
+		}
+		if err := h.Secrets.SaveWebhookSecret(ctx, key, secret); err != nil {
+			h.reject(w, http.StatusInternalServerError, "save secret for %s: %v", key, err)
+			return
+		}
+		w.Header().Set(hSecret, secret)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	secret, err := h.Secrets.LoadWebhookSecret(ctx, key)
+	if err != nil {
+		h.reject(w, http.StatusInternalServerError, "load secret for %s: %v", key, err)
+		return
+	}
+	if secret == "" {
+		h.reject(w, http.StatusUnauthorized, "no secret for %s", key)
+		return
+	}
+
+	// Read the body
+	maxBodySize := h.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = DefaultMaxWebhookBodySize
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	if err != nil {
+		h.reject(w, http.StatusBadRequest, "read body: %v", err)
+		return
+	}
+	if int64(len(body)) > maxBodySize {
+		h.reject(w, http.StatusRequestEntityTooLarge, "body is larger than %d bytes", maxBodySize)
+		return
+	}
+
+	// Verify the signature
+	verifier, err := NewSecretsVerifier(r.Header, secret)
+	if err != nil {
+		h.reject(w, http.StatusUnauthorized, "signature for %s: %v", key, err)
+		return
+	}
+	verifier.Write(body)
+	if err := verifier.Ensure(); err != nil {
+		h.reject(w, http.StatusUnauthorized, "signature for %s: %v", key, err)
+		return
+	}
+
+	// Decode and dispatch the events
+	events, err := ParseHook(ioutil.NopCloser(bytes.NewReader(body)))
+	if err != nil {
+		h.reject(w, http.StatusBadRequest, "decode events: %v", err)
+		return
+	}
+	for _, event := range events {
+		if err := h.dispatch(ctx, event); err != nil {
+			h.reject(w, http.StatusInternalServerError, "handle %s %s event: %v", event.Resource.ResourceType, event.Action, err)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *WebhookHandler) dispatch(ctx context.Context, event Event) error {
+	h.mu.RLock()
+	var handlers []WebhookEventHandler
+	seen := map[webhookRoute]bool{}
+	for _, route := range []webhookRoute{
+		{ResourceType: event.Resource.ResourceType, Action: event.Action},
+		{ResourceType: event.Resource.ResourceType},
+		{Action: event.Action},
+		{},
+	} {
+		if !seen[route] {
+			seen[route] = true
+			handlers = append(handlers, h.handlers[route]...)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, fn := range handlers {
+		if err := fn(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *WebhookHandler) reject(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	if h.Debug {
+		log.Printf("Rejecting webhook: "+format, args...)
+	}
+	http.Error(w, http.StatusText(status), status)
+}
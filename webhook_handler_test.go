@@ -0,0 +1,136 @@
+package asana
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestWebhookHandler(t *testing.T) {
+	handler := NewWebhookHandler(&MemoryWebhookSecretStore{})
+
+	var changed, any int
+	handler.Handle("task", "changed", func(ctx context.Context, event Event) error {
+		changed++
+		return nil
+	})
+	handler.Handle("", "", func(ctx context.Context, event Event) error {
+		any++
+		return nil
+	})
+
+	send := func(header http.Header, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/hooks/1", strings.NewReader(body))
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	// Deliveries before the handshake are rejected
+	if w := send(nil, `{"events": []}`); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 before handshake, saw %d", w.Code)
+	}
+
+	// Handshake
+	w := send(http.Header{hSecret: {"secret"}}, "")
+	if w.Code != http.StatusOK || w.Header().Get(hSecret) != "secret" {
+		t.Fatalf("Expected the handshake secret to be echoed, saw %d %q", w.Code, w.Header().Get(hSecret))
+	}
+
+	// A second handshake cannot replace the secret
+	if w := send(http.Header{hSecret: {"forged"}}, ""); w.Code != http.StatusForbidden {
+		t.Errorf("Expected a second handshake to be rejected with 403, saw %d", w.Code)
+	}
+
+	body := `{"events": [
+		{"action": "changed", "resource": {"gid": "1", "resource_type": "task"}},
+		{"action": "added", "resource": {"gid": "2", "resource_type": "story"}}
+	]}`
+
+	// Invalid signature
+	if w := send(http.Header{hSignature: {sign("wrong", body)}}, body); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a bad signature, saw %d", w.Code)
+	}
+	if changed != 0 || any != 0 {
+		t.Fatal("Expected no events to be dispatched for a bad signature")
+	}
+
+	// Valid delivery
+	if w := send(http.Header{hSignature: {sign("secret", body)}}, body); w.Code != http.StatusOK {
+		t.Errorf("Expected 200 for a valid delivery, saw %d", w.Code)
+	}
+	if changed != 1 || any != 2 {
+		t.Errorf("Expected events to be dispatched by type and action, saw %d and %d", changed, any)
+	}
+}
+
+func TestWebhookHandlerRehandshake(t *testing.T) {
+	handler := NewWebhookHandler(&MemoryWebhookSecretStore{})
+	handler.AllowHandshake = func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "Bearer admin"
+	}
+
+	handshake := func(secret, authorization string) int {
+		r := httptest.NewRequest(http.MethodPost, "/hooks/1", nil)
+		r.Header.Set(hSecret, secret)
+		if authorization != "" {
+			r.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if code := handshake("first", ""); code != http.StatusOK {
+		t.Fatalf("Expected the first handshake to be accepted, saw %d", code)
+	}
+	if code := handshake("second", ""); code != http.StatusForbidden {
+		t.Errorf("Expected an unauthorized handshake to be rejected, saw %d", code)
+	}
+	if code := handshake("second", "Bearer admin"); code != http.StatusOK {
+		t.Errorf("Expected an allowed handshake to be accepted, saw %d", code)
+	}
+	if secret, _ := handler.Secrets.LoadWebhookSecret(context.Background(), "/hooks/1"); secret != "second" {
+		t.Errorf("Expected the secret to be replaced, saw %q", secret)
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, io.ErrUnexpectedEOF }
+
+func TestWebhookHandlerBody(t *testing.T) {
+	store := &MemoryWebhookSecretStore{}
+	store.SaveWebhookSecret(context.Background(), "/hooks/1", "secret")
+	handler := NewWebhookHandler(store)
+	handler.MaxBodySize = 16
+
+	send := func(body io.Reader) int {
+		r := httptest.NewRequest(http.MethodPost, "/hooks/1", body)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if code := send(strings.NewReader(`{"events": [], "padding": true}`)); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for a body over the limit, saw %d", code)
+	}
+	if code := send(failingReader{}); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a truncated body, saw %d", code)
+	}
+}
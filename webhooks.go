@@ -63,24 +63,13 @@ func (w *Workspace) Webhooks(ctx context.Context, client *Client, options ...*Op
 // AllWebhooks repeatedly pages through all available webhooks for a user
 func (w *Workspace) AllWebhooks(ctx context.Context, client *Client, options ...*Options) ([]*Webhook, error) {
 	var allWebhooks []*Webhook
-	nextPage := &NextPage{}
 
-	var webhooks []*Webhook
-	var err error
-
-	for nextPage != nil {
-		page := &Options{
-			Limit:  100,
-			Offset: nextPage.Offset,
-		}
-
-		allOptions := append([]*Options{page}, options...)
-		webhooks, nextPage, err = w.Webhooks(ctx, client, allOptions...)
-		if err != nil {
-			return nil, err
-		}
-
-		allWebhooks = append(allWebhooks, webhooks...)
+	it := w.IterateWebhooks(ctx, client, options...)
+	for it.Next() {
+		allWebhooks = append(allWebhooks, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
 	}
 	return allWebhooks, nil
 }
@@ -153,3 +142,21 @@ func (v SecretsVerifier) Ensure() error {
 	}
 	return fmt.Errorf("Computed unexpected signature of: %s", hex.EncodeToString(computed))
 }
+
+// WebhookIterator iterates over a list of webhooks
+type WebhookIterator struct {
+	*Iterator
+}
+
+// Value returns the current webhook
+func (it *WebhookIterator) Value() *Webhook {
+	v, _ := it.Iterator.Value().(*Webhook)
+	return v
+}
+
+// IterateWebhooks returns an iterator over the webhooks registered in this workspace
+func (w *Workspace) IterateWebhooks(ctx context.Context, client *Client, options ...*Options) *WebhookIterator {
+	return &WebhookIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return w.Webhooks(ctx, client, options...)
+	}, options...)}
+}
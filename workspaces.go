@@ -56,24 +56,31 @@ func (c *Client) Workspaces(ctx context.Context, options ...*Options) ([]*Worksp
 // AllWorkspaces repeatedly pages through all available workspaces for a client
 func (c *Client) AllWorkspaces(ctx context.Context, options ...*Options) ([]*Workspace, error) {
 	allWorkspaces := []*Workspace{}
-	nextPage := &NextPage{}
 
-	var workspaces []*Workspace
-	var err error
+	it := c.IterateWorkspaces(ctx, options...)
+	for it.Next() {
+		allWorkspaces = append(allWorkspaces, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return allWorkspaces, nil
+}
 
-	for nextPage != nil {
-		page := &Options{
-			Limit:  100,
-			Offset: nextPage.Offset,
-		}
+// WorkspaceIterator iterates over a list of workspaces
+type WorkspaceIterator struct {
+	*Iterator
+}
 
-		allOptions := append([]*Options{page}, options...)
-		workspaces, nextPage, err = c.Workspaces(ctx, allOptions...)
-		if err != nil {
-			return nil, err
-		}
+// Value returns the current workspace
+func (it *WorkspaceIterator) Value() *Workspace {
+	v, _ := it.Iterator.Value().(*Workspace)
+	return v
+}
 
-		allWorkspaces = append(allWorkspaces, workspaces...)
-	}
-	return allWorkspaces, nil
+// IterateWorkspaces returns an iterator over the workspaces accessible to the currently authorized account
+func (c *Client) IterateWorkspaces(ctx context.Context, options ...*Options) *WorkspaceIterator {
+	return &WorkspaceIterator{NewIterator(ctx, func(ctx context.Context, options ...*Options) (interface{}, *NextPage, error) {
+		return c.Workspaces(ctx, options...)
+	}, options...)}
 }